@@ -10,3 +10,21 @@ type GPXEncoder interface {
 	// GPX returns a GPX instance
 	GPX() (*gpx.GPX, error)
 }
+
+// SeriesEncoder provides a provider-neutral time series
+type SeriesEncoder interface {
+	// Series returns a Series instance
+	Series() (*Series, error)
+}
+
+// Summarizer provides a provider-neutral summary
+type Summarizer interface {
+	// Summarize returns a provider-neutral summary of the activity
+	Summarize() *Summary
+}
+
+// CourseEncoder provides a planned route
+type CourseEncoder interface {
+	// Course returns a Course instance
+	Course() (*Course, error)
+}
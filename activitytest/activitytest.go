@@ -0,0 +1,138 @@
+// Package activitytest provides configurable in-memory implementations of the core interfaces
+// of the activity package for testing
+//
+// Each test double records its calls and can be configured with latency and failures injected
+// by method name. The doubles are safe for concurrent use.
+package activitytest
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/bzimmer/activity"
+)
+
+// Option configures a test double
+type Option func(d *double)
+
+// WithLatency delays every call by the duration, a canceled context ends the delay with its error
+func WithLatency(latency time.Duration) Option {
+	return func(d *double) {
+		d.latency = latency
+	}
+}
+
+// WithErrors sets the errors returned by successive calls of the method
+//
+// The nth call of the method returns the nth error, calls with a nil error or after the errors
+// are exhausted succeed. The method is the name of the interface method, for example "Upload".
+func WithErrors(method string, errs ...error) Option {
+	return func(d *double) {
+		d.errs[method] = errs
+	}
+}
+
+// WithStatuses sets the statuses reported by successive status checks of an upload
+//
+// The last status repeats once the statuses are exhausted. It applies to the Uploader and Poller.
+func WithStatuses(statuses ...activity.UploadStatus) Option {
+	return func(d *double) {
+		if len(statuses) > 0 {
+			d.statuses = statuses
+		}
+	}
+}
+
+// WithUploadErr sets the error of an upload which failed processing
+func WithUploadErr(err error) Option {
+	return func(d *double) {
+		d.uploadErr = err
+	}
+}
+
+// Call is a recorded call of a test double
+type Call struct {
+	// Method is the name of the interface method
+	Method string
+	// Args are the arguments of the call excluding the context
+	Args []any
+}
+
+// double is the configuration and call history shared by the test doubles
+type double struct {
+	latency   time.Duration
+	errs      map[string][]error
+	statuses  []activity.UploadStatus
+	uploadErr error
+
+	mu     sync.Mutex
+	calls  []Call
+	counts map[string]int
+}
+
+func newDouble(opts []Option) *double {
+	d := &double{
+		errs:      make(map[string][]error),
+		statuses:  []activity.UploadStatus{activity.StatusProcessing, activity.StatusReady},
+		uploadErr: &activity.Error{Kind: activity.ErrInvalidFile, Err: errors.New("upload failed")},
+		counts:    make(map[string]int),
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Calls returns the recorded calls in order
+func (d *double) Calls() []Call {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return slices.Clone(d.calls)
+}
+
+// Called returns the number of calls of the method
+func (d *double) Called(method string) int {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.counts[method]
+}
+
+// call records the call, waits for the latency, and returns the injected error if any
+func (d *double) call(ctx context.Context, method string, args ...any) error {
+	d.mu.Lock()
+	d.calls = append(d.calls, Call{Method: method, Args: args})
+	n := d.counts[method]
+	d.counts[method]++
+	var err error
+	if errs := d.errs[method]; n < len(errs) {
+		err = errs[n]
+	}
+	d.mu.Unlock()
+	if werr := d.wait(ctx); werr != nil {
+		return werr
+	}
+	return err
+}
+
+// wait for the latency or until the context is canceled
+func (d *double) wait(ctx context.Context) error {
+	if d.latency <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d.latency)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// status returns the status of the nth status check
+func (d *double) status(n int) activity.UploadStatus {
+	return d.statuses[min(n, len(d.statuses)-1)]
+}
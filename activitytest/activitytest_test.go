@@ -0,0 +1,56 @@
+package activitytest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/activitytest"
+)
+
+func TestErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	exporter := activitytest.NewExporter(activitytest.WithErrors("Export", activity.ErrRateLimited, nil))
+	exporter.Add(1, "ride", activity.FormatFIT, []byte("fit"))
+
+	exp, err := exporter.Export(ctx, 1)
+	a.True(errors.Is(err, activity.ErrRateLimited))
+	a.Nil(exp)
+	for range 2 {
+		exp, err = exporter.Export(ctx, 1)
+		a.NoError(err)
+		a.NotNil(exp)
+	}
+	a.Equal(3, exporter.Called("Export"))
+	a.Equal(0, exporter.Called("Upload"))
+	calls := exporter.Calls()
+	a.Len(calls, 3)
+	a.Equal(activitytest.Call{Method: "Export", Args: []any{int64(1)}}, calls[0])
+}
+
+func TestLatency(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	uploader := activitytest.NewUploader(activitytest.WithLatency(10 * time.Millisecond))
+	start := time.Now()
+	upload, err := uploader.Upload(context.Background(), file("data"))
+	a.NoError(err)
+	a.NotNil(upload)
+	a.GreaterOrEqual(time.Since(start), 10*time.Millisecond)
+
+	uploader = activitytest.NewUploader(activitytest.WithLatency(time.Hour))
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	upload, err = uploader.Upload(ctx, file("data"))
+	a.True(errors.Is(err, context.DeadlineExceeded))
+	a.Nil(upload)
+	a.Equal(1, uploader.Called("Upload"))
+}
@@ -0,0 +1,76 @@
+package activitytest
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/bzimmer/activity"
+)
+
+var (
+	_ activity.Exporter = (*Exporter)(nil)
+	_ activity.Lister   = (*Lister)(nil)
+)
+
+// Exporter is an in-memory activity.Exporter
+type Exporter struct {
+	*double
+	exports map[int64]activity.File
+	data    map[int64][]byte
+}
+
+// NewExporter returns a new Exporter
+func NewExporter(opts ...Option) *Exporter {
+	return &Exporter{double: newDouble(opts), exports: make(map[int64]activity.File), data: make(map[int64][]byte)}
+}
+
+// Add the contents of the activity for export
+func (e *Exporter) Add(activityID int64, name string, format activity.Format, data []byte) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports[activityID] = activity.File{Name: name, Format: format}
+	e.data[activityID] = slices.Clone(data)
+}
+
+// Export returns the contents of the activity, an activity which was not added is not found
+func (e *Exporter) Export(ctx context.Context, activityID int64) (*activity.Export, error) {
+	if err := e.call(ctx, "Export", activityID); err != nil {
+		return nil, err
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	file, ok := e.exports[activityID]
+	if !ok {
+		return nil, &activity.Error{Kind: activity.ErrNotFound, Err: fmt.Errorf("activity %d not found", activityID)}
+	}
+	file.Reader = bytes.NewReader(e.data[activityID])
+	return &activity.Export{File: &file, ID: activityID}, nil
+}
+
+// Lister is an in-memory activity.Lister
+type Lister struct {
+	*double
+	summaries []*activity.Summary
+}
+
+// NewLister returns a new Lister of the summaries
+func NewLister(summaries []*activity.Summary, opts ...Option) *Lister {
+	return &Lister{double: newDouble(opts), summaries: summaries}
+}
+
+// List returns the summaries beginning with the page of the specification up to the total
+func (l *Lister) List(ctx context.Context, spec activity.Pagination) ([]*activity.Summary, error) {
+	if err := l.call(ctx, "List", spec); err != nil {
+		return nil, err
+	}
+	summaries := l.summaries
+	if spec.Start > 1 && spec.Count > 0 {
+		summaries = summaries[min((spec.Start-1)*spec.Count, len(summaries)):]
+	}
+	if spec.Total > 0 {
+		summaries = summaries[:min(spec.Total, len(summaries))]
+	}
+	return slices.Clone(summaries), nil
+}
@@ -0,0 +1,63 @@
+package activitytest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/activitytest"
+)
+
+func TestExporter(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	exporter := activitytest.NewExporter()
+	exporter.Add(10, "Morning Ride", activity.FormatGPX, []byte("<gpx/>"))
+	for range 2 {
+		exp, err := exporter.Export(ctx, 10)
+		a.NoError(err)
+		a.Equal(int64(10), exp.ID)
+		a.Equal("Morning Ride", exp.Name)
+		a.Equal(activity.FormatGPX, exp.Format)
+		data, err := io.ReadAll(exp)
+		a.NoError(err)
+		a.Equal("<gpx/>", string(data))
+	}
+
+	exp, err := exporter.Export(ctx, 11)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(exp)
+}
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var summaries []*activity.Summary
+	for i := range 5 {
+		summaries = append(summaries, &activity.Summary{ID: int64(i)})
+	}
+	ctx := context.Background()
+	lister := activitytest.NewLister(summaries)
+
+	res, err := lister.List(ctx, activity.Pagination{})
+	a.NoError(err)
+	a.Len(res, 5)
+	res, err = lister.List(ctx, activity.Pagination{Total: 2})
+	a.NoError(err)
+	a.Len(res, 2)
+	res, err = lister.List(ctx, activity.Pagination{Start: 2, Count: 2, Total: 1})
+	a.NoError(err)
+	a.Len(res, 1)
+	a.Equal(int64(2), res[0].ID)
+	res, err = lister.List(ctx, activity.Pagination{Start: 4, Count: 2})
+	a.NoError(err)
+	a.Empty(res)
+	a.Equal(4, lister.Called("List"))
+}
@@ -0,0 +1,92 @@
+package activitytest
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/bzimmer/activity"
+)
+
+var (
+	_ activity.Paginator       = (*Paginator)(nil)
+	_ activity.CursorPaginator = (*CursorPaginator)(nil)
+)
+
+// Paginator is an activity.Paginator of a collection of resources
+//
+// Each call of Do returns up to a page of the resources beginning at the offset of the
+// specification and the specifications are recorded as the arguments of the calls.
+type Paginator struct {
+	*double
+	total    int
+	pageSize int
+	count    int
+}
+
+// NewPaginator returns a new Paginator of total resources queried pageSize at a time
+func NewPaginator(total, pageSize int, opts ...Option) *Paginator {
+	return &Paginator{double: newDouble(opts), total: total, pageSize: pageSize}
+}
+
+func (p *Paginator) PageSize() int {
+	return p.pageSize
+}
+
+func (p *Paginator) Count() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.count
+}
+
+func (p *Paginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
+	if err := p.call(ctx, "Do", spec); err != nil {
+		return 0, err
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := max(min(p.total-spec.Offset, spec.Count), 0)
+	p.count += n
+	return n, nil
+}
+
+// CursorPaginator is an activity.CursorPaginator of a collection of resources
+//
+// The cursor of the next page is the offset of its first resource.
+type CursorPaginator struct {
+	*Paginator
+	cursor string
+}
+
+// NewCursorPaginator returns a new CursorPaginator of total resources queried pageSize at a time
+func NewCursorPaginator(total, pageSize int, opts ...Option) *CursorPaginator {
+	return &CursorPaginator{Paginator: NewPaginator(total, pageSize, opts...)}
+}
+
+// Do returns the page beginning at the cursor of the specification
+func (p *CursorPaginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
+	if err := p.call(ctx, "Do", spec); err != nil {
+		return 0, err
+	}
+	offset := 0
+	if spec.Cursor != "" {
+		var err error
+		if offset, err = strconv.Atoi(spec.Cursor); err != nil {
+			return 0, err
+		}
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	n := max(min(p.total-offset, spec.Count), 0)
+	p.count += n
+	p.cursor = ""
+	if offset+n < p.total {
+		p.cursor = strconv.Itoa(offset + n)
+	}
+	return n, nil
+}
+
+func (p *CursorPaginator) Cursor() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.cursor
+}
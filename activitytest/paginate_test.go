@@ -0,0 +1,51 @@
+package activitytest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/activitytest"
+)
+
+func TestPaginator(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	paginator := activitytest.NewPaginator(25, 10)
+	a.NoError(activity.Paginate(ctx, paginator, activity.Pagination{}))
+	a.Equal(25, paginator.Count())
+	calls := paginator.Calls()
+	a.Len(calls, 4)
+	var offsets []int
+	for _, call := range calls {
+		offsets = append(offsets, call.Args[0].(activity.Pagination).Offset)
+	}
+	a.Equal([]int{0, 10, 20, 25}, offsets)
+
+	paginator = activitytest.NewPaginator(25, 10)
+	a.NoError(activity.Paginate(ctx, paginator, activity.Pagination{Total: 12}))
+	a.Equal(20, paginator.Count())
+	a.Equal(2, paginator.Called("Do"))
+
+	paginator = activitytest.NewPaginator(25, 10, activitytest.WithErrors("Do", nil, activity.ErrServer))
+	err := activity.Paginate(ctx, paginator, activity.Pagination{})
+	a.True(errors.Is(err, activity.ErrServer))
+	a.Equal(10, paginator.Count())
+}
+
+func TestCursorPaginator(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	paginator := activitytest.NewCursorPaginator(25, 10)
+	a.NoError(activity.Paginate(ctx, paginator, activity.Pagination{}))
+	a.Equal(25, paginator.Count())
+	a.Equal(3, paginator.Called("Do"))
+	a.Empty(paginator.Cursor())
+}
@@ -0,0 +1,178 @@
+package activitytest
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/bzimmer/activity"
+)
+
+var (
+	_ activity.Upload   = (*Upload)(nil)
+	_ activity.Outcome  = (*Upload)(nil)
+	_ activity.Stager   = (*Upload)(nil)
+	_ activity.Uploader = (*Uploader)(nil)
+	_ activity.Poller   = (*Poller)(nil)
+)
+
+// Upload is an upload with the status set by the test
+type Upload struct {
+	ID         activity.UploadID
+	Status     activity.UploadStatus
+	ActivityID int64
+	Error      error
+}
+
+// Identifier is the id of the upload
+func (u *Upload) Identifier() activity.UploadID {
+	return u.ID
+}
+
+// Done is true if the upload is ready or failed
+func (u *Upload) Done() bool {
+	return u.Status == activity.StatusReady || u.Status == activity.StatusError
+}
+
+// Stage is the status set by the test
+func (u *Upload) Stage() activity.UploadStatus {
+	return u.Status
+}
+
+// ActivityIdentifier is the id of the created activity, 0 unless the upload is ready
+func (u *Upload) ActivityIdentifier() int64 {
+	return u.ActivityID
+}
+
+// Err is the error of a failed upload
+func (u *Upload) Err() error {
+	return u.Error
+}
+
+// newUpload returns an upload with the status, ready uploads create an activity with the id of the upload
+func (d *double) newUpload(id activity.UploadID, status activity.UploadStatus) *Upload {
+	u := &Upload{ID: id, Status: status}
+	switch status {
+	case activity.StatusReady:
+		u.ActivityID = int64(id)
+	case activity.StatusError:
+		u.Error = d.uploadErr
+	case activity.StatusQueued, activity.StatusProcessing:
+	}
+	return u
+}
+
+// Uploader is an in-memory activity.Uploader
+//
+// Uploads are queued and report the scripted statuses on successive Status calls, by default
+// processing followed by ready. The id of the activity of a ready upload is the id of the upload.
+type Uploader struct {
+	*double
+	id     activity.UploadID
+	checks map[activity.UploadID]int
+	files  []activity.File
+	data   [][]byte
+}
+
+// NewUploader returns a new Uploader
+func NewUploader(opts ...Option) *Uploader {
+	return &Uploader{double: newDouble(opts), checks: make(map[activity.UploadID]int)}
+}
+
+// Upload reads the file and queues the upload
+func (u *Uploader) Upload(ctx context.Context, file *activity.File) (activity.Upload, error) {
+	if err := u.call(ctx, "Upload", file); err != nil {
+		return nil, err
+	}
+	if file == nil {
+		return nil, errors.New("missing upload file")
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.id++
+	u.checks[u.id] = 0
+	u.files = append(u.files, activity.File{Filename: file.Filename, Name: file.Name, Format: file.Format})
+	u.data = append(u.data, data)
+	return u.newUpload(u.id, activity.StatusQueued), nil
+}
+
+// Status returns the next scripted status of the upload
+func (u *Uploader) Status(ctx context.Context, id activity.UploadID) (activity.Upload, error) {
+	if err := u.call(ctx, "Status", id); err != nil {
+		return nil, err
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	n, ok := u.checks[id]
+	if !ok {
+		return nil, &activity.Error{Kind: activity.ErrNotFound, Err: fmt.Errorf("upload %d not found", id)}
+	}
+	u.checks[id]++
+	return u.newUpload(id, u.status(n)), nil
+}
+
+// Files returns copies of the uploaded files in order
+func (u *Uploader) Files() []*activity.File {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	files := make([]*activity.File, 0, len(u.files))
+	for i, f := range u.files {
+		f.Reader = bytes.NewReader(u.data[i])
+		files = append(files, &f)
+	}
+	return files
+}
+
+// Poller is an in-memory activity.Poller
+//
+// Each Poll sends a result for every scripted status, waiting for the latency before each, and
+// then closes the channel. An injected error is sent as the only result and canceling the context
+// ends the polling with a result of the context's error, so the channel must be read until closed.
+type Poller struct {
+	*double
+}
+
+// NewPoller returns a new Poller
+func NewPoller(opts ...Option) *Poller {
+	return &Poller{double: newDouble(opts)}
+}
+
+// Poll sends the scripted statuses of the upload
+func (p *Poller) Poll(ctx context.Context, uploadID activity.UploadID) <-chan *activity.Poll {
+	res := make(chan *activity.Poll)
+	go func() {
+		defer close(res)
+		send := func(poll *activity.Poll) bool {
+			select {
+			case <-ctx.Done():
+				return false
+			case res <- poll:
+				return true
+			}
+		}
+		if err := p.call(ctx, "Poll", uploadID); err != nil {
+			// the final error is sent even if the context is done
+			res <- &activity.Poll{Err: err}
+			return
+		}
+		for i, status := range p.statuses {
+			if i > 0 {
+				if err := p.wait(ctx); err != nil {
+					res <- &activity.Poll{Err: err}
+					return
+				}
+			}
+			if !send(&activity.Poll{Upload: p.newUpload(uploadID, status), Status: status}) {
+				res <- &activity.Poll{Err: ctx.Err()}
+				return
+			}
+		}
+	}()
+	return res
+}
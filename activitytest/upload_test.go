@@ -0,0 +1,140 @@
+package activitytest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/activitytest"
+)
+
+func file(contents string) *activity.File {
+	return &activity.File{Reader: strings.NewReader(contents), Name: "ride.fit", Format: activity.FormatFIT}
+}
+
+func poll(ctx context.Context, poller activity.Poller, id activity.UploadID) []*activity.Poll {
+	var polls []*activity.Poll
+	for p := range poller.Poll(ctx, id) {
+		polls = append(polls, p)
+	}
+	return polls
+}
+
+func TestUploader(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	uploader := activitytest.NewUploader(activitytest.WithStatuses(
+		activity.StatusQueued, activity.StatusProcessing, activity.StatusProcessing, activity.StatusReady))
+	upload, err := uploader.Upload(ctx, file("fit data"))
+	a.NoError(err)
+	a.Equal(activity.UploadID(1), upload.Identifier())
+	a.Equal(activity.StatusQueued, activity.StatusOf(upload))
+	a.False(upload.Done())
+
+	poller := activity.NewPoller(uploader, activity.WithInterval(time.Millisecond))
+	polls := poll(ctx, poller, upload.Identifier())
+	a.Len(polls, 3)
+	last := polls[len(polls)-1]
+	a.NoError(last.Err)
+	a.Equal(activity.StatusReady, last.Status)
+	a.Equal(int64(1), last.Upload.(activity.Outcome).ActivityIdentifier())
+	a.Equal(4, uploader.Called("Status"))
+
+	files := uploader.Files()
+	a.Len(files, 1)
+	a.Equal("ride.fit", files[0].Name)
+	data, err := io.ReadAll(files[0])
+	a.NoError(err)
+	a.Equal("fit data", string(data))
+
+	upload, err = uploader.Status(ctx, 100)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(upload)
+}
+
+func TestUploaderFailure(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	uploader := activitytest.NewUploader(
+		activitytest.WithStatuses(activity.StatusProcessing, activity.StatusError),
+		activitytest.WithUploadErr(&activity.DuplicateError{ActivityID: 7, Message: "duplicate"}),
+		activitytest.WithErrors("Upload", activity.ErrServer),
+		activitytest.WithErrors("Status", nil, activity.ErrRateLimited),
+	)
+	upload, err := uploader.Upload(ctx, file("data"))
+	a.True(errors.Is(err, activity.ErrServer))
+	a.Nil(upload)
+	a.Empty(uploader.Files())
+
+	upload, err = uploader.Upload(ctx, nil)
+	a.EqualError(err, "missing upload file")
+	a.Nil(upload)
+	a.Empty(uploader.Files())
+
+	upload, err = uploader.Upload(ctx, file("data"))
+	a.NoError(err)
+	_, err = uploader.Status(ctx, upload.Identifier())
+	a.NoError(err)
+	_, err = uploader.Status(ctx, upload.Identifier())
+	a.True(errors.Is(err, activity.ErrRateLimited))
+	upload, err = uploader.Status(ctx, upload.Identifier())
+	a.NoError(err)
+	a.True(upload.Done())
+	a.True(errors.Is(upload.(activity.Outcome).Err(), activity.ErrDuplicate))
+}
+
+func TestPoller(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	poller := activitytest.NewPoller(activitytest.WithStatuses(activity.StatusProcessing, activity.StatusError))
+	polls := poll(ctx, poller, 12)
+	a.Len(polls, 2)
+	a.Equal(activity.StatusProcessing, polls[0].Status)
+	a.Equal(activity.StatusError, polls[1].Status)
+	a.Equal(activity.UploadID(12), polls[1].Upload.Identifier())
+	a.True(errors.Is(polls[1].Upload.(activity.Outcome).Err(), activity.ErrInvalidFile))
+
+	poller = activitytest.NewPoller(activitytest.WithErrors("Poll", activity.ErrExceededDeadline))
+	polls = poll(ctx, poller, 12)
+	a.Len(polls, 1)
+	a.True(errors.Is(polls[0].Err, activity.ErrExceededDeadline))
+	a.Equal([]activitytest.Call{{Method: "Poll", Args: []any{activity.UploadID(12)}}}, poller.Calls())
+
+	// canceling the context always ends with its error
+	for range 20 {
+		cctx, cancel := context.WithCancel(ctx)
+		poller = activitytest.NewPoller(activitytest.WithLatency(time.Hour))
+		res := poller.Poll(cctx, 12)
+		cancel()
+		polls = nil
+		for p := range res {
+			polls = append(polls, p)
+		}
+		a.Len(polls, 1)
+		a.ErrorIs(polls[0].Err, context.Canceled)
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	poller = activitytest.NewPoller(activitytest.WithStatuses(
+		activity.StatusProcessing, activity.StatusProcessing, activity.StatusProcessing, activity.StatusReady))
+	polls = nil
+	for p := range poller.Poll(cctx, 12) {
+		polls = append(polls, p)
+		cancel()
+	}
+	a.NotEmpty(polls)
+	a.ErrorIs(polls[len(polls)-1].Err, context.Canceled)
+}
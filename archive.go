@@ -0,0 +1,256 @@
+package activity
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ArchiveManifest is the name of the manifest in the root of the archive
+const ArchiveManifest = "manifest.json"
+
+// ArchiveEntry is an activity file in the archive
+type ArchiveEntry struct {
+	Provider string `json:"provider"`
+	ID       int64  `json:"id"`
+	Format   Format `json:"format"`
+	// Path of the file relative to the root of the archive
+	Path string `json:"path"`
+	// SHA256 is the hex encoded hash of the contents of the file
+	SHA256    string    `json:"sha256"`
+	Size      int64     `json:"size"`
+	StartTime time.Time `json:"start_time"`
+	Summary   *Summary  `json:"summary,omitempty"`
+	Archived  time.Time `json:"archived"`
+}
+
+// ArchiveResult is the result of archiving an activity
+type ArchiveResult struct {
+	// Summary of the activity
+	Summary *Summary `json:"summary"`
+	// Entry is the archived file if no error occurred
+	Entry *ArchiveEntry `json:"entry,omitempty"`
+	// Skipped is true if the activity was previously archived
+	Skipped bool `json:"skipped"`
+	// Err is non-nil if exporting or writing the activity failed
+	Err error `json:"-"`
+}
+
+type archiveKey struct {
+	provider string
+	id       int64
+}
+
+// Archive stores exported activity files in a directory tree
+//
+// Files are written to `provider/year/id.ext` relative to the root and the manifest, a JSON
+// list of the entries, is rewritten after each file is added.
+type Archive struct {
+	root    string
+	mu      sync.Mutex
+	entries map[archiveKey]*ArchiveEntry
+}
+
+// NewArchive returns an Archive rooted at the directory, reading the manifest if it exists
+func NewArchive(root string) (*Archive, error) {
+	a := &Archive{root: root, entries: make(map[archiveKey]*ArchiveEntry)}
+	data, err := os.ReadFile(filepath.Join(root, ArchiveManifest))
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		var entries []*ArchiveEntry
+		if err = json.Unmarshal(data, &entries); err != nil {
+			return nil, err
+		}
+		for _, x := range entries {
+			a.entries[archiveKey{provider: x.Provider, id: x.ID}] = x
+		}
+	}
+	return a, nil
+}
+
+// Entry returns the entry of the provider's activity or nil if the activity is not archived
+func (a *Archive) Entry(provider string, activityID int64) *ArchiveEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.entries[archiveKey{provider: provider, id: activityID}]
+}
+
+// Entries returns all the entries ordered by provider and id
+func (a *Archive) Entries() []*ArchiveEntry {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.sorted()
+}
+
+// sorted returns the entries, with any added entries replacing those of the same activity, ordered by provider and id
+func (a *Archive) sorted(added ...*ArchiveEntry) []*ArchiveEntry {
+	entries := make([]*ArchiveEntry, 0, len(a.entries)+len(added))
+	entries = append(entries, added...)
+	for key, x := range a.entries {
+		if !slices.ContainsFunc(added, func(y *ArchiveEntry) bool {
+			return key == archiveKey{provider: y.Provider, id: y.ID}
+		}) {
+			entries = append(entries, x)
+		}
+	}
+	// a stable order keeps the manifest diff-able
+	slices.SortFunc(entries, func(x, y *ArchiveEntry) int {
+		return cmp.Or(cmp.Compare(x.Provider, y.Provider), cmp.Compare(x.ID, y.ID))
+	})
+	return entries
+}
+
+// Add exports the activity from the provider and writes it to the archive
+//
+// Activities already in the archive are not exported and the existing entry is returned,
+// concurrent adds of an activity write it to the archive only once.
+func (a *Archive) Add(
+	ctx context.Context, provider string, exporter Exporter, summary *Summary) (*ArchiveResult, error) {
+	if provider == "" || summary == nil {
+		return nil, errors.New("missing provider or summary")
+	}
+	if entry := a.Entry(provider, summary.ID); entry != nil {
+		return &ArchiveResult{Summary: summary, Entry: entry, Skipped: true}, nil
+	}
+	export, err := exporter.Export(ctx, summary.ID)
+	if err != nil {
+		return nil, fmt.Errorf("export %d: %w", summary.ID, err)
+	}
+	entry, tmp, err := a.write(provider, export, summary)
+	if err = errors.Join(err, export.Close()); err != nil {
+		return nil, fmt.Errorf("archive %d: %w", summary.ID, err)
+	}
+	defer os.Remove(tmp)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	key := archiveKey{provider: provider, id: summary.ID}
+	// a concurrent add of the activity might have archived it while exporting
+	if x := a.entries[key]; x != nil {
+		return &ArchiveResult{Summary: summary, Entry: x, Skipped: true}, nil
+	}
+	path := filepath.Join(a.root, filepath.FromSlash(entry.Path))
+	if err = os.Rename(tmp, path); err != nil {
+		return nil, fmt.Errorf("archive %d: %w", summary.ID, err)
+	}
+	// the entry is added only once the manifest is written so a failure leaves the entries unchanged
+	if err = writeJSON(filepath.Join(a.root, ArchiveManifest), a.sorted(entry)); err != nil {
+		return nil, errors.Join(err, os.Remove(path))
+	}
+	a.entries[key] = entry
+	return &ArchiveResult{Summary: summary, Entry: entry}, nil
+}
+
+// Sync archives the provider's activities listed using the pagination specification
+//
+// An error is returned only if listing fails or the context is canceled, failures archiving an
+// individual activity are reported in the results.
+func (a *Archive) Sync(
+	ctx context.Context, provider string, lister Lister, exporter Exporter, spec Pagination) ([]*ArchiveResult, error) {
+	summaries, err := lister.List(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*ArchiveResult, 0, len(summaries))
+	for _, summary := range summaries {
+		if err = ctx.Err(); err != nil {
+			return results, err
+		}
+		res, aerr := a.Add(ctx, provider, exporter, summary)
+		if aerr != nil {
+			res = &ArchiveResult{Summary: summary, Err: aerr}
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// write the exported file to a temporary file next to its path in the archive hashing the contents
+//
+// The caller renames the temporary file to the path of the entry or removes it.
+func (a *Archive) write(provider string, export *Export, summary *Summary) (*ArchiveEntry, string, error) {
+	year := "undated"
+	if !summary.StartTime.IsZero() {
+		year = strconv.Itoa(summary.StartTime.UTC().Year())
+	}
+	ext := filepath.Ext(export.Name)
+	if export.Format != FormatOriginal {
+		ext = "." + export.Format.String()
+	}
+	rel := filepath.Join(provider, year, strconv.FormatInt(summary.ID, 10)+ext)
+	path := filepath.Join(a.root, rel)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, "", err
+	}
+	fp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return nil, "", err
+	}
+	hash := sha256.New()
+	size, err := io.Copy(io.MultiWriter(fp, hash), export)
+	if err = errors.Join(err, fp.Close()); err != nil {
+		return nil, "", errors.Join(err, os.Remove(fp.Name()))
+	}
+	return &ArchiveEntry{
+		Provider:  provider,
+		ID:        summary.ID,
+		Format:    export.Format,
+		Path:      filepath.ToSlash(rel),
+		SHA256:    hex.EncodeToString(hash.Sum(nil)),
+		Size:      size,
+		StartTime: summary.StartTime,
+		Summary:   summary,
+		Archived:  time.Now().UTC(),
+	}, fp.Name(), nil
+}
+
+// Exporter returns an Exporter of the provider's archived activities
+//
+// The contents of the file are verified against the hash in the manifest.
+func (a *Archive) Exporter(provider string) Exporter {
+	return &archiveExporter{archive: a, provider: provider}
+}
+
+type archiveExporter struct {
+	archive  *Archive
+	provider string
+}
+
+func (x *archiveExporter) Export(_ context.Context, activityID int64) (*Export, error) {
+	entry := x.archive.Entry(x.provider, activityID)
+	if entry == nil {
+		return nil, &Error{Kind: ErrNotFound, Err: fmt.Errorf("%s activity %d not archived", x.provider, activityID)}
+	}
+	path := filepath.Join(x.archive.root, filepath.FromSlash(entry.Path))
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if sum := sha256.Sum256(data); hex.EncodeToString(sum[:]) != entry.SHA256 {
+		return nil, fmt.Errorf("%s: checksum mismatch", entry.Path)
+	}
+	return &Export{
+		File: &File{
+			Reader:   bytes.NewReader(data),
+			Filename: path,
+			Name:     filepath.Base(path),
+			Format:   entry.Format,
+		},
+		ID: activityID,
+	}, nil
+}
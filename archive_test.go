@@ -0,0 +1,184 @@
+package activity_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestArchive(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	root := t.TempDir()
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	lister := &syncLister{summaries: []*activity.Summary{
+		{ID: 1, Name: "Morning Ride", Sport: activity.SportCycling, StartTime: start},
+		{ID: 2, Name: "Untitled"},
+	}}
+	exporter := &syncExporter{}
+
+	archive, err := activity.NewArchive(root)
+	a.NoError(err)
+	results, err := archive.Sync(ctx, "strava", lister, exporter, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 2)
+	a.Equal(2, exporter.exports)
+
+	sum := sha256.Sum256([]byte("activity"))
+	entry := results[0].Entry
+	a.False(results[0].Skipped)
+	a.Equal("strava/2021/1.fit", entry.Path)
+	a.Equal(hex.EncodeToString(sum[:]), entry.SHA256)
+	a.Equal(int64(len("activity")), entry.Size)
+	a.Equal(start, entry.StartTime)
+	a.Equal("strava/undated/2.fit", results[1].Entry.Path)
+	data, err := os.ReadFile(filepath.Join(root, "strava", "2021", "1.fit"))
+	a.NoError(err)
+	a.Equal("activity", string(data))
+
+	// archived activities are skipped
+	results, err = archive.Sync(ctx, "strava", lister, exporter, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 2)
+	a.True(results[0].Skipped)
+	a.Equal(2, exporter.exports)
+
+	// the manifest is read when reopened
+	archive, err = activity.NewArchive(root)
+	a.NoError(err)
+	entries := archive.Entries()
+	a.Len(entries, 2)
+	a.Equal(activity.FormatFIT, entries[0].Format)
+	a.Equal(activity.SportCycling, entries[0].Summary.Sport)
+	a.Equal("Morning Ride", entries[0].Summary.Name)
+	a.Nil(archive.Entry("zwift", 1))
+
+	// the archive exports the files for re-uploading
+	export, err := archive.Exporter("strava").Export(ctx, 1)
+	a.NoError(err)
+	data, err = io.ReadAll(export)
+	a.NoError(err)
+	a.Equal("activity", string(data))
+	a.Equal(activity.FormatFIT, export.Format)
+	a.Equal("1.fit", export.Name)
+	a.NoError(export.Close())
+
+	_, err = archive.Exporter("zwift").Export(ctx, 1)
+	a.ErrorIs(err, activity.ErrNotFound)
+
+	a.NoError(os.WriteFile(filepath.Join(root, "strava", "2021", "1.fit"), []byte("modified"), 0o600))
+	_, err = archive.Exporter("strava").Export(ctx, 1)
+	a.ErrorContains(err, "checksum mismatch")
+}
+
+func TestArchiveErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	summaries := []*activity.Summary{{ID: 1}}
+
+	archive, err := activity.NewArchive(t.TempDir())
+	a.NoError(err)
+
+	_, err = archive.Sync(ctx, "strava", &syncLister{err: errors.New("boom")}, &syncExporter{}, activity.Pagination{})
+	a.ErrorContains(err, "boom")
+
+	results, err := archive.Sync(
+		ctx, "strava", &syncLister{summaries: summaries}, &syncExporter{err: errors.New("gone")}, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 1)
+	a.ErrorContains(results[0].Err, "export 1: gone")
+	a.Nil(results[0].Entry)
+	a.Empty(archive.Entries())
+
+	_, err = archive.Add(ctx, "", &syncExporter{}, summaries[0])
+	a.ErrorContains(err, "missing provider or summary")
+
+	// the entry is not added if the manifest cannot be written
+	root := t.TempDir()
+	archive, err = activity.NewArchive(root)
+	a.NoError(err)
+	a.NoError(os.Mkdir(filepath.Join(root, activity.ArchiveManifest), 0o700))
+	_, err = archive.Add(ctx, "strava", &syncExporter{}, summaries[0])
+	a.Error(err)
+	a.Nil(archive.Entry("strava", 1))
+	a.Empty(archive.Entries())
+	// the file is not left behind
+	files, err := os.ReadDir(filepath.Join(root, "strava", "undated"))
+	a.NoError(err)
+	a.Empty(files)
+	a.NoError(os.Remove(filepath.Join(root, activity.ArchiveManifest)))
+	res, err := archive.Add(ctx, "strava", &syncExporter{}, summaries[0])
+	a.NoError(err)
+	a.False(res.Skipped)
+	a.Len(archive.Entries(), 1)
+
+	root = t.TempDir()
+	a.NoError(os.WriteFile(filepath.Join(root, activity.ArchiveManifest), []byte("{"), 0o600))
+	_, err = activity.NewArchive(root)
+	a.Error(err)
+}
+
+func TestArchiveConcurrentAdd(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	root := t.TempDir()
+	archive, err := activity.NewArchive(root)
+	a.NoError(err)
+
+	var wg sync.WaitGroup
+	results := make([]*activity.ArchiveResult, 8)
+	// every add exports the activity before any of them write it
+	exporter := &barrierExporter{}
+	exporter.started.Add(len(results))
+	for i := range results {
+		wg.Go(func() {
+			res, aerr := archive.Add(ctx, "strava", exporter, &activity.Summary{ID: 1})
+			a.NoError(aerr)
+			results[i] = res
+		})
+	}
+	wg.Wait()
+
+	// only one add archives the activity and the others return its entry
+	var added int
+	for _, res := range results {
+		if !res.Skipped {
+			added++
+		}
+		a.Equal(results[0].Entry, res.Entry)
+	}
+	a.Equal(1, added)
+	a.Len(archive.Entries(), 1)
+	files, err := os.ReadDir(filepath.Join(root, "strava", "undated"))
+	a.NoError(err)
+	a.Len(files, 1)
+}
+
+// barrierExporter waits for all the expected exports to start before returning
+type barrierExporter struct {
+	syncExporter
+	started sync.WaitGroup
+}
+
+func (e *barrierExporter) Export(ctx context.Context, activityID int64) (*activity.Export, error) {
+	e.started.Done()
+	e.started.Wait()
+	return e.syncExporter.Export(ctx, activityID)
+}
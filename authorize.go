@@ -0,0 +1,240 @@
+package activity
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/oauth2"
+)
+
+const (
+	authorizeAddress = "127.0.0.1:0"
+	authorizePath    = "/callback"
+)
+
+// An AuthorizerOption allows configuring an Authorizer
+type AuthorizerOption func(a *Authorizer)
+
+// WithAuthorizerAddress sets the address of the loopback server receiving the authorization code
+//
+// The address must match the callback domain registered with the provider, the default is an
+// ephemeral port on 127.0.0.1.
+func WithAuthorizerAddress(address string) AuthorizerOption {
+	return func(a *Authorizer) {
+		if address != "" {
+			a.address = address
+		}
+	}
+}
+
+// WithAuthorizerOpen is called with the authorization url the user must visit
+//
+// The default prints the url to stderr.
+func WithAuthorizerOpen(open func(ctx context.Context, url string) error) AuthorizerOption {
+	return func(a *Authorizer) {
+		if open != nil {
+			a.open = open
+		}
+	}
+}
+
+// WithAuthorizerStore saves the token to the store once authorized
+func WithAuthorizerStore(store TokenStore) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.store = store
+	}
+}
+
+// WithAuthorizerScopeDelimiter sets the delimiter of the scopes in the authorization url
+//
+// The default is a space as specified by RFC 6749.
+func WithAuthorizerScopeDelimiter(delimiter string) AuthorizerOption {
+	return func(a *Authorizer) {
+		if delimiter != "" {
+			a.delimiter = delimiter
+		}
+	}
+}
+
+// WithAuthorizerPKCE controls the use of PKCE, enabled by default
+func WithAuthorizerPKCE(pkce bool) AuthorizerOption {
+	return func(a *Authorizer) {
+		a.pkce = pkce
+	}
+}
+
+// Authorizer acquires a token using the OAuth authorization code flow
+type Authorizer struct {
+	config    oauth2.Config
+	address   string
+	delimiter string
+	pkce      bool
+	open      func(ctx context.Context, url string) error
+	store     TokenStore
+}
+
+// NewAuthorizer returns an Authorizer for the config
+//
+// The scopes of the config are requested and the redirect url is replaced by the url of the loopback server.
+func NewAuthorizer(config oauth2.Config, opts ...AuthorizerOption) *Authorizer {
+	a := &Authorizer{
+		config:    config,
+		address:   authorizeAddress,
+		delimiter: " ",
+		pkce:      true,
+		open: func(_ context.Context, url string) error {
+			_, err := fmt.Fprintf(os.Stderr, "Visit the url to authorize: %s\n", url)
+			return err
+		},
+	}
+	for _, opt := range opts {
+		opt(a)
+	}
+	return a
+}
+
+// callback is the outcome of the redirect to the loopback server
+type callback struct {
+	code   string
+	scopes []string
+	err    error
+}
+
+// Authorize starts the loopback server, waits for the user to grant access, and exchanges the code for a token
+//
+// If the granted scopes are reported by the provider and any requested scope was not granted
+// the error wraps ErrMissingScope.
+func (a *Authorizer) Authorize(ctx context.Context) (*oauth2.Token, error) {
+	listener, err := (&net.ListenConfig{}).Listen(ctx, "tcp", a.address)
+	if err != nil {
+		return nil, err
+	}
+	config := a.config
+	config.Scopes = nil
+	config.RedirectURL = "http://" + listener.Addr().String() + authorizePath
+
+	state, verifier := rand.Text(), oauth2.GenerateVerifier()
+	callbacks := make(chan *callback, 1)
+	mux := http.NewServeMux()
+	mux.Handle(authorizePath, a.handler(state, callbacks))
+	svr := &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go svr.Serve(listener)                         //nolint:errcheck // the server is always shutdown
+	defer svr.Shutdown(context.WithoutCancel(ctx)) //nolint:errcheck // nothing to be done
+
+	if err = a.open(ctx, config.AuthCodeURL(state, a.authOptions(verifier)...)); err != nil {
+		return nil, err
+	}
+	var cb *callback
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case cb = <-callbacks:
+	}
+	if cb.err != nil {
+		return nil, cb.err
+	}
+	return a.exchange(ctx, &config, cb, verifier)
+}
+
+// authOptions returns the options of the authorization url
+func (a *Authorizer) authOptions(verifier string) []oauth2.AuthCodeOption {
+	var opts []oauth2.AuthCodeOption
+	if len(a.config.Scopes) > 0 {
+		opts = append(opts, oauth2.SetAuthURLParam("scope", strings.Join(a.config.Scopes, a.delimiter)))
+	}
+	if a.pkce {
+		opts = append(opts, oauth2.S256ChallengeOption(verifier))
+	}
+	return opts
+}
+
+// handler receives the redirect from the provider sending the outcome to the channel
+//
+// Redirects with a mismatched state are rejected without completing the authorization.
+func (a *Authorizer) handler(state string, callbacks chan<- *callback) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		if q.Get("state") != state {
+			// a redirect which did not originate from this authorization is ignored
+			http.Error(w, "authorization state mismatch", http.StatusBadRequest)
+			return
+		}
+		cb := &callback{code: q.Get("code"), scopes: a.scopes(q.Get("scope"))}
+		switch {
+		case q.Get("error") != "":
+			cb.err = &Error{Kind: ErrUnauthorized, Err: fmt.Errorf("authorization failed: %s", q.Get("error"))}
+		case cb.code == "":
+			cb.err = errors.New("authorization code missing")
+		}
+		select {
+		case callbacks <- cb:
+		default:
+			// only the first redirect is used
+			http.Error(w, "authorization already complete", http.StatusConflict)
+			return
+		}
+		if cb.err != nil {
+			http.Error(w, cb.err.Error(), http.StatusBadRequest)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "Authorization complete, this window may be closed.")
+	})
+}
+
+// exchange the code for a token, verify the granted scopes, and save the token to the store
+func (a *Authorizer) exchange(
+	ctx context.Context, config *oauth2.Config, cb *callback, verifier string) (*oauth2.Token, error) {
+	var opts []oauth2.AuthCodeOption
+	if a.pkce {
+		opts = append(opts, oauth2.VerifierOption(verifier))
+	}
+	token, err := config.Exchange(ctx, cb.code, opts...)
+	if err != nil {
+		return nil, err
+	}
+	granted := cb.scopes
+	if scope, ok := token.Extra("scope").(string); ok && len(granted) == 0 {
+		granted = a.scopes(scope)
+	}
+	if err = a.verify(granted); err != nil {
+		return nil, err
+	}
+	if a.store != nil {
+		if err = a.store.SaveToken(ctx, token); err != nil {
+			return nil, err
+		}
+	}
+	return token, nil
+}
+
+// scopes splits the scopes reported by the provider
+func (a *Authorizer) scopes(scope string) []string {
+	return strings.FieldsFunc(scope, func(r rune) bool {
+		return r == ' ' || strings.ContainsRune(a.delimiter, r)
+	})
+}
+
+// verify all requested scopes were granted, if the provider did not report the granted scopes all are assumed granted
+func (a *Authorizer) verify(granted []string) error {
+	if len(granted) == 0 {
+		return nil
+	}
+	var missing []string
+	for _, scope := range a.config.Scopes {
+		if !slices.Contains(granted, scope) {
+			missing = append(missing, scope)
+		}
+	}
+	if len(missing) > 0 {
+		return &Error{Kind: ErrMissingScope, Err: fmt.Errorf("scopes not granted: %s", strings.Join(missing, ", "))}
+	}
+	return nil
+}
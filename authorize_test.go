@@ -0,0 +1,203 @@
+package activity_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+)
+
+// redirect simulates the user granting access by following the redirect to the loopback server
+func redirect(ctx context.Context, uri string, params url.Values) error {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return err
+	}
+	q := u.Query()
+	callback := q.Get("redirect_uri") + "?" + params.Encode()
+	if params.Get("state") == "" {
+		callback += "&state=" + q.Get("state")
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, callback, http.NoBody)
+	if err != nil {
+		return err
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	_, err = io.Copy(io.Discard, res.Body)
+	return err
+}
+
+func TestAuthorize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name     string
+		params   url.Values
+		scope    string
+		pkce     bool
+		err      error
+		contains string
+	}{
+		{
+			name:   "success",
+			params: url.Values{"code": {"abc"}, "scope": {"read,activity:read_all"}},
+			pkce:   true,
+		},
+		{
+			name:   "scope from token",
+			params: url.Values{"code": {"abc"}},
+			scope:  "read activity:read_all",
+		},
+		{
+			name:     "missing scope",
+			params:   url.Values{"code": {"abc"}, "scope": {"read"}},
+			err:      activity.ErrMissingScope,
+			contains: "activity:read_all",
+		},
+		{
+			name:   "access denied",
+			params: url.Values{"error": {"access_denied"}},
+			err:    activity.ErrUnauthorized,
+		},
+		{
+			name:     "code missing",
+			params:   url.Values{},
+			contains: "code missing",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var challenge string
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				a.NoError(r.ParseForm())
+				a.Equal("abc", r.Form.Get("code"))
+				verifier := r.Form.Get("code_verifier")
+				if tt.pkce {
+					sum := sha256.Sum256([]byte(verifier))
+					a.Equal(challenge, base64.RawURLEncoding.EncodeToString(sum[:]))
+				}
+				w.Header().Set("Content-Type", "application/json")
+				a.NoError(json.NewEncoder(w).Encode(map[string]any{
+					"access_token": "access", "refresh_token": "refresh", "expires_in": 3600, "scope": tt.scope}))
+			}))
+			defer svr.Close()
+
+			store := activity.NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+			config := oauth2.Config{
+				ClientID: "client",
+				Endpoint: oauth2.Endpoint{AuthURL: svr.URL + "/authorize", TokenURL: svr.URL + "/token"},
+				Scopes:   []string{"read", "activity:read_all"},
+			}
+			authorizer := activity.NewAuthorizer(config,
+				activity.WithAuthorizerScopeDelimiter(","),
+				activity.WithAuthorizerPKCE(tt.pkce),
+				activity.WithAuthorizerStore(store),
+				activity.WithAuthorizerOpen(func(ctx context.Context, uri string) error {
+					u, err := url.Parse(uri)
+					a.NoError(err)
+					q := u.Query()
+					a.Equal("client", q.Get("client_id"))
+					a.Equal("read,activity:read_all", q.Get("scope"))
+					a.NotEmpty(q.Get("state"))
+					challenge = q.Get("code_challenge")
+					a.Equal(tt.pkce, challenge != "")
+					return redirect(ctx, uri, tt.params)
+				}))
+			token, err := authorizer.Authorize(context.Background())
+			stored, serr := store.Token(context.Background())
+			if tt.err != nil || tt.contains != "" {
+				a.Nil(token)
+				a.Error(err)
+				if tt.err != nil {
+					a.ErrorIs(err, tt.err)
+				}
+				a.ErrorContains(err, tt.contains)
+				a.Nil(stored)
+				a.ErrorIs(serr, activity.ErrNotFound)
+				return
+			}
+			a.NoError(err)
+			a.Equal("access", token.AccessToken)
+			a.NoError(serr)
+			a.Equal("refresh", stored.RefreshToken)
+		})
+	}
+}
+
+func TestAuthorizeCanceled(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	authorizer := activity.NewAuthorizer(oauth2.Config{},
+		activity.WithAuthorizerOpen(func(context.Context, string) error {
+			cancel()
+			return nil
+		}))
+	token, err := authorizer.Authorize(ctx)
+	a.Nil(token)
+	a.ErrorIs(err, context.Canceled)
+
+	authorizer = activity.NewAuthorizer(oauth2.Config{},
+		activity.WithAuthorizerOpen(func(context.Context, string) error {
+			return errors.New("no browser")
+		}))
+	token, err = authorizer.Authorize(context.Background())
+	a.Nil(token)
+	a.EqualError(err, "no browser")
+}
+
+func TestAuthorizeStateMismatch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("abc", r.Form.Get("code"))
+		w.Header().Set("Content-Type", "application/json")
+		a.NoError(json.NewEncoder(w).Encode(map[string]any{"access_token": "access", "expires_in": 3600}))
+	}))
+	defer svr.Close()
+
+	config := oauth2.Config{Endpoint: oauth2.Endpoint{AuthURL: svr.URL + "/authorize", TokenURL: svr.URL + "/token"}}
+	forged := url.Values{"code": {"forged"}, "state": {"forged"}}
+
+	authorizer := activity.NewAuthorizer(config,
+		activity.WithAuthorizerOpen(func(ctx context.Context, uri string) error {
+			a.NoError(redirect(ctx, uri, forged))
+			return redirect(ctx, uri, url.Values{"code": {"abc"}})
+		}))
+	token, err := authorizer.Authorize(context.Background())
+	a.NoError(err)
+	a.Equal("access", token.AccessToken)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	authorizer = activity.NewAuthorizer(config,
+		activity.WithAuthorizerOpen(func(ctx context.Context, uri string) error {
+			return redirect(ctx, uri, forged)
+		}))
+	token, err = authorizer.Authorize(ctx)
+	a.Nil(token)
+	a.ErrorIs(err, context.DeadlineExceeded)
+}
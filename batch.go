@@ -0,0 +1,209 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+const batchConcurrency = 4
+
+// BatchResult is the result of uploading a file in a batch
+type BatchResult struct {
+	// File uploaded
+	File *File `json:"file"`
+	// UploadID of the upload, 0 if the upload failed
+	UploadID UploadID `json:"upload_id,omitempty"`
+	// ActivityID of the created activity, or the existing activity for duplicates, 0 if unknown
+	ActivityID int64 `json:"activity_id,omitempty"`
+	// Duplicate is true if the file duplicates an existing activity
+	Duplicate bool `json:"duplicate"`
+	// Err is non-nil if uploading or processing the file failed
+	Err error `json:"-"`
+}
+
+// A BatchOption allows configuring a BatchUploader
+type BatchOption func(b *BatchUploader)
+
+// WithBatchConcurrency controls the maximum number of concurrent uploads
+func WithBatchConcurrency(concurrency int) BatchOption {
+	return func(b *BatchUploader) {
+		if concurrency > 0 {
+			b.concurrency = concurrency
+		}
+	}
+}
+
+// WithBatchPoller controls the creation of the Poller used to check the status of uploads
+func WithBatchPoller(poller func(Uploader) Poller) BatchOption {
+	return func(b *BatchUploader) {
+		if poller != nil {
+			b.poller = poller
+		}
+	}
+}
+
+// WithBatchProgress is called with the result of each file once complete
+//
+// The function is called serially so it need not be safe for concurrent use.
+func WithBatchProgress(progress func(*BatchResult)) BatchOption {
+	return func(b *BatchUploader) {
+		if progress != nil {
+			b.progress = progress
+		}
+	}
+}
+
+// BatchUploader uploads many files with bounded concurrency
+type BatchUploader struct {
+	uploader    Uploader
+	concurrency int
+	poller      func(Uploader) Poller
+	progress    func(*BatchResult)
+}
+
+// NewBatchUploader returns a BatchUploader for the uploader
+func NewBatchUploader(uploader Uploader, opts ...BatchOption) *BatchUploader {
+	b := &BatchUploader{
+		uploader:    uploader,
+		concurrency: batchConcurrency,
+		poller:      func(u Uploader) Poller { return NewPoller(u) },
+		progress:    func(*BatchResult) {},
+	}
+	for _, opt := range opts {
+		opt(b)
+	}
+	return b
+}
+
+// batchEvent is the outcome of uploading or polling a file in the batch
+type batchEvent struct {
+	index  int
+	upload Upload
+	err    error
+	// final is true if no more events will be sent for the file
+	final bool
+}
+
+// Upload uploads the files and polls all pending uploads until processing completes
+//
+// Each file is closed after it is uploaded. The results are in the same order as the files.
+// An error is returned only if the context is canceled, failures uploading an individual file
+// are reported in the results.
+func (b *BatchUploader) Upload(ctx context.Context, files []*File) ([]*BatchResult, error) {
+	results := make([]*BatchResult, len(files))
+	for i, file := range files {
+		results[i] = &BatchResult{File: file}
+	}
+
+	// all upload and poll events are sent to a single channel
+	events := make(chan *batchEvent)
+	indices := make(chan int)
+	var uploads, polls sync.WaitGroup
+	for range min(b.concurrency, max(len(files), 1)) {
+		uploads.Add(1)
+		go func() {
+			defer uploads.Done()
+			for i := range indices {
+				// files are not uploaded once the context is canceled
+				if ctx.Err() != nil {
+					continue
+				}
+				b.upload(ctx, i, files[i], events, &polls)
+			}
+		}()
+	}
+	go func() {
+		defer close(indices)
+		for i := range files {
+			select {
+			case <-ctx.Done():
+				return
+			case indices <- i:
+			}
+		}
+	}()
+	go func() {
+		uploads.Wait()
+		polls.Wait()
+		close(events)
+	}()
+
+	complete := make([]bool, len(files))
+	for event := range events {
+		res := results[event.index]
+		// providers return a nil pointer with an error so the upload is used only without an error
+		if event.err == nil && event.upload != nil {
+			res.UploadID = event.upload.Identifier()
+		}
+		if !event.final {
+			continue
+		}
+		complete[event.index] = true
+		res.Err = event.err
+		if event.err == nil {
+			res.ActivityID, res.Duplicate, res.Err = outcome(event.upload)
+		}
+		b.progress(res)
+	}
+
+	for i, res := range results {
+		if !complete[i] {
+			res.Err = errors.Join(errors.New("upload incomplete"), ctx.Err())
+		}
+	}
+	return results, ctx.Err()
+}
+
+// upload the file and, if processing is not complete, poll the status sending all events to the channel
+func (b *BatchUploader) upload(
+	ctx context.Context, index int, file *File, events chan<- *batchEvent, polls *sync.WaitGroup) {
+	send := func(event *batchEvent) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case events <- event:
+			return true
+		}
+	}
+	upload, err := b.uploader.Upload(ctx, file)
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil || upload.Done() {
+		send(&batchEvent{index: index, upload: upload, err: err, final: true})
+		return
+	}
+	if !send(&batchEvent{index: index, upload: upload}) {
+		return
+	}
+	polls.Add(1)
+	go func() {
+		defer polls.Done()
+		for poll := range b.poller(b.uploader).Poll(ctx, upload.Identifier()) {
+			final := poll.Err != nil || poll.Upload.Done()
+			if !send(&batchEvent{index: index, upload: poll.Upload, err: poll.Err, final: final}) || final {
+				return
+			}
+		}
+	}()
+}
+
+// outcome returns the id of the activity, whether the upload was a duplicate, and any processing error
+//
+// If the Upload does not implement Outcome the activity id is unknown and processing is assumed successful.
+func outcome(upload Upload) (int64, bool, error) {
+	x, ok := upload.(Outcome)
+	if !ok {
+		return 0, false, nil
+	}
+	err := x.Err()
+	if err == nil {
+		return x.ActivityIdentifier(), false, nil
+	}
+	var dup *DuplicateError
+	if errors.As(err, &dup) {
+		return dup.ActivityID, true, nil
+	}
+	return 0, false, err
+}
@@ -0,0 +1,164 @@
+package activity_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+type batchUploader struct {
+	mu       sync.Mutex
+	statuses map[activity.UploadID]int
+	active   atomic.Int32
+	peak     atomic.Int32
+}
+
+func (u *batchUploader) Upload(_ context.Context, file *activity.File) (activity.Upload, error) {
+	n := u.active.Add(1)
+	defer u.active.Add(-1)
+	for {
+		peak := u.peak.Load()
+		if n <= peak || u.peak.CompareAndSwap(peak, n) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	switch string(data) {
+	case "unauthorized":
+		return nil, errors.New("unauthorized")
+	case "rejected":
+		// providers return a nil pointer with the error
+		var upload *syncUpload
+		return upload, errors.New("rejected")
+	case "done":
+		return &syncUpload{id: 1, done: true, activity: 100}, nil
+	case "duplicate":
+		return &syncUpload{id: 2, done: true, err: &activity.DuplicateError{ActivityID: 200, Message: "duplicate"}}, nil
+	case "malformed":
+		return &syncUpload{id: 3}, nil
+	case "stuck":
+		return &syncUpload{id: 4}, nil
+	default:
+		return &syncUpload{id: 5}, nil
+	}
+}
+
+func (u *batchUploader) Status(_ context.Context, id activity.UploadID) (activity.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	u.statuses[id]++
+	switch {
+	case id == 3:
+		return &syncUpload{id: id, done: true, err: errors.New("malformed")}, nil
+	case id == 4:
+		return &syncUpload{id: id}, nil
+	case u.statuses[id] < 3:
+		return &syncUpload{id: id}, nil
+	default:
+		return &syncUpload{id: id, done: true, activity: 500}, nil
+	}
+}
+
+func newBatchFile(content string) *activity.File {
+	return &activity.File{
+		Reader: io.NopCloser(bytes.NewBufferString(content)),
+		Name:   content + ".fit",
+		Format: activity.FormatFIT,
+	}
+}
+
+func TestBatchUpload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	uploader := &batchUploader{statuses: make(map[activity.UploadID]int)}
+	var progress []*activity.BatchResult
+	batch := activity.NewBatchUploader(uploader,
+		activity.WithBatchConcurrency(2),
+		activity.WithBatchProgress(func(res *activity.BatchResult) { progress = append(progress, res) }),
+		activity.WithBatchPoller(func(u activity.Uploader) activity.Poller {
+			return activity.NewPoller(u, activity.WithInterval(time.Millisecond), activity.WithIterations(5))
+		}))
+
+	contents := []string{"done", "unauthorized", "duplicate", "malformed", "stuck", "pending"}
+	files := make([]*activity.File, len(contents))
+	for i, content := range contents {
+		files[i] = newBatchFile(content)
+	}
+	results, err := batch.Upload(context.Background(), files)
+	a.NoError(err)
+	a.Len(results, len(files))
+	a.Len(progress, len(files))
+	a.LessOrEqual(uploader.peak.Load(), int32(2))
+
+	for i, res := range results {
+		a.Same(files[i], res.File)
+	}
+	a.NoError(results[0].Err)
+	a.Equal(int64(100), results[0].ActivityID)
+	a.False(results[0].Duplicate)
+
+	a.EqualError(results[1].Err, "unauthorized")
+	a.Equal(activity.UploadID(0), results[1].UploadID)
+
+	a.NoError(results[2].Err)
+	a.True(results[2].Duplicate)
+	a.Equal(int64(200), results[2].ActivityID)
+
+	a.EqualError(results[3].Err, "malformed")
+	a.Equal(activity.UploadID(3), results[3].UploadID)
+
+	a.ErrorIs(results[4].Err, activity.ErrExceededIterations)
+	a.Equal(activity.UploadID(4), results[4].UploadID)
+
+	a.NoError(results[5].Err)
+	a.Equal(int64(500), results[5].ActivityID)
+	a.Equal(activity.UploadID(5), results[5].UploadID)
+}
+
+func TestBatchUploadFailure(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	batch := activity.NewBatchUploader(&batchUploader{statuses: make(map[activity.UploadID]int)})
+	results, err := batch.Upload(context.Background(), []*activity.File{newBatchFile("done"), newBatchFile("rejected")})
+	a.NoError(err)
+	a.Len(results, 2)
+	a.NoError(results[0].Err)
+	a.Equal(int64(100), results[0].ActivityID)
+	a.EqualError(results[1].Err, "rejected")
+	a.Equal(activity.UploadID(0), results[1].UploadID)
+	a.Zero(results[1].ActivityID)
+}
+
+func TestBatchUploadCanceled(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	batch := activity.NewBatchUploader(&batchUploader{statuses: make(map[activity.UploadID]int)})
+	results, err := batch.Upload(ctx, []*activity.File{newBatchFile("done"), newBatchFile("pending")})
+	a.ErrorIs(err, context.Canceled)
+	a.Len(results, 2)
+	for _, res := range results {
+		a.ErrorIs(res.Err, context.Canceled)
+	}
+
+	results, err = batch.Upload(context.Background(), nil)
+	a.NoError(err)
+	a.Empty(results)
+}
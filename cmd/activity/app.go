@@ -0,0 +1,209 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/rwgps"
+	"github.com/bzimmer/activity/strava"
+	"github.com/bzimmer/activity/zwift"
+)
+
+const (
+	outputJSON  = "json"
+	outputTable = "table"
+)
+
+// command is a subcommand of the cli
+type command struct {
+	name    string
+	usage   string
+	summary string
+	// run the command, the flag set is parsed by the command once its flags are defined
+	run func(ctx context.Context, app *app, fs *flag.FlagSet, args []string) error
+}
+
+func commands() []*command {
+	return []*command{
+		listCommand(),
+		exportCommand(),
+		uploadCommand(),
+		athleteCommand(),
+		streamsCommand(),
+		convertCommand(),
+	}
+}
+
+// app is the state shared by all commands
+type app struct {
+	stdout   io.Writer
+	stderr   io.Writer
+	config   string
+	output   string
+	registry *activity.Registry
+}
+
+// run parses the global flags and runs the command
+func run(ctx context.Context, args []string, stdout, stderr io.Writer) error {
+	registry, err := activity.NewRegistry(
+		cyclinganalytics.Provider(), rwgps.Provider(), strava.Provider(), zwift.Provider())
+	if err != nil {
+		return err
+	}
+	a := &app{stdout: stdout, stderr: stderr, registry: registry}
+	fs := flag.NewFlagSet("activity", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.StringVar(&a.config, "config", defaultConfig(), "path of the YAML or JSON provider configuration")
+	fs.StringVar(&a.output, "output", outputTable, "output format, json or table")
+	fs.Usage = func() { a.usage(fs) }
+	if err = fs.Parse(args); err != nil {
+		return err
+	}
+	if a.output != outputJSON && a.output != outputTable {
+		return fmt.Errorf("unknown output format %q", a.output)
+	}
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return errors.New("missing command")
+	}
+	for _, cmd := range commands() {
+		if cmd.name == fs.Arg(0) {
+			return cmd.run(ctx, a, a.flags(cmd), fs.Args()[1:])
+		}
+	}
+	return fmt.Errorf("unknown command %q", fs.Arg(0))
+}
+
+// defaultConfig returns the path of the configuration in the user's config directory
+func defaultConfig() string {
+	if path := os.Getenv("ACTIVITY_CONFIG"); path != "" {
+		return path
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "activity.yaml"
+	}
+	return filepath.Join(dir, "activity", "config.yaml")
+}
+
+func (a *app) usage(fs *flag.FlagSet) {
+	fmt.Fprintln(a.stderr, "usage: activity [flags] <command> [command flags] <provider> [args]")
+	fmt.Fprintln(a.stderr, "\nflags:")
+	fs.PrintDefaults()
+	fmt.Fprintln(a.stderr, "\ncommands:")
+	for _, cmd := range commands() {
+		fmt.Fprintf(a.stderr, "  %-8s %s\n", cmd.name, cmd.summary)
+	}
+	names := make([]string, 0)
+	for _, provider := range a.registry.Providers() {
+		names = append(names, provider.Name)
+	}
+	fmt.Fprintf(a.stderr, "\nproviders: %s\n", strings.Join(names, ", "))
+}
+
+// flags returns the flag set of the command
+func (a *app) flags(cmd *command) *flag.FlagSet {
+	fs := flag.NewFlagSet(cmd.name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(a.stderr, "usage: activity %s %s\n\n%s\n", cmd.name, cmd.usage, cmd.summary)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parse the arguments of the command returning the provider and remaining arguments
+func (a *app) parse(fs *flag.FlagSet, args []string, nargs int) (string, []string, error) {
+	if err := fs.Parse(args); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() < nargs+1 {
+		fs.Usage()
+		return "", nil, errors.New("missing arguments")
+	}
+	return fs.Arg(0), fs.Args()[1:], nil
+}
+
+// client returns the client of the provider created from the configuration
+func (a *app) client(ctx context.Context, name string) (any, error) {
+	provider, err := a.registry.Provider(name)
+	if err != nil {
+		return nil, err
+	}
+	config, err := activity.LoadConfig(a.config)
+	if err != nil {
+		return nil, err
+	}
+	cfg, ok := config.Providers[name]
+	if !ok {
+		return nil, fmt.Errorf("no configuration for provider %q", name)
+	}
+	return provider.New(ctx, cfg)
+}
+
+// clients returns the client of the provider as Clients to query its capabilities
+func (a *app) clients(ctx context.Context, name string) (activity.Clients, error) {
+	client, err := a.client(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return activity.Clients{name: client}, nil
+}
+
+func (a *app) lister(ctx context.Context, name string) (activity.Lister, error) {
+	clients, err := a.clients(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if lister, ok := clients.Listers()[name]; ok {
+		return lister, nil
+	}
+	return nil, fmt.Errorf("%s does not support listing activities", name)
+}
+
+func (a *app) exporter(ctx context.Context, name string) (activity.Exporter, error) {
+	clients, err := a.clients(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if exporter, ok := clients.Exporters()[name]; ok {
+		return exporter, nil
+	}
+	return nil, fmt.Errorf("%s does not support exporting activities", name)
+}
+
+func (a *app) uploader(ctx context.Context, name string) (activity.Uploader, error) {
+	clients, err := a.clients(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	if uploader, ok := clients.Uploaders()[name]; ok {
+		return uploader, nil
+	}
+	return nil, fmt.Errorf("%s does not support uploading activities", name)
+}
+
+// write the value as JSON or the rows as a table
+func (a *app) write(v any, header []string, rows [][]string) error {
+	if a.output == outputJSON {
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", " ")
+		return enc.Encode(v)
+	}
+	w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
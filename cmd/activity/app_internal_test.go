@@ -0,0 +1,94 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"flag"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+// stravaTestdata is the directory of the strava api responses
+const stravaTestdata = "../../strava/testdata"
+
+// newServer returns a server for the provider and the path of a configuration using it
+func newServer(t *testing.T, provider string, mux *http.ServeMux) (*httptest.Server, string) {
+	svr := httptest.NewServer(mux)
+	t.Cleanup(svr.Close)
+	config := &activity.Config{
+		Providers: map[string]activity.ProviderConfig{
+			provider: {
+				ClientID:    "client",
+				AccessToken: "access",
+				Expiry:      time.Now().Add(time.Hour),
+				BaseURL:     svr.URL,
+			},
+		},
+	}
+	data, err := json.Marshal(config)
+	assert.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "config.json")
+	assert.NoError(t, os.WriteFile(path, data, 0o600))
+	return svr, path
+}
+
+// runApp runs the cli returning stdout and stderr
+func runApp(args ...string) (string, string, error) {
+	var stdout, stderr bytes.Buffer
+	err := run(context.Background(), args, &stdout, &stderr)
+	return stdout.String(), stderr.String(), err
+}
+
+func TestRun(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, config := newServer(t, "zwift", http.NewServeMux())
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "missing command", err: "missing command"},
+		{name: "unknown command", args: []string{"frobnicate"}, err: `unknown command "frobnicate"`},
+		{name: "unknown output", args: []string{"-output", "xml", "list"}, err: `unknown output format "xml"`},
+		{name: "missing provider", args: []string{"list"}, err: "missing arguments"},
+		{name: "unknown provider", args: []string{"-config", config, "list", "garmin"}, err: `"garmin" not registered`},
+		{name: "not configured", args: []string{"-config", config, "list", "strava"}, err: "no configuration"},
+		{
+			name: "missing config",
+			args: []string{"-config", filepath.Join(t.TempDir(), "missing.yaml"), "list", "strava"},
+			err:  "no such file",
+		},
+		{
+			name: "not supported",
+			args: []string{"-config", config, "upload", "zwift", "ride.fit"},
+			err:  "zwift does not support uploading activities",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, _, err := runApp(tt.args...)
+			a.ErrorContains(err, tt.err)
+			a.Empty(stdout)
+		})
+	}
+
+	_, stderr, err := runApp("-h")
+	a.ErrorIs(err, flag.ErrHelp)
+	a.Contains(stderr, "providers: cyclinganalytics, rwgps, strava, zwift")
+
+	_, stderr, err = runApp("list", "-h")
+	a.ErrorIs(err, flag.ErrHelp)
+	a.Contains(stderr, "usage: activity list [-count n] <provider>")
+}
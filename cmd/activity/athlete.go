@@ -0,0 +1,71 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/rwgps"
+	"github.com/bzimmer/activity/strava"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func athleteCommand() *command {
+	return &command{
+		name:    "athlete",
+		usage:   "<provider>",
+		summary: "show the profile of the authenticated user",
+		run:     athlete,
+	}
+}
+
+func athlete(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	name, _, err := a.parse(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	client, err := a.client(ctx, name)
+	if err != nil {
+		return err
+	}
+	var (
+		v  any
+		id int64
+		fn []string
+	)
+	switch c := client.(type) {
+	case *strava.Client:
+		ath, aerr := c.Athlete.Athlete(ctx)
+		if aerr != nil {
+			return aerr
+		}
+		v, id, fn = ath, int64(ath.ID), []string{ath.Firstname, ath.Lastname}
+	case *rwgps.Client:
+		user, aerr := c.Users.AuthenticatedUser(ctx)
+		if aerr != nil {
+			return aerr
+		}
+		// the auth token is a credential and is not displayed
+		user.AuthToken = ""
+		v, id, fn = user, int64(user.ID), []string{user.Name}
+	case *zwift.Client:
+		profile, aerr := c.Profile.Profile(ctx, zwift.Me)
+		if aerr != nil {
+			return aerr
+		}
+		v, id, fn = profile, profile.ID, []string{profile.FirstName, profile.LastName}
+	case *cyclinganalytics.Client:
+		user, aerr := c.User.Me(ctx)
+		if aerr != nil {
+			return aerr
+		}
+		v, id, fn = user, int64(user.ID), []string{user.Name}
+	default:
+		return fmt.Errorf("%s does not support athletes", name)
+	}
+	row := []string{name, strconv.FormatInt(id, 10), strings.Join(fn, " ")}
+	return a.write(v, []string{"PROVIDER", "ID", "NAME"}, [][]string{row})
+}
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAthlete(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/athlete", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "athlete.json"))
+	})
+	_, config := newServer(t, "strava", mux)
+	stdout, _, err := runApp("-config", config, "athlete", "strava")
+	a.NoError(err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	a.Len(lines, 2)
+	a.Equal([]string{"PROVIDER", "ID", "NAME"}, strings.Fields(lines[0]))
+	a.True(strings.HasPrefix(lines[1], "strava"))
+
+	mux = http.NewServeMux()
+	mux.HandleFunc("/users/current.json", func(w http.ResponseWriter, _ *http.Request) {
+		a.NoError(json.NewEncoder(w).Encode(map[string]any{
+			"user": map[string]any{"id": 1122, "name": "Barney", "auth_token": "secret"}}))
+	})
+	_, config = newServer(t, "rwgps", mux)
+	stdout, _, err = runApp("-config", config, "-output", "json", "athlete", "rwgps")
+	a.NoError(err)
+	var user map[string]any
+	a.NoError(json.Unmarshal([]byte(stdout), &user))
+	a.Equal("Barney", user["name"])
+	a.NotContains(stdout, "secret")
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"syscall"
+	"time"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/rwgps"
+	"github.com/bzimmer/activity/strava"
+)
+
+func convertCommand() *command {
+	return &command{
+		name:    "convert",
+		usage:   "[-format gpx|tcx|fit] [-from provider] [-start time] [-out path] [-overwrite] <file>",
+		summary: "convert the activity file or provider model to another format",
+		run:     convert,
+	}
+}
+
+// converted is an activity file written in another format
+type converted struct {
+	Filename string          `json:"filename"`
+	Format   activity.Format `json:"format"`
+	Path     string          `json:"path"`
+}
+
+func convert(_ context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	format := fs.String("format", "", "the output format, gpx, tcx, or fit, defaults to the extension of the output file")
+	from := fs.String("from", "", "the provider of the JSON model, strava, rwgps, or cyclinganalytics, "+
+		"if empty the file is decoded as gpx, tcx, or fit")
+	start := fs.String("start", "", "the RFC 3339 start time of a strava streams model")
+	out := fs.String("out", "", "the path of the output file, stdout if empty")
+	overwrite := fs.Bool("overwrite", false, "overwrite an existing output file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return errors.New("missing arguments")
+	}
+	filename := fs.Arg(0)
+	target := activity.ToFormat(*format)
+	if *format == "" {
+		target = activity.ToFormat(filepath.Ext(*out))
+	}
+	if target == activity.FormatOriginal {
+		return errors.New("missing output format")
+	}
+	var src any
+	switch *from {
+	case "":
+		file, err := activity.OpenFile(filename)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		src = file
+	default:
+		var t time.Time
+		if *start != "" {
+			var err error
+			if t, err = time.Parse(time.RFC3339, *start); err != nil {
+				return fmt.Errorf("invalid start time %q", *start)
+			}
+		}
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			return err
+		}
+		if src, err = model(*from, data, t); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		return activity.Convert(a.stdout, src, target)
+	}
+	if err := writeFile(*out, *overwrite, func(w io.Writer) error {
+		return activity.Convert(w, src, target)
+	}); err != nil {
+		return err
+	}
+	res := &converted{Filename: filename, Format: target, Path: *out}
+	return a.write(res, []string{"FILE", "FORMAT", "PATH"}, [][]string{{filename, target.String(), *out}})
+}
+
+// writeFile writes the file with a temporary file in the same directory which is
+// renamed on success so a failed write leaves no partial file behind
+func writeFile(path string, overwrite bool, write func(w io.Writer) error) error {
+	if !overwrite {
+		if _, err := os.Lstat(path); err == nil {
+			return &os.PathError{Op: "open", Path: path, Err: syscall.EEXIST}
+		}
+	}
+	fp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fp.Name())
+	if err = errors.Join(write(fp), fp.Close()); err != nil {
+		return err
+	}
+	if err = os.Chmod(fp.Name(), 0o644); err != nil { //nolint:gosec // the output file is not secret
+		return err
+	}
+	return os.Rename(fp.Name(), path)
+}
+
+// model decodes the JSON model of the provider
+func model(provider string, data []byte, start time.Time) (any, error) {
+	switch provider {
+	case "strava":
+		return stravaModel(data, start)
+	case "rwgps":
+		return rwgpsModel(data)
+	case "cyclinganalytics":
+		ride := &cyclinganalytics.Ride{}
+		if err := json.Unmarshal(data, ride); err != nil {
+			return nil, err
+		}
+		return ride, nil
+	default:
+		return nil, fmt.Errorf("%s models are not supported", provider)
+	}
+}
+
+// stravaModel decodes an activity or the streams of an activity
+//
+// Streams, which have no start time of their own, are offset from `start`.
+func stravaModel(data []byte, start time.Time) (*strava.Activity, error) {
+	// activities have an id while the streams are keyed by the stream type
+	var probe struct {
+		ID *int64 `json:"id"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return nil, err
+	}
+	if probe.ID != nil {
+		act := &strava.Activity{}
+		if err := json.Unmarshal(data, act); err != nil {
+			return nil, err
+		}
+		return act, nil
+	}
+	streams := &strava.Streams{}
+	if err := json.Unmarshal(data, streams); err != nil {
+		return nil, err
+	}
+	if start.IsZero() {
+		return nil, errors.New("start time required for strava streams")
+	}
+	return &strava.Activity{StartDate: start, Streams: streams}, nil
+}
+
+// rwgpsModel decodes a trip or route
+func rwgpsModel(data []byte) (*rwgps.Trip, error) {
+	// the api wraps the trip or route in an envelope but a bare trip is also accepted
+	var res struct {
+		Trip  *rwgps.Trip `json:"trip"`
+		Route *rwgps.Trip `json:"route"`
+	}
+	if err := json.Unmarshal(data, &res); err != nil {
+		return nil, err
+	}
+	switch {
+	case res.Trip != nil:
+		res.Trip.Type = rwgps.TypeTrip.String()
+		return res.Trip, nil
+	case res.Route != nil:
+		res.Route.Type = rwgps.TypeRoute.String()
+		return res.Route, nil
+	}
+	trip := &rwgps.Trip{}
+	if err := json.Unmarshal(data, trip); err != nil {
+		return nil, err
+	}
+	trip.Type = rwgps.TypeTrip.String()
+	return trip, nil
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	// tcx to gpx written to stdout
+	stdout, _, err := runApp("convert", "-format", "gpx", "../../testdata/activity.tcx")
+	a.NoError(err)
+	a.Contains(stdout, "<trkpt")
+
+	// rwgps trip to gpx
+	stdout, _, err = runApp("convert", "-from", "rwgps", "-format", "gpx", "../../rwgps/testdata/rwgps_trip_94.json")
+	a.NoError(err)
+	a.Contains(stdout, "<trk>")
+
+	// strava streams to fit with the format from the extension of the output file
+	out := filepath.Join(t.TempDir(), "streams.fit")
+	args := []string{
+		"convert", "-from", "strava", "-start", "2021-03-07T08:00:00Z", "-out", out,
+		filepath.Join(stravaTestdata, "streams.json"),
+	}
+	stdout, _, err = runApp(args...)
+	a.NoError(err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	a.Len(lines, 2)
+	a.Equal([]string{"FILE", "FORMAT", "PATH"}, strings.Fields(lines[0]))
+	a.Contains(lines[1], "fit")
+	fp, err := os.Open(out)
+	a.NoError(err)
+	defer fp.Close()
+	fit, err := activity.DecodeFIT(fp)
+	a.NoError(err)
+	series, err := fit.Series()
+	a.NoError(err)
+	a.Positive(series.Len())
+
+	// the output file is not overwritten unless requested
+	_, _, err = runApp(args...)
+	a.ErrorContains(err, "file exists")
+	_, _, err = runApp(append([]string{"convert", "-overwrite"}, args[1:]...)...)
+	a.NoError(err)
+	info, err := os.Stat(out)
+	a.NoError(err)
+	a.Equal(os.FileMode(0o644), info.Mode().Perm())
+
+	// a failed conversion leaves no file behind
+	dir := t.TempDir()
+	_, _, err = runApp("convert", "-from", "strava", "-out", filepath.Join(dir, "activity.fit"),
+		filepath.Join(stravaTestdata, "activity.json"))
+	a.ErrorContains(err, "no streams")
+	entries, err := os.ReadDir(dir)
+	a.NoError(err)
+	a.Empty(entries)
+}
+
+func TestConvertErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	streams := filepath.Join(stravaTestdata, "streams.json")
+	tests := []struct {
+		name string
+		args []string
+		err  string
+	}{
+		{name: "missing file", args: []string{"-format", "gpx"}, err: "missing arguments"},
+		{name: "missing format", args: []string{streams}, err: "missing output format"},
+		{name: "missing start", args: []string{"-from", "strava", "-format", "fit", streams}, err: "start time required"},
+		{
+			name: "invalid start",
+			args: []string{"-from", "strava", "-start", "yesterday", "-format", "fit", streams},
+			err:  `invalid start time "yesterday"`,
+		},
+		{name: "unsupported model", args: []string{"-from", "zwift", "-format", "fit", streams}, err: "not supported"},
+		{name: "unsupported file", args: []string{"-format", "fit", streams}, err: "unsupported file format"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			stdout, _, err := runApp(append([]string{"convert"}, tt.args...)...)
+			a.ErrorContains(err, tt.err)
+			a.Empty(stdout)
+		})
+	}
+}
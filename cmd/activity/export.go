@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+
+	"github.com/bzimmer/activity"
+)
+
+func exportCommand() *command {
+	return &command{
+		name:    "export",
+		usage:   "[-dir path] [-overwrite] <provider> <activity id>...",
+		summary: "export the activity files to a directory",
+		run:     export,
+	}
+}
+
+// exported is an activity file written to the directory
+type exported struct {
+	*activity.Export
+	Path string `json:"path"`
+}
+
+func export(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	dir := fs.String("dir", ".", "the directory to write the files")
+	overwrite := fs.Bool("overwrite", false, "overwrite existing files")
+	name, ids, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	exporter, err := a.exporter(ctx, name)
+	if err != nil {
+		return err
+	}
+	var exports []*exported
+	var rows [][]string
+	for _, id := range ids {
+		activityID, perr := strconv.ParseInt(id, 10, 64)
+		if perr != nil {
+			return fmt.Errorf("invalid activity id %q", id)
+		}
+		exp, xerr := exporter.Export(ctx, activityID)
+		if xerr != nil {
+			return xerr
+		}
+		path, werr := write(exp, filepath.Join(*dir, filename(activityID, exp)), *overwrite)
+		if werr != nil {
+			return werr
+		}
+		exports = append(exports, &exported{Export: exp, Path: path})
+		rows = append(rows, []string{id, exp.Format.String(), path})
+	}
+	return a.write(exports, []string{"ID", "FORMAT", "PATH"}, rows)
+}
+
+// filename of the exported file is the activity id with the extension of the format
+//
+// The format of an original file is unknown so the extension of its name, if any, is used.
+func filename(activityID int64, exp *activity.Export) string {
+	ext := "." + exp.Format.String()
+	if exp.Format == activity.FormatOriginal {
+		ext = filepath.Ext(exp.Name)
+	}
+	return strconv.FormatInt(activityID, 10) + ext
+}
+
+// write the exported file to the path returning the path of the file
+func write(exp *activity.Export, path string, overwrite bool) (string, error) {
+	flags := os.O_WRONLY | os.O_CREATE | os.O_TRUNC
+	if !overwrite {
+		flags |= os.O_EXCL
+	}
+	fp, err := os.OpenFile(path, flags, 0o644) //nolint:gosec // the path is a file in the requested directory
+	if err != nil {
+		return "", errors.Join(err, exp.Close())
+	}
+	_, err = io.Copy(fp, exp.Reader)
+	return path, errors.Join(err, fp.Close(), exp.Close())
+}
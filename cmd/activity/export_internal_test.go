@@ -0,0 +1,85 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/activities/6099369285", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "activity.json"))
+	})
+	mux.HandleFunc("/activities/6099369285/streams/", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "streams_export.json"))
+	})
+	_, config := newServer(t, "strava", mux)
+
+	dir := t.TempDir()
+	stdout, _, err := runApp("-config", config, "-output", "json", "export", "-dir", dir, "strava", "6099369285")
+	a.NoError(err)
+	var exports []map[string]any
+	a.NoError(json.Unmarshal([]byte(stdout), &exports))
+	a.Len(exports, 1)
+	a.Equal("gpx", exports[0]["format"])
+	path, ok := exports[0]["path"].(string)
+	a.True(ok)
+	a.Equal(filepath.Join(dir, "6099369285.gpx"), path)
+	data, err := os.ReadFile(path)
+	a.NoError(err)
+	a.Contains(string(data), "<gpx")
+
+	// the file exists
+	_, _, err = runApp("-config", config, "export", "-dir", dir, "strava", "6099369285")
+	a.ErrorIs(err, os.ErrExist)
+
+	stdout, _, err = runApp("-config", config, "export", "-dir", dir, "-overwrite", "strava", "6099369285")
+	a.NoError(err)
+	a.Contains(stdout, path)
+
+	_, _, err = runApp("-config", config, "export", "strava", "latest")
+	a.EqualError(err, `invalid activity id "latest"`)
+}
+
+func TestFilename(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name     string
+		file     *activity.File
+		filename string
+	}{
+		{
+			name:     "format",
+			file:     &activity.File{Name: "Morning Ride", Format: activity.FormatGPX},
+			filename: "100.gpx",
+		},
+		{
+			name:     "original",
+			file:     &activity.File{Name: "activity.fit", Format: activity.FormatOriginal},
+			filename: "100.fit",
+		},
+		{
+			name:     "original without extension",
+			file:     &activity.File{Name: "Morning Ride", Format: activity.FormatOriginal},
+			filename: "100",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a.Equal(tt.filename, filename(100, &activity.Export{File: tt.file, ID: 100}))
+		})
+	}
+}
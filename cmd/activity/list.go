@@ -0,0 +1,53 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/bzimmer/activity"
+)
+
+func listCommand() *command {
+	return &command{
+		name:    "list",
+		usage:   "[-count n] <provider>",
+		summary: "list the activities of the authenticated user",
+		run:     list,
+	}
+}
+
+func list(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	count := fs.Int("count", 25, "the number of activities to list")
+	name, _, err := a.parse(fs, args, 0)
+	if err != nil {
+		return err
+	}
+	lister, err := a.lister(ctx, name)
+	if err != nil {
+		return err
+	}
+	summaries, err := lister.List(ctx, activity.Pagination{Total: *count})
+	if err != nil {
+		return err
+	}
+	rows := make([][]string, 0, len(summaries))
+	for _, summary := range summaries {
+		rows = append(rows, []string{
+			strconv.FormatInt(summary.ID, 10),
+			summary.StartTime.Format(time.DateOnly),
+			summary.Sport.String(),
+			summary.Name,
+			fmt.Sprintf("%.1f km", summary.Distance.Kilometers()),
+			duration(summary.ElapsedTime.Seconds()).String(),
+		})
+	}
+	return a.write(summaries, []string{"ID", "DATE", "SPORT", "NAME", "DISTANCE", "ELAPSED"}, rows)
+}
+
+// duration returns the seconds as a duration rounded to the second
+func duration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second)).Round(time.Second)
+}
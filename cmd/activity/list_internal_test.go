@@ -0,0 +1,53 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestList(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/athlete/activities", func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer access", r.Header.Get("Authorization"))
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "activities.json"))
+	})
+	_, config := newServer(t, "strava", mux)
+
+	stdout, _, err := runApp("-config", config, "list", "-count", "1", "strava")
+	a.NoError(err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	a.Len(lines, 2)
+	a.Equal([]string{"ID", "DATE", "SPORT", "NAME", "DISTANCE", "ELAPSED"}, strings.Fields(lines[0]))
+	a.Contains(lines[1], "154504250376823")
+	a.Contains(lines[1], "2018-05-02")
+	a.Contains(lines[1], "Happy Friday")
+	a.Contains(lines[1], "24.9 km")
+	a.Contains(lines[1], "1h15m0s")
+
+	stdout, _, err = runApp("-config", config, "-output", "json", "list", "-count", "1", "strava")
+	a.NoError(err)
+	var summaries []map[string]any
+	a.NoError(json.Unmarshal([]byte(stdout), &summaries))
+	a.Len(summaries, 1)
+	a.Equal("strava", summaries[0]["provider"])
+	a.Equal("Happy Friday", summaries[0]["name"])
+}
+
+func TestDuration(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal(time.Duration(0), duration(0))
+	a.Equal(2*time.Second, duration(1.5))
+	a.Equal(time.Minute, duration(59.6))
+	a.Equal(time.Hour+15*time.Minute, duration(4500.2))
+}
@@ -0,0 +1,29 @@
+// Command activity lists, exports, and uploads activities for the supported providers
+//
+// The providers are configured in a YAML or JSON file, see activity.Config.
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+)
+
+func main() {
+	os.Exit(exec())
+}
+
+func exec() int {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout, os.Stderr); err != nil {
+		if !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(os.Stderr, "activity: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
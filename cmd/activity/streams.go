@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/rwgps"
+	"github.com/bzimmer/activity/strava"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func streamsCommand() *command {
+	return &command{
+		name:    "streams",
+		usage:   "[-streams name,...] <provider> <activity id>",
+		summary: "show the time series of the activity",
+		run:     streams,
+	}
+}
+
+func streams(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	names := fs.String("streams", "", "comma separated streams to query, all available streams if empty")
+	name, ids, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	activityID, err := strconv.ParseInt(ids[0], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid activity id %q", ids[0])
+	}
+	client, err := a.client(ctx, name)
+	if err != nil {
+		return err
+	}
+	var keys []string
+	if *names != "" {
+		keys = strings.Split(*names, ",")
+	}
+	data, err := series(ctx, client, activityID, keys)
+	if err != nil {
+		return err
+	}
+	header, rows := table(data)
+	return a.write(data, header, rows)
+}
+
+// series queries the streams of the activity from the provider
+func series(ctx context.Context, client any, activityID int64, keys []string) (*activity.Series, error) {
+	var (
+		enc activity.SeriesEncoder
+		err error
+	)
+	switch c := client.(type) {
+	case *strava.Client:
+		if len(keys) == 0 {
+			keys = slices.Sorted(maps.Keys(c.Activity.StreamSets()))
+		}
+		enc, err = c.Activity.Activity(ctx, activityID, keys...)
+	case *cyclinganalytics.Client:
+		if len(keys) == 0 {
+			keys = slices.Sorted(maps.Keys(c.Rides.StreamSets()))
+		}
+		enc, err = c.Rides.Ride(ctx, activityID, cyclinganalytics.WithRideOptions(cyclinganalytics.RideOptions{
+			Streams: keys,
+		}))
+	case *rwgps.Client:
+		enc, err = c.Trips.Trip(ctx, activityID)
+	case *zwift.Client:
+		exp, xerr := c.Activity.Export(ctx, activityID)
+		if xerr != nil {
+			return nil, xerr
+		}
+		defer exp.Close()
+		enc, err = activity.DecodeFIT(exp.Reader)
+	default:
+		return nil, fmt.Errorf("%T does not support streams", client)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return enc.Series()
+}
+
+// table returns a row for each sample with a column for each available channel
+func table(series *activity.Series) ([]string, [][]string) {
+	type column struct {
+		name  string
+		value func(i int) string
+	}
+	float := func(f float64) string { return strconv.FormatFloat(f, 'f', -1, 64) }
+	columns := []column{{"TIME", func(i int) string { return float(series.Time[i].Seconds()) }}}
+	add := func(name string, n int, value func(i int) string) {
+		if n > 0 {
+			columns = append(columns, column{name, value})
+		}
+	}
+	add("LAT", len(series.Latitude), func(i int) string { return float(series.Latitude[i]) })
+	add("LNG", len(series.Longitude), func(i int) string { return float(series.Longitude[i]) })
+	add("ELEVATION", len(series.Elevation), func(i int) string { return float(series.Elevation[i].Meters()) })
+	add("DISTANCE", len(series.Distance), func(i int) string { return float(series.Distance[i].Meters()) })
+	add("SPEED", len(series.Speed), func(i int) string { return float(series.Speed[i].MetersPerSecond()) })
+	add("HR", len(series.HeartRate), func(i int) string { return float(series.HeartRate[i]) })
+	add("CADENCE", len(series.Cadence), func(i int) string { return float(series.Cadence[i]) })
+	add("POWER", len(series.Power), func(i int) string { return float(series.Power[i].Watts()) })
+	add("TEMP", len(series.Temperature), func(i int) string { return float(series.Temperature[i].Celsius()) })
+
+	header := make([]string, 0, len(columns))
+	for _, c := range columns {
+		header = append(header, c.name)
+	}
+	rows := make([][]string, 0, series.Len())
+	for i := range series.Len() {
+		row := make([]string, 0, len(columns))
+		for _, c := range columns {
+			row = append(row, c.value(i))
+		}
+		rows = append(rows, row)
+	}
+	return header, rows
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStreams(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/activities/6099369285", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "activity.json"))
+	})
+	mux.HandleFunc("/activities/6099369285/streams/", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, filepath.Join(stravaTestdata, "streams_export.json"))
+	})
+	_, config := newServer(t, "strava", mux)
+
+	stdout, _, err := runApp("-config", config, "streams", "strava", "6099369285")
+	a.NoError(err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	a.Greater(len(lines), 1)
+	header := strings.Fields(lines[0])
+	a.Equal("TIME", header[0])
+	a.Contains(header, "LAT")
+	a.Contains(header, "ELEVATION")
+	for _, line := range lines[1:] {
+		a.Len(strings.Fields(line), len(header))
+	}
+
+	stdout, _, err = runApp("-config", config, "-output", "json", "streams", "strava", "6099369285")
+	a.NoError(err)
+	var series map[string]any
+	a.NoError(json.Unmarshal([]byte(stdout), &series))
+	a.Contains(series, "latitude")
+
+	_, _, err = runApp("-config", config, "streams", "-streams", "bogus", "strava", "6099369285")
+	a.ErrorContains(err, "invalid stream 'bogus'")
+}
@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/bzimmer/activity"
+)
+
+func uploadCommand() *command {
+	return &command{
+		name:    "upload",
+		usage:   "[-poll] <provider> <file>...",
+		summary: "upload the activity files",
+		run:     upload,
+	}
+}
+
+// uploaded is the result of uploading a file
+type uploaded struct {
+	*activity.BatchResult
+	Status activity.UploadStatus `json:"status"`
+	Error  string                `json:"error,omitempty"`
+}
+
+func upload(ctx context.Context, a *app, fs *flag.FlagSet, args []string) error {
+	poll := fs.Bool("poll", false, "poll the status of the uploads until processing completes")
+	name, filenames, err := a.parse(fs, args, 1)
+	if err != nil {
+		return err
+	}
+	uploader, err := a.uploader(ctx, name)
+	if err != nil {
+		return err
+	}
+	files := make([]*activity.File, 0, len(filenames))
+	for _, filename := range filenames {
+		file, ferr := activity.OpenFile(filename)
+		if ferr != nil {
+			for _, f := range files {
+				f.Close()
+			}
+			return ferr
+		}
+		files = append(files, file)
+	}
+	var results []*uploaded
+	if *poll {
+		results, err = uploadPoll(ctx, uploader, files)
+	} else {
+		results = uploadFiles(ctx, uploader, files)
+	}
+	if err != nil {
+		return err
+	}
+	var failed int
+	rows := make([][]string, 0, len(results))
+	for _, res := range results {
+		if res.Err != nil {
+			failed++
+		}
+		rows = append(rows, []string{
+			res.File.Filename,
+			strconv.FormatInt(int64(res.UploadID), 10),
+			res.Status.String(),
+			strconv.FormatInt(res.ActivityID, 10),
+			strconv.FormatBool(res.Duplicate),
+			res.Error,
+		})
+	}
+	if err = a.write(results, []string{"FILE", "UPLOAD", "STATUS", "ACTIVITY", "DUPLICATE", "ERROR"}, rows); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d uploads failed", failed, len(results))
+	}
+	return nil
+}
+
+// uploadFiles uploads the files without waiting for processing to complete
+func uploadFiles(ctx context.Context, uploader activity.Uploader, files []*activity.File) []*uploaded {
+	results := make([]*uploaded, 0, len(files))
+	for _, file := range files {
+		res := &uploaded{BatchResult: &activity.BatchResult{File: file}}
+		u, err := uploader.Upload(ctx, file)
+		res.Err = errors.Join(err, file.Close())
+		if err == nil {
+			res.UploadID = u.Identifier()
+			res.Status = activity.StatusOf(u)
+			if x, ok := u.(activity.Outcome); ok {
+				res.ActivityID = x.ActivityIdentifier()
+			}
+		}
+		results = append(results, result(res))
+	}
+	return results
+}
+
+// uploadPoll uploads the files and polls the status until processing completes
+func uploadPoll(ctx context.Context, uploader activity.Uploader, files []*activity.File) ([]*uploaded, error) {
+	batch, err := activity.NewBatchUploader(uploader).Upload(ctx, files)
+	if err != nil {
+		return nil, err
+	}
+	results := make([]*uploaded, 0, len(batch))
+	for _, res := range batch {
+		results = append(results, result(&uploaded{BatchResult: res, Status: activity.StatusReady}))
+	}
+	return results, nil
+}
+
+// result completes the status and error of the upload
+func result(res *uploaded) *uploaded {
+	if res.Err != nil {
+		res.Status = activity.StatusError
+		res.Error = res.Err.Error()
+	}
+	return res
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data, err := os.ReadFile(filepath.Join(stravaTestdata, "example.gpx"))
+	a.NoError(err)
+	dir := t.TempDir()
+	ride, invalid := filepath.Join(dir, "ride.gpx"), filepath.Join(dir, "invalid.gpx")
+	a.NoError(os.WriteFile(ride, data, 0o600))
+	a.NoError(os.WriteFile(invalid, data, 0o600))
+
+	var uploads atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/uploads", func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseMultipartForm(1 << 20))
+		if r.FormValue("filename") == "invalid.gpx" {
+			w.WriteHeader(http.StatusBadRequest)
+			a.NoError(json.NewEncoder(w).Encode(map[string]any{"message": "Bad Request"}))
+			return
+		}
+		a.NoError(json.NewEncoder(w).Encode(map[string]any{"id": 100 + uploads.Add(1)}))
+	})
+	mux.HandleFunc("/uploads/101", func(w http.ResponseWriter, _ *http.Request) {
+		a.NoError(json.NewEncoder(w).Encode(map[string]any{"id": 101, "activity_id": 99}))
+	})
+	_, config := newServer(t, "strava", mux)
+
+	stdout, _, err := runApp("-config", config, "upload", "-poll", "strava", ride)
+	a.NoError(err)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	a.Len(lines, 2)
+	a.Equal([]string{"FILE", "UPLOAD", "STATUS", "ACTIVITY", "DUPLICATE", "ERROR"}, strings.Fields(lines[0]))
+	a.Equal([]string{ride, "101", "ready", "99", "false"}, strings.Fields(lines[1]))
+
+	stdout, _, err = runApp("-config", config, "-output", "json", "upload", "strava", ride, invalid)
+	a.EqualError(err, "1 of 2 uploads failed")
+	var results []map[string]any
+	a.NoError(json.Unmarshal([]byte(stdout), &results))
+	a.Len(results, 2)
+	a.Equal("processing", results[0]["status"])
+	a.InDelta(102, results[0]["upload_id"], 0)
+	a.Equal("error", results[1]["status"])
+	a.Equal("Bad Request", results[1]["error"])
+
+	_, _, err = runApp("-config", config, "upload", "strava", filepath.Join(dir, "missing.gpx"))
+	a.ErrorIs(err, os.ErrNotExist)
+}
@@ -0,0 +1,223 @@
+package activity
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/martinlindhe/unit"
+	"github.com/twpayne/go-gpx"
+)
+
+// gpxDocument is a GPX document decoded as an activity
+type gpxDocument struct {
+	doc *gpx.GPX
+}
+
+var _ GPXEncoder = (*gpxDocument)(nil)
+var _ SeriesEncoder = (*gpxDocument)(nil)
+var _ Summarizer = (*gpxDocument)(nil)
+
+// GPX returns the document
+func (g *gpxDocument) GPX() (*gpx.GPX, error) {
+	return g.doc, nil
+}
+
+// trackpoints returns all the trackpoints of all the tracks
+func (g *gpxDocument) trackpoints() []*gpx.WptType {
+	var pts []*gpx.WptType
+	for _, trk := range g.doc.Trk {
+		for _, seg := range trk.TrkSeg {
+			pts = append(pts, seg.TrkPt...)
+		}
+	}
+	return pts
+}
+
+// Summarize returns a provider-neutral summary of the tracks
+func (g *gpxDocument) Summarize() *Summary {
+	s := &Summary{}
+	if g.doc.Metadata != nil {
+		s.Name = g.doc.Metadata.Name
+	}
+	if len(g.doc.Trk) > 0 {
+		trk := g.doc.Trk[0]
+		s.Sport = ToSport(trk.Type)
+		if trk.Name != "" {
+			s.Name = trk.Name
+		}
+	}
+	pts := g.trackpoints()
+	for i, pt := range pts {
+		if i == 0 {
+			s.StartTime = pt.Time
+			continue
+		}
+		prev := pts[i-1]
+		s.Distance += Haversine(prev.Lat, prev.Lon, pt.Lat, pt.Lon)
+		if pt.Ele > prev.Ele {
+			s.ElevationGain += unit.Length(pt.Ele-prev.Ele) * unit.Meter
+		}
+	}
+	if n := len(pts); n > 0 {
+		s.ElapsedTime = unit.Duration(pts[n-1].Time.Sub(s.StartTime).Seconds()) * unit.Second
+		s.MovingTime = s.ElapsedTime
+	}
+	return s
+}
+
+// Series returns the trackpoints of all the tracks as a provider-neutral time series
+//
+// The distance is the cumulative great-circle distance between the trackpoints.
+func (g *gpxDocument) Series() (*Series, error) {
+	pts := g.trackpoints()
+	n := len(pts)
+	if n == 0 {
+		return nil, errNoTrackpoints
+	}
+	x := &Series{
+		StartTime: pts[0].Time,
+		Time:      make([]unit.Duration, n),
+		Latitude:  make([]float64, n),
+		Longitude: make([]float64, n),
+		Elevation: make([]unit.Length, n),
+		Distance:  make([]unit.Length, n),
+	}
+	for i, pt := range pts {
+		if pt.Time.IsZero() {
+			return nil, errors.New("time is required for all trackpoints")
+		}
+		x.Time[i] = unit.Duration(pt.Time.Sub(x.StartTime).Seconds()) * unit.Second
+		x.Latitude[i], x.Longitude[i] = pt.Lat, pt.Lon
+		x.Elevation[i] = unit.Length(pt.Ele) * unit.Meter
+		if i > 0 {
+			x.Distance[i] = x.Distance[i-1] + Haversine(pts[i-1].Lat, pts[i-1].Lon, pt.Lat, pt.Lon)
+		}
+	}
+	return x, nil
+}
+
+// EncodeGPX encodes the series as a GPX track
+//
+// Samples without a coordinate are skipped. The summary is optional and, if provided, is used
+// for the name and the sport of the track.
+func EncodeGPX(w io.Writer, summary *Summary, series *Series) error {
+	if series == nil {
+		return errors.New("missing series")
+	}
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	seg := &gpx.TrkSegType{}
+	for i := range series.Len() {
+		if !series.HasLatLng(i) {
+			continue
+		}
+		pt := &gpx.WptType{
+			Lat:  series.Latitude[i],
+			Lon:  series.Longitude[i],
+			Time: series.Timestamp(i),
+		}
+		if series.Elevation != nil {
+			pt.Ele = nan(series.Elevation[i].Meters())
+		}
+		seg.TrkPt = append(seg.TrkPt, pt)
+	}
+	if len(seg.TrkPt) == 0 {
+		return errors.New("no coordinates available for gpx encoding")
+	}
+	trk := &gpx.TrkType{TrkSeg: []*gpx.TrkSegType{seg}}
+	if summary != nil {
+		trk.Name = summary.Name
+		trk.Type = summary.Sport.String()
+	}
+	x := &gpx.GPX{
+		Version: "1.1",
+		Creator: UserAgent,
+		Trk:     []*gpx.TrkType{trk},
+	}
+	return x.Write(w)
+}
+
+// decode the file as an activity
+func decode(file *File) (any, error) {
+	switch file.Format {
+	case FormatGPX:
+		b, err := file.buffer()
+		if err != nil {
+			return nil, err
+		}
+		doc, err := gpx.Read(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		return &gpxDocument{doc: doc}, nil
+	case FormatTCX:
+		return file.TCX()
+	case FormatFIT:
+		return file.FIT()
+	case FormatOriginal:
+		return nil, fmt.Errorf("unsupported file format for %q", file.Name)
+	default:
+		return nil, fmt.Errorf("unsupported file format %q", file.Format)
+	}
+}
+
+// Convert writes the activity in the format
+//
+// The source is a *File in the GPX, TCX, or FIT format or any model implementing GPXEncoder or
+// SeriesEncoder, such as a provider's activity. GPX output uses the model's GPX encoding if
+// available, otherwise the track is encoded from the series. TCX and FIT output require the
+// series and use the model's summary if it implements Summarizer. Channels the output format
+// does not support are dropped.
+func Convert(w io.Writer, src any, format Format) error {
+	if file, ok := src.(*File); ok {
+		var err error
+		if src, err = decode(file); err != nil {
+			return err
+		}
+	}
+	var summary *Summary
+	if x, ok := src.(Summarizer); ok {
+		summary = x.Summarize()
+	}
+	series := func() (*Series, error) {
+		x, ok := src.(SeriesEncoder)
+		if !ok {
+			return nil, fmt.Errorf("%T does not support series encoding", src)
+		}
+		return x.Series()
+	}
+	switch format {
+	case FormatGPX:
+		if x, ok := src.(GPXEncoder); ok {
+			doc, err := x.GPX()
+			if err != nil {
+				return err
+			}
+			return doc.Write(w)
+		}
+		s, err := series()
+		if err != nil {
+			return err
+		}
+		return EncodeGPX(w, summary, s)
+	case FormatTCX:
+		s, err := series()
+		if err != nil {
+			return err
+		}
+		return EncodeTCX(w, summary, s)
+	case FormatFIT:
+		s, err := series()
+		if err != nil {
+			return err
+		}
+		return EncodeFIT(w, summary, s)
+	case FormatOriginal:
+		return errors.New("a target format is required")
+	default:
+		return fmt.Errorf("unsupported format %q", format)
+	}
+}
@@ -0,0 +1,153 @@
+package activity_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/twpayne/go-gpx"
+
+	"github.com/bzimmer/activity"
+)
+
+// route is a model with a GPX encoding but no time series
+type route struct{}
+
+func (r *route) GPX() (*gpx.GPX, error) {
+	return &gpx.GPX{Version: "1.1", Rte: []*gpx.RteType{{Name: "route"}}}, nil
+}
+
+func convert(t *testing.T, filename string, format activity.Format) (*bytes.Buffer, error) {
+	file, err := activity.OpenFile(filename)
+	assert.NoError(t, err)
+	defer file.Close()
+	var buf bytes.Buffer
+	return &buf, activity.Convert(&buf, file, format)
+}
+
+func TestConvert(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+
+	// tcx to gpx skips the samples without coordinates
+	buf, err := convert(t, "testdata/activity.tcx", activity.FormatGPX)
+	a.NoError(err)
+	doc, err := gpx.Read(buf)
+	a.NoError(err)
+	a.Len(doc.Trk, 1)
+	a.Equal("cycling", doc.Trk[0].Type)
+	a.Len(doc.Trk[0].TrkSeg[0].TrkPt, 2)
+	a.Equal(47.2, doc.Trk[0].TrkSeg[0].TrkPt[1].Lat)
+	a.Equal(start.Add(2*time.Second), doc.Trk[0].TrkSeg[0].TrkPt[1].Time)
+
+	// tcx to fit preserves all the channels
+	buf, err = convert(t, "testdata/activity.tcx", activity.FormatFIT)
+	a.NoError(err)
+	data := buf.Bytes()
+	fit, err := activity.DecodeFIT(bytes.NewReader(data))
+	a.NoError(err)
+	series, err := fit.Series()
+	a.NoError(err)
+	a.Equal(3, series.Len())
+	a.Equal(start, series.StartTime)
+	a.InDelta(220, series.Power[2].Watts(), 0.001)
+	a.Equal(activity.SportCycling, fit.Summarize().Sport)
+
+	// fit to gpx
+	file, err := activity.NewFile(bytes.NewReader(data), "activity.fit")
+	a.NoError(err)
+	a.Equal(activity.FormatFIT, file.Format)
+	var out bytes.Buffer
+	a.NoError(activity.Convert(&out, file, activity.FormatGPX))
+	doc, err = gpx.Read(&out)
+	a.NoError(err)
+	a.Len(doc.Trk[0].TrkSeg[0].TrkPt, 2)
+
+	// gpx to tcx derives the distance from the coordinates
+	buf, err = convert(t, "testdata/activity.gpx", activity.FormatTCX)
+	a.NoError(err)
+	tcx, err := activity.DecodeTCX(buf)
+	a.NoError(err)
+	summary := tcx.Summarize()
+	a.Equal(activity.SportCycling, summary.Sport)
+	a.Equal(start, summary.StartTime)
+	a.InDelta(2, summary.MovingTime.Seconds(), 0.001)
+	a.InDelta(22.2, summary.Distance.Meters(), 0.1)
+	a.InDelta(2, summary.ElevationGain.Meters(), 0.001)
+
+	// gpx to gpx uses the document
+	buf, err = convert(t, "testdata/activity.gpx", activity.FormatGPX)
+	a.NoError(err)
+	doc, err = gpx.Read(buf)
+	a.NoError(err)
+	a.Equal("Morning Ride", doc.Trk[0].Name)
+	a.Len(doc.Trk[0].TrkSeg[0].TrkPt, 3)
+}
+
+func TestConvertErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	untimed := `<gpx version="1.1"><trk><trkseg><trkpt lat="47.1" lon="-122.1"></trkpt></trkseg></trk></gpx>`
+	tests := []struct {
+		name   string
+		src    func() any
+		format activity.Format
+		err    string
+	}{
+		{
+			name:   "gpx model",
+			src:    func() any { return &route{} },
+			format: activity.FormatGPX,
+		},
+		{
+			name:   "no series",
+			src:    func() any { return &route{} },
+			format: activity.FormatTCX,
+			err:    "does not support series encoding",
+		},
+		{
+			name:   "no format",
+			src:    func() any { return &route{} },
+			format: activity.FormatOriginal,
+			err:    "a target format is required",
+		},
+		{
+			name: "unsupported file",
+			src: func() any {
+				file, err := activity.NewFile(strings.NewReader("hello"), "notes.txt")
+				a.NoError(err)
+				return file
+			},
+			format: activity.FormatGPX,
+			err:    `unsupported file format for "notes.txt"`,
+		},
+		{
+			name: "no time",
+			src: func() any {
+				file, err := activity.NewFile(strings.NewReader(untimed), "route.gpx")
+				a.NoError(err)
+				return file
+			},
+			format: activity.FormatFIT,
+			err:    "time is required for all trackpoints",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			err := activity.Convert(&buf, tt.src(), tt.format)
+			if tt.err != "" {
+				a.ErrorContains(err, tt.err)
+				return
+			}
+			a.NoError(err)
+			a.Contains(buf.String(), "<rte>")
+		})
+	}
+}
@@ -0,0 +1,143 @@
+package activity
+
+//go:generate stringer -type=CoursePointType -linecomment -output=course_string.go
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+const earthRadius = 6371008.8 * unit.Meter
+
+// courseSpeed is the nominal speed used to generate timestamps for course points
+const courseSpeed = 20 * unit.KilometersPerHour
+
+// CoursePointType is the kind of a course point
+type CoursePointType int
+
+const (
+	CoursePointGeneric        CoursePointType = iota // generic
+	CoursePointSummit                                // summit
+	CoursePointValley                                // valley
+	CoursePointWater                                 // water
+	CoursePointFood                                  // food
+	CoursePointDanger                                // danger
+	CoursePointLeft                                  // left
+	CoursePointRight                                 // right
+	CoursePointStraight                              // straight
+	CoursePointFirstAid                              // first_aid
+	CoursePointFourthCategory                        // fourth_category
+	CoursePointThirdCategory                         // third_category
+	CoursePointSecondCategory                        // second_category
+	CoursePointFirstCategory                         // first_category
+	CoursePointHorsCategory                          // hors_category
+	CoursePointSprint                                // sprint
+)
+
+// MarshalJSON converts a CoursePointType enum to a string representation
+func (c CoursePointType) MarshalJSON() ([]byte, error) {
+	return fmt.Appendf(nil, `"%s"`, c.String()), nil
+}
+
+// ToCoursePointType converts a name to a CoursePointType
+// If no mapping exists the CoursePointType Generic is returned
+func ToCoursePointType(name string) CoursePointType {
+	name = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(name)), " ", "_")
+	for c := CoursePointGeneric; c <= CoursePointSprint; c++ {
+		if c.String() == name {
+			return c
+		}
+	}
+	return CoursePointGeneric
+}
+
+// CoursePoint is a point of interest along a course
+type CoursePoint struct {
+	Name string          `json:"name"`
+	Type CoursePointType `json:"type"`
+	// Index of the course coordinate for the point
+	Index int `json:"index"`
+}
+
+// Course is a planned route
+type Course struct {
+	Name      string         `json:"name"`
+	Sport     Sport          `json:"sport"`
+	Latitude  []float64      `json:"latitude"`
+	Longitude []float64      `json:"longitude"`
+	Elevation []unit.Length  `json:"elevation,omitempty" units:"m"`
+	Points    []*CoursePoint `json:"points,omitempty"`
+}
+
+// Len returns the number of points in the course
+func (c *Course) Len() int {
+	return len(c.Latitude)
+}
+
+// Validate the course is well-formed
+func (c *Course) Validate() error {
+	n := c.Len()
+	if n == 0 {
+		return errors.New("no course points")
+	}
+	if len(c.Longitude) != n {
+		return errors.New("both latitude and longitude are required")
+	}
+	if c.Elevation != nil && len(c.Elevation) != n {
+		return errors.New("invalid length for elevation")
+	}
+	for _, p := range c.Points {
+		if p.Index < 0 || p.Index >= n {
+			return fmt.Errorf("invalid index for course point '%s': %d", p.Name, p.Index)
+		}
+	}
+	return nil
+}
+
+// Distance returns the cumulative distance along the course at each point
+func (c *Course) Distance() []unit.Length {
+	n := c.Len()
+	if n == 0 {
+		return nil
+	}
+	d := make([]unit.Length, n)
+	for i := 1; i < n; i++ {
+		d[i] = d[i-1] + Haversine(c.Latitude[i-1], c.Longitude[i-1], c.Latitude[i], c.Longitude[i])
+	}
+	return d
+}
+
+// Nearest returns the index of the course coordinate nearest the location
+func (c *Course) Nearest(lat, lng float64) int {
+	idx, best := 0, unit.Length(math.Inf(1))
+	for i := range c.Len() {
+		if d := Haversine(lat, lng, c.Latitude[i], c.Longitude[i]); d < best {
+			idx, best = i, d
+		}
+	}
+	return idx
+}
+
+// timestamps generates a time for each coordinate from the cumulative distance at a nominal speed
+func (c *Course) timestamps(start time.Time) []time.Time {
+	d := c.Distance()
+	t := make([]time.Time, len(d))
+	for i := range d {
+		t[i] = start.Add(time.Duration(d[i].Meters() / courseSpeed.MetersPerSecond() * float64(time.Second)))
+	}
+	return t
+}
+
+// Haversine returns the great-circle distance between two coordinates
+func Haversine(lat1, lng1, lat2, lng2 float64) unit.Length {
+	rad := func(deg float64) float64 { return deg * math.Pi / 180 }
+	dlat := rad(lat2 - lat1)
+	dlng := rad(lng2 - lng1)
+	h := math.Pow(math.Sin(dlat/2), 2) + math.Cos(rad(lat1))*math.Cos(rad(lat2))*math.Pow(math.Sin(dlng/2), 2)
+	return unit.Length(2*math.Asin(math.Min(1, math.Sqrt(h)))) * earthRadius
+}
@@ -0,0 +1,102 @@
+package activity_test
+
+import (
+	"testing"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestCourse(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name   string
+		err    string
+		course *activity.Course
+	}{
+		{
+			name:   "empty",
+			err:    "no course points",
+			course: &activity.Course{},
+		},
+		{
+			name:   "valid",
+			course: &activity.Course{Latitude: []float64{47.1, 47.2}, Longitude: []float64{-122.1, -122.2}},
+		},
+		{
+			name:   "missing longitude",
+			err:    "both latitude and longitude are required",
+			course: &activity.Course{Latitude: []float64{47.1, 47.2}},
+		},
+		{
+			name: "invalid elevation",
+			err:  "invalid length for elevation",
+			course: &activity.Course{
+				Latitude:  []float64{47.1, 47.2},
+				Longitude: []float64{-122.1, -122.2},
+				Elevation: []unit.Length{10},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.course.Validate()
+			if tt.err != "" {
+				a.Error(err)
+				a.Contains(err.Error(), tt.err)
+				return
+			}
+			a.NoError(err)
+		})
+	}
+}
+
+func TestCourseDistance(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	c := &activity.Course{
+		Latitude:  []float64{0, 0, 1},
+		Longitude: []float64{0, 1, 1},
+	}
+	d := c.Distance()
+	a.Len(d, 3)
+	a.Zero(d[0].Meters())
+	// one degree of arc along the equator
+	a.InDelta(111195, d[1].Meters(), 1)
+	a.InDelta(2*111195, d[2].Meters(), 2)
+	a.Nil((&activity.Course{}).Distance())
+
+	a.InDelta(0, activity.Haversine(47.6, -122.3, 47.6, -122.3).Meters(), 0.001)
+}
+
+func TestCoursePointType(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal(activity.CoursePointFirstAid, activity.ToCoursePointType("First Aid"))
+	a.Equal(activity.CoursePointSprint, activity.ToCoursePointType("sprint"))
+	a.Equal(activity.CoursePointGeneric, activity.ToCoursePointType("unknown"))
+
+	b, err := activity.CoursePointHorsCategory.MarshalJSON()
+	a.NoError(err)
+	a.Equal(`"hors_category"`, string(b))
+}
+
+func TestCourseNearest(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	c := &activity.Course{
+		Latitude:  []float64{47.1, 47.2, 47.3},
+		Longitude: []float64{-122.1, -122.2, -122.3},
+	}
+	a.Equal(0, c.Nearest(47, -122))
+	a.Equal(1, c.Nearest(47.21, -122.19))
+	a.Equal(2, c.Nearest(48, -123))
+}
@@ -0,0 +1,22 @@
+package cyclinganalytics
+
+import (
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+)
+
+// NewAuthorizer returns an Authorizer for acquiring a token with the scopes
+//
+// More information on scopes can be found at https://www.cyclinganalytics.com/developer/api
+func NewAuthorizer(
+	clientID, clientSecret string, scopes []string, opts ...activity.AuthorizerOption) *activity.Authorizer {
+	config := oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint:     Endpoint(),
+		Scopes:       scopes,
+	}
+	opts = append([]activity.AuthorizerOption{activity.WithAuthorizerScopeDelimiter(",")}, opts...)
+	return activity.NewAuthorizer(config, opts...)
+}
@@ -0,0 +1,32 @@
+package cyclinganalytics_test
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+)
+
+func TestNewAuthorizer(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	stop := errors.New("stop")
+	authorizer := cyclinganalytics.NewAuthorizer("client", "secret", []string{"read_account", "read_rides"},
+		activity.WithAuthorizerOpen(func(_ context.Context, uri string) error {
+			u, err := url.Parse(uri)
+			a.NoError(err)
+			a.Equal("www.cyclinganalytics.com", u.Host)
+			a.Equal("client", u.Query().Get("client_id"))
+			a.Equal("read_account,read_rides", u.Query().Get("scope"))
+			return stop
+		}))
+	token, err := authorizer.Authorize(context.Background())
+	a.Nil(token)
+	a.ErrorIs(err, stop)
+}
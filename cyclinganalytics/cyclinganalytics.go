@@ -1,6 +1,6 @@
 package cyclinganalytics
 
-//go:generate genwith --do --client --endpoint-func --config --token --ratelimit --package cyclinganalytics
+//go:generate genwith --client --endpoint-func --config --ratelimit --package cyclinganalytics
 
 import (
 	"context"
@@ -26,6 +26,7 @@ type Client struct {
 	token   *oauth2.Token
 	client  *http.Client
 	baseURL string
+	hooks   activity.RefreshHooks
 
 	User  *UserService
 	Rides *RidesService
@@ -85,3 +86,7 @@ func (c *Client) newAPIRequest(
 func (c *Client) Uploader() activity.Uploader {
 	return newUploader(c.Rides)
 }
+
+func (c *Client) Lister() activity.Lister {
+	return newLister(c.Rides)
+}
@@ -3,6 +3,7 @@ package cyclinganalytics_test
 import (
 	"context"
 	"net/http"
+	"path/filepath"
 	"testing"
 	"time"
 
@@ -10,20 +11,31 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 
+	"github.com/bzimmer/activity"
 	"github.com/bzimmer/activity/cyclinganalytics"
 )
 
 func TestWith(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
+	path := filepath.Join(t.TempDir(), "cyclinganalytics.json")
 	client, err := cyclinganalytics.NewClient(
 		cyclinganalytics.WithConfig(oauth2.Config{}),
 		cyclinganalytics.WithHTTPTracing(true),
 		cyclinganalytics.WithHTTPClient(http.DefaultClient),
 		cyclinganalytics.WithToken(&oauth2.Token{}),
+		cyclinganalytics.WithTokenStore(context.Background(), activity.NewFileTokenStore(path)),
+		cyclinganalytics.WithRefreshHook(func(context.Context, *oauth2.Token) error { return nil }),
 		cyclinganalytics.WithAutoRefresh(context.Background()),
 		cyclinganalytics.WithRateLimiter(rate.NewLimiter(rate.Every(time.Second), 10)),
 		cyclinganalytics.WithClientCredentials("foo", "bar"))
 	a.NoError(err)
 	a.NotNil(client)
+
+	client, err = cyclinganalytics.NewClient(cyclinganalytics.WithTokenStore(context.Background(), nil))
+	a.Error(err)
+	a.Nil(client)
+	client, err = cyclinganalytics.NewClient(cyclinganalytics.WithRefreshHook(nil))
+	a.Error(err)
+	a.Nil(client)
 }
@@ -0,0 +1,210 @@
+// Package cyclinganalyticstest provides a stateful fake of the Cycling Analytics API for testing
+//
+// The Server implements the endpoints used by the cyclinganalytics package so clients created
+// with cyclinganalytics.WithBaseURL(server.URL) can be tested without access to Cycling Analytics.
+package cyclinganalyticstest
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/internal/fakeserver"
+)
+
+// AccessToken is the default access token accepted by the server
+const AccessToken = "cyclinganalyticstest-access-token"
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAccessToken sets the access token required by the endpoints
+func WithAccessToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithUser sets the authenticated user
+func WithUser(user *cyclinganalytics.User) Option {
+	return func(s *Server) {
+		s.user = user
+	}
+}
+
+// WithProcessing sets the number of status requests before an upload creates a ride
+func WithProcessing(polls int) Option {
+	return func(s *Server) {
+		s.polls = polls
+	}
+}
+
+// Server is a fake of the Cycling Analytics API safe for concurrent use
+type Server struct {
+	*fakeserver.Server
+
+	token string
+	polls int
+
+	mu      sync.Mutex
+	ids     fakeserver.IDs
+	user    *cyclinganalytics.User
+	rides   map[int64]*cyclinganalytics.Ride
+	uploads *fakeserver.Uploads[string]
+}
+
+// NewServer starts and returns a new Server, the caller should call Close when finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		token: AccessToken,
+		polls: 1,
+		user:  &cyclinganalytics.User{ID: 1, Name: "cyclinganalyticstest", Units: "metric"},
+		rides: make(map[int64]*cyclinganalytics.Ride),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.uploads = fakeserver.NewUploads[string](s.polls)
+	s.Server = fakeserver.Start(s.handler())
+	return s
+}
+
+// NewClient returns a cyclinganalytics.Client for the server authenticated with the server's access token
+func (s *Server) NewClient(opts ...cyclinganalytics.Option) (*cyclinganalytics.Client, error) {
+	opts = append([]cyclinganalytics.Option{
+		cyclinganalytics.WithBaseURL(s.URL),
+		cyclinganalytics.WithTokenCredentials(s.token, "", time.Time{}),
+	}, opts...)
+	return cyclinganalytics.NewClient(opts...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /me", s.authorize(s.getUser))
+	mux.HandleFunc("GET /me/rides", s.authorize(s.listRides))
+	mux.HandleFunc("GET /ride/{id}", s.authorize(s.getRide))
+	mux.HandleFunc("POST /me/upload", s.authorize(s.createUpload))
+	mux.HandleFunc("GET /me/upload/{upload}", s.authorize(s.getUpload))
+	mux.HandleFunc("POST /user/{user}/upload", s.authorize(s.self(s.createUpload)))
+	mux.HandleFunc("GET /user/{user}/upload/{upload}", s.authorize(s.self(s.getUpload)))
+	return mux
+}
+
+// authorize requires the bearer token of the user
+func (s *Server) authorize(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			fault(w, http.StatusUnauthorized, "invalid access token")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// self requires the user id of the path to be the authenticated user
+func (s *Server) self(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.mu.Lock()
+		ok := r.PathValue("user") == strconv.Itoa(int(s.user.ID))
+		s.mu.Unlock()
+		if !ok {
+			fault(w, http.StatusNotFound, "user not found")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// AddRide adds a ride of the authenticated user returning its id
+//
+// An id is assigned if the ride does not have one. The streams of the ride are returned only
+// if requested.
+func (s *Server) AddRide(ride *cyclinganalytics.Ride) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := *ride
+	if x.ID == 0 {
+		x.ID = s.ids.Next()
+	}
+	x.UserID = s.user.ID
+	s.rides[x.ID] = &x
+	return x.ID
+}
+
+// Ride returns a copy of the ride or nil if it does not exist
+func (s *Server) Ride(rideID int64) *cyclinganalytics.Ride {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ride, ok := s.rides[rideID]
+	if !ok {
+		return nil
+	}
+	x := *ride
+	return &x
+}
+
+func (s *Server) getUser(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fakeserver.Encode(w, http.StatusOK, s.user)
+}
+
+// listRides returns all the user's rides newest first without streams
+func (s *Server) listRides(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rides := make([]*cyclinganalytics.Ride, 0, len(s.rides))
+	for _, ride := range s.rides {
+		x := *ride
+		x.Streams = cyclinganalytics.Streams{}
+		rides = append(rides, &x)
+	}
+	slices.SortFunc(rides, func(a, b *cyclinganalytics.Ride) int {
+		return cmp.Or(b.UTCDatetime.Compare(a.UTCDatetime.Time), cmp.Compare(b.ID, a.ID))
+	})
+	fakeserver.Encode(w, http.StatusOK, map[string]any{"rides": rides})
+}
+
+// getRide returns the ride including the streams in the `streams` query parameter
+func (s *Server) getRide(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	ride := s.Ride(id)
+	if err != nil || ride == nil {
+		fault(w, http.StatusNotFound, "ride not found")
+		return
+	}
+	streams, err := filter(&ride.Streams, r.URL.Query().Get("streams"))
+	if err != nil {
+		fault(w, http.StatusInternalServerError, err.Error())
+		return
+	}
+	ride.Streams = *streams
+	fakeserver.Encode(w, http.StatusOK, ride)
+}
+
+// filter returns only the streams in the comma separated list of names
+func filter(streams *cyclinganalytics.Streams, names string) (*cyclinganalytics.Streams, error) {
+	fields, err := fakeserver.Fields(streams, strings.Split(names, ","))
+	if err != nil {
+		return nil, err
+	}
+	data, err := json.Marshal(fields)
+	if err != nil {
+		return nil, err
+	}
+	res := &cyclinganalytics.Streams{}
+	if err = json.Unmarshal(data, res); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func fault(w http.ResponseWriter, status int, message string) {
+	fakeserver.Encode(w, status, &cyclinganalytics.Fault{Code: status, Message: message})
+}
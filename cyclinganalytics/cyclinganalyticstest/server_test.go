@@ -0,0 +1,80 @@
+package cyclinganalyticstest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/cyclinganalytics/cyclinganalyticstest"
+)
+
+func newServer(
+	t *testing.T, opts ...cyclinganalyticstest.Option) (*cyclinganalyticstest.Server, *cyclinganalytics.Client) {
+	t.Helper()
+	svr := cyclinganalyticstest.NewServer(opts...)
+	t.Cleanup(svr.Close)
+	client, err := svr.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svr, client
+}
+
+func TestUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, cyclinganalyticstest.WithUser(&cyclinganalytics.User{ID: 2000, Name: "Some One"}))
+	user, err := client.User.Me(context.Background())
+	a.NoError(err)
+	a.Equal(cyclinganalytics.UserID(2000), user.ID)
+	a.Equal("Some One", user.Name)
+
+	client, err = svr.NewClient(cyclinganalytics.WithTokenCredentials("other", "", time.Time{}))
+	a.NoError(err)
+	user, err = client.User.Me(context.Background())
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+	a.Nil(user)
+}
+
+func TestRides(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	ctx := context.Background()
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	for i := range 4 {
+		svr.AddRide(&cyclinganalytics.Ride{
+			Title:       "ride",
+			UTCDatetime: cyclinganalytics.Datetime{Time: start.AddDate(0, 0, i)},
+			Streams: cyclinganalytics.Streams{
+				Power:     []float64{100, 200},
+				Heartrate: []float64{120, 140},
+			},
+		})
+	}
+
+	rides, err := client.Rides.Rides(ctx, cyclinganalytics.Me, activity.Pagination{Total: 3})
+	a.NoError(err)
+	a.Len(rides, 3)
+	// newest first without streams
+	a.Equal(start.AddDate(0, 0, 3), rides[0].UTCDatetime.Time)
+	a.Nil(rides[0].Streams.Power)
+
+	ride, err := client.Rides.Ride(ctx, rides[0].ID,
+		cyclinganalytics.WithRideOptions(cyclinganalytics.RideOptions{Streams: []string{"power", "cadence"}}))
+	a.NoError(err)
+	a.Equal([]float64{100, 200}, ride.Streams.Power)
+	a.Nil(ride.Streams.Heartrate)
+	a.Nil(ride.Streams.Cadence)
+
+	ride, err = client.Rides.Ride(ctx, rides[0].ID+100)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(ride)
+}
@@ -0,0 +1,103 @@
+package cyclinganalyticstest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/internal/fakeserver"
+)
+
+// createUpload accepts an activity file for processing, the details of the upload are its format
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		fault(w, http.StatusBadRequest, "invalid upload")
+		return
+	}
+	fp, hdr, err := r.FormFile("data")
+	if err != nil {
+		fault(w, http.StatusBadRequest, "missing data")
+		return
+	}
+	defer fp.Close()
+	format := strings.TrimPrefix(strings.ToLower(filepath.Ext(hdr.Filename)), ".")
+	switch format {
+	case "fit", "gpx", "tcx", "srm", "pwx":
+	default:
+		fault(w, http.StatusBadRequest, "unsupported file format")
+		return
+	}
+	u, err := fakeserver.NewUpload(cmp.Or(r.FormValue("filename"), hdr.Filename), fp, format)
+	if err != nil {
+		fault(w, http.StatusBadRequest, "invalid data")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads.Add(s.ids.Next(), u)
+	fakeserver.Encode(w, http.StatusOK, s.status(u))
+}
+
+// getUpload returns the status of the upload
+func (s *Server) getUpload(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("upload"), 10, 64)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads.Status(id, s.exists, s.create)
+	if err != nil || !ok {
+		fault(w, http.StatusNotFound, "upload not found")
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, s.status(u))
+}
+
+// exists returns true if the ride exists, the lock must be held
+func (s *Server) exists(rideID int64) bool {
+	_, ok := s.rides[rideID]
+	return ok
+}
+
+// create the ride of the upload, the lock must be held
+func (s *Server) create(u *fakeserver.Upload[string]) (int64, error) {
+	ride := &cyclinganalytics.Ride{
+		ID:            s.ids.Next(),
+		Format:        u.Details,
+		Title:         strings.TrimSuffix(u.Filename, filepath.Ext(u.Filename)),
+		UserID:        s.user.ID,
+		LocalDatetime: cyclinganalytics.Datetime{Time: u.Updated},
+		UTCDatetime:   cyclinganalytics.Datetime{Time: u.Updated},
+	}
+	s.rides[ride.ID] = ride
+	return ride.ID, nil
+}
+
+// status of the upload as reported by Cycling Analytics, the lock must be held
+func (s *Server) status(u *fakeserver.Upload[string]) *cyclinganalytics.Upload {
+	res := &cyclinganalytics.Upload{
+		ID:       u.ID,
+		RideID:   u.ResourceID,
+		UserID:   s.user.ID,
+		Format:   u.Details,
+		Datetime: cyclinganalytics.Datetime{Time: u.Created},
+		Filename: u.Filename,
+		Size:     u.Size,
+	}
+	var dup *fakeserver.DuplicateError
+	switch {
+	case !u.Done():
+		res.Status = "processing"
+	case errors.As(u.Err, &dup):
+		res.Status, res.ErrorCode = "error", "duplicate_ride"
+		res.Error = fmt.Sprintf("The ride already exists: %d", dup.ID)
+	case u.Err != nil:
+		res.Status, res.Error, res.ErrorCode = "error", "The file is empty", "empty_file"
+	default:
+		res.Status = "done"
+	}
+	return res
+}
@@ -0,0 +1,62 @@
+package cyclinganalyticstest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+	"github.com/bzimmer/activity/cyclinganalytics/cyclinganalyticstest"
+)
+
+func file(contents string) *activity.File {
+	return &activity.File{Reader: strings.NewReader(contents), Name: "ride.fit", Format: activity.FormatFIT}
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, cyclinganalyticstest.WithProcessing(1))
+	ctx := context.Background()
+
+	upload, err := client.Rides.Upload(ctx, file("fit data"))
+	a.NoError(err)
+	a.Equal("processing", upload.Status)
+	a.Equal("fit", upload.Format)
+	a.Equal(int64(8), upload.Size)
+	upload, err = client.Rides.Status(ctx, upload.ID)
+	a.NoError(err)
+	a.False(upload.Done())
+	upload, err = client.Rides.StatusWithUser(ctx, 1, upload.ID)
+	a.NoError(err)
+	a.Equal("done", upload.Status)
+	a.Equal("ride", svr.Ride(upload.RideID).Title)
+
+	// the processing errors are reported with their error codes
+	process := func(contents string) *cyclinganalytics.Upload {
+		upload, err = client.Rides.UploadWithUser(ctx, 1, file(contents))
+		a.NoError(err)
+		for range 2 {
+			upload, err = client.Rides.Status(ctx, upload.ID)
+			a.NoError(err)
+		}
+		return upload
+	}
+	a.Equal("empty_file", process("").ErrorCode)
+	a.Equal("duplicate_ride", process("fit data").ErrorCode)
+	a.True(errors.Is(upload.Err(), activity.ErrInvalidFile))
+
+	upload, err = client.Rides.StatusWithUser(ctx, 2, upload.ID)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(upload)
+
+	upload, err = client.Rides.UploadWithUser(ctx, cyclinganalytics.Me, &activity.File{
+		Reader: strings.NewReader("data"), Name: "ride.txt"})
+	a.True(errors.Is(err, activity.ErrInvalidFile))
+	a.Nil(upload)
+}
@@ -0,0 +1,19 @@
+package cyclinganalytics
+
+import (
+	"net/http"
+
+	"github.com/bzimmer/activity"
+)
+
+// do executes the http request and populates v with the result
+//
+// The Fault of a failed request is classified using the error taxonomy of the activity package.
+func (c *Client) do(req *http.Request, v any) error {
+	return activity.Do(c.client, req, v, newStatusError)
+}
+
+// newStatusError classifies the Fault of the failed response
+func newStatusError(res *http.Response) error {
+	return activity.NewResponseError(res, &Fault{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)})
+}
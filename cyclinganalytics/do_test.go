@@ -0,0 +1,51 @@
+package cyclinganalytics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+)
+
+func TestDoErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/me", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		_, err := w.Write([]byte(`{"error": "insufficient scope"}`))
+		a.NoError(err)
+	})
+	mux.HandleFunc("/me/upload", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusUnsupportedMediaType)
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	client, err := cyclinganalytics.NewClient(
+		cyclinganalytics.WithBaseURL(svr.URL),
+		cyclinganalytics.WithTokenCredentials("fooKey", "barToken", time.Time{}))
+	a.NoError(err)
+
+	me, err := client.User.Me(context.Background())
+	a.Nil(me)
+	a.ErrorIs(err, activity.ErrMissingScope)
+	a.EqualError(err, "insufficient scope")
+	var fault *cyclinganalytics.Fault
+	a.ErrorAs(err, &fault)
+	a.Equal(http.StatusForbidden, fault.Code)
+
+	file := &activity.File{Reader: http.NoBody, Name: "ride.fit", Format: activity.FormatFIT}
+	upload, err := client.Rides.Upload(context.Background(), file)
+	a.Nil(upload)
+	a.ErrorIs(err, activity.ErrInvalidFile)
+
+	a.ErrorIs((&cyclinganalytics.Upload{Status: "error", ErrorCode: "parse"}).Err(), activity.ErrInvalidFile)
+}
@@ -5,6 +5,7 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/martinlindhe/unit"
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-gpx"
 
@@ -12,6 +13,7 @@ import (
 )
 
 var _ activity.GPXEncoder = (*Ride)(nil)
+var _ activity.SeriesEncoder = (*Ride)(nil)
 
 func (r *Ride) GPX() (*gpx.GPX, error) {
 	var layout geom.Layout
@@ -65,3 +67,62 @@ func (r *Ride) GPX() (*gpx.GPX, error) {
 		Trk: []*gpx.TrkType{trk},
 	}, nil
 }
+
+// Series representation of a ride
+//
+// Cycling Analytics streams are sampled at 1Hz starting at the ride's UTC datetime.
+func (r *Ride) Series() (*activity.Series, error) {
+	s := r.Streams
+	n := max(len(s.Latitude), len(s.Elevation), len(s.Distance), len(s.Speed),
+		len(s.Heartrate), len(s.Cadence), len(s.Power), len(s.Temperature), len(s.Gradient))
+	if n == 0 {
+		return nil, errors.New("no streams available for series encoding")
+	}
+	x := &activity.Series{
+		StartTime: r.UTCDatetime.Time,
+		Time:      make([]unit.Duration, n),
+		Latitude:  s.Latitude,
+		Longitude: s.Longitude,
+		HeartRate: s.Heartrate,
+		Cadence:   s.Cadence,
+		Grade:     s.Gradient,
+	}
+	for i := range n {
+		x.Time[i] = unit.Duration(i) * unit.Second
+	}
+	if s.Elevation != nil {
+		x.Elevation = make([]unit.Length, len(s.Elevation))
+		for i, e := range s.Elevation {
+			x.Elevation[i] = unit.Length(e) * unit.Meter
+		}
+	}
+	if s.Distance != nil {
+		x.Distance = make([]unit.Length, len(s.Distance))
+		for i, d := range s.Distance {
+			// the distance unit is kilometers
+			x.Distance[i] = unit.Length(d) * unit.Kilometer
+		}
+	}
+	if s.Speed != nil {
+		x.Speed = make([]unit.Speed, len(s.Speed))
+		for i, v := range s.Speed {
+			x.Speed[i] = unit.Speed(v) * unit.MetersPerSecond
+		}
+	}
+	if s.Power != nil {
+		x.Power = make([]unit.Power, len(s.Power))
+		for i, p := range s.Power {
+			x.Power[i] = unit.Power(p) * unit.Watt
+		}
+	}
+	if s.Temperature != nil {
+		x.Temperature = make([]unit.Temperature, len(s.Temperature))
+		for i, t := range s.Temperature {
+			x.Temperature[i] = unit.FromCelsius(t)
+		}
+	}
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
@@ -29,3 +29,36 @@ func TestRideGPX(t *testing.T) {
 	a.Equal(5, len(gpx.Trk[0].TrkSeg[0].TrkPt))
 	a.Equal(0, len(gpx.Rte))
 }
+
+func TestRideSeries(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	data, err := os.ReadFile("testdata/ride.json")
+	a.NoError(err)
+	var ride cyclinganalytics.Ride
+	a.NoError(json.Unmarshal(data, &ride))
+
+	series, err := ride.Series()
+	a.NoError(err)
+	a.NotNil(series)
+	a.Equal(5, series.Len())
+	a.Equal(ride.UTCDatetime.Time, series.StartTime)
+	a.InDelta(4, series.Time[4].Seconds(), 0.001)
+	a.Equal(48.087035, series.Latitude[4])
+	a.InDelta(100.8, series.Elevation[4].Meters(), 0.001)
+	a.Nil(series.Power)
+
+	ride.Streams.Distance = []float64{0, 0.01, 0.02, 0.03, 0.04}
+	series, err = ride.Series()
+	a.NoError(err)
+	a.InDelta(40, series.Distance[4].Meters(), 0.001)
+
+	ride.Streams.Power = []float64{100}
+	series, err = ride.Series()
+	a.Error(err)
+	a.Nil(series)
+
+	series, err = (&cyclinganalytics.Ride{}).Series()
+	a.Error(err)
+	a.Nil(series)
+}
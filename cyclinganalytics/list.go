@@ -0,0 +1,28 @@
+package cyclinganalytics
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+type lister struct {
+	s *RidesService
+}
+
+func newLister(s *RidesService) activity.Lister {
+	return &lister{s: s}
+}
+
+// List returns summaries of the authenticated user's rides
+func (l *lister) List(ctx context.Context, spec activity.Pagination) ([]*activity.Summary, error) {
+	rides, err := l.s.Rides(ctx, Me, spec)
+	if err != nil {
+		return nil, err
+	}
+	summaries := make([]*activity.Summary, len(rides))
+	for i, ride := range rides {
+		summaries[i] = ride.Summarize()
+	}
+	return summaries, nil
+}
@@ -0,0 +1,50 @@
+package cyclinganalytics_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+)
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/me/rides", func(w http.ResponseWriter, r *http.Request) {
+		http.ServeFile(w, r, "testdata/me-rides.json")
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	client, err := cyclinganalytics.NewClient(
+		cyclinganalytics.WithBaseURL(svr.URL),
+		cyclinganalytics.WithTokenCredentials("fooKey", "barToken", time.Time{}))
+	a.NoError(err)
+	summaries, err := client.Lister().List(context.Background(), activity.Pagination{Total: 1})
+	a.NoError(err)
+	a.Len(summaries, 1)
+	a.Equal("cyclinganalytics", summaries[0].Provider)
+}
+
+func TestUploadOutcome(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var upload activity.Outcome = &cyclinganalytics.Upload{Status: "done", RideID: 7711}
+	a.Equal(int64(7711), upload.ActivityIdentifier())
+	a.NoError(upload.Err())
+
+	upload = &cyclinganalytics.Upload{Status: "error", Error: "unknown file format"}
+	a.EqualError(upload.Err(), "unknown file format")
+
+	upload = &cyclinganalytics.Upload{Status: "error", ErrorCode: "parse_error"}
+	a.EqualError(upload.Err(), "upload failed: parse_error")
+}
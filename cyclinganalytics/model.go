@@ -1,6 +1,8 @@
 package cyclinganalytics
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/bzimmer/activity"
@@ -173,6 +175,23 @@ func (u *Upload) Done() bool {
 	return u.Status != "processing"
 }
 
+// ActivityIdentifier is the id of the created ride, 0 until processing completes
+func (u *Upload) ActivityIdentifier() int64 {
+	return u.RideID
+}
+
+// Err is the processing error of a failed upload
+func (u *Upload) Err() error {
+	if u.Status != "error" {
+		return nil
+	}
+	err := errors.New(u.Error)
+	if u.Error == "" {
+		err = fmt.Errorf("upload failed: %s", u.ErrorCode)
+	}
+	return &activity.Error{Kind: activity.ErrInvalidFile, Err: err}
+}
+
 type UploadResult struct {
 	Upload *Upload `json:"upload"`
 	Err    error   `json:"error"`
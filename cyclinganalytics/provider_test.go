@@ -0,0 +1,41 @@
+package cyclinganalytics_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+)
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer access", r.Header.Get("Authorization"))
+		a.NoError(json.NewEncoder(w).Encode(&cyclinganalytics.User{ID: cyclinganalytics.UserID(1590343)}))
+	}))
+	defer svr.Close()
+
+	provider := cyclinganalytics.Provider()
+	a.Equal("cyclinganalytics", provider.Name)
+	a.True(provider.Supports(activity.CapabilityUpload))
+	a.False(provider.Supports(activity.CapabilityExport))
+	client, err := provider.New(context.Background(), activity.ProviderConfig{
+		ClientID:    "client",
+		AccessToken: "access",
+		Expiry:      time.Now().Add(time.Hour),
+		BaseURL:     svr.URL,
+	})
+	a.NoError(err)
+	user, err := client.(*cyclinganalytics.Client).User.Me(context.Background())
+	a.NoError(err)
+	a.Equal(cyclinganalytics.UserID(1590343), user.ID)
+}
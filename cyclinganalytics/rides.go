@@ -141,8 +141,7 @@ func (s *RidesService) UploadWithUser(ctx context.Context, userID UserID, file *
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
 	res := &Upload{}
-	err = s.client.do(req, res)
-	if err != nil {
+	if err = activity.Do(s.client.client, req, res, activity.UploadFault(newStatusError)); err != nil {
 		return nil, err
 	}
 	return res, nil
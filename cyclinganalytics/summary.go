@@ -0,0 +1,31 @@
+package cyclinganalytics
+
+import (
+	"github.com/martinlindhe/unit"
+
+	"github.com/bzimmer/activity"
+)
+
+const provider = "cyclinganalytics"
+
+var _ activity.Summarizer = (*Ride)(nil)
+
+// Summarize returns a provider-neutral summary of the ride
+//
+// Cycling Analytics reports distance in kilometers and supports only rides
+func (r *Ride) Summarize() *activity.Summary {
+	return &activity.Summary{
+		ID:            r.ID,
+		Provider:      provider,
+		Name:          r.Title,
+		Sport:         activity.SportCycling,
+		Trainer:       r.Trainer,
+		StartTime:     r.UTCDatetime.Time,
+		ElapsedTime:   unit.Duration(r.Summary.TotalTime) * unit.Second,
+		MovingTime:    unit.Duration(r.Summary.MovingTime) * unit.Second,
+		Distance:      unit.Length(r.Summary.Distance) * unit.Kilometer,
+		ElevationGain: unit.Length(r.Summary.Climbing) * unit.Meter,
+		AveragePower:  unit.Power(r.Summary.AvgPower) * unit.Watt,
+		MaxPower:      unit.Power(r.Summary.MaxPower) * unit.Watt,
+	}
+}
@@ -0,0 +1,34 @@
+package cyclinganalytics_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/cyclinganalytics"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	data, err := os.ReadFile("testdata/ride.json")
+	a.NoError(err)
+	var ride cyclinganalytics.Ride
+	a.NoError(json.Unmarshal(data, &ride))
+
+	s := ride.Summarize()
+	a.NotNil(s)
+	a.Equal(int64(175334338355), s.ID)
+	a.Equal("cyclinganalytics", s.Provider)
+	a.Equal("98mi around Snohomish County", s.Name)
+	a.Equal(activity.SportCycling, s.Sport)
+	a.False(s.Trainer)
+	a.Equal(ride.UTCDatetime.Time, s.StartTime)
+	a.InDelta(157036, s.Distance.Meters(), 0.01)
+	a.InDelta(27154, s.ElapsedTime.Seconds(), 0.01)
+	a.InDelta(23095, s.MovingTime.Seconds(), 0.01)
+	a.InDelta(1256, s.ElevationGain.Meters(), 0.01)
+}
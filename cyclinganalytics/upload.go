@@ -12,6 +12,8 @@ import (
 // More information can be found at:
 //  https://www.cyclinganalytics.com/developer/api#/user/user_id/upload/upload_id
 
+var _ activity.Outcome = (*Upload)(nil)
+
 type uploader struct {
 	s *RidesService
 }
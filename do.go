@@ -0,0 +1,46 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// Do executes the http request and decodes the JSON body of a successful response into v if not nil
+//
+// The error of a failed response is created by fault, typically using NewResponseError.
+func Do(client *http.Client, req *http.Request, v any, fault func(*http.Response) error) error {
+	ctx := req.Context()
+	res, err := client.Do(req)
+	if err != nil {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+			return err
+		}
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return fault(res)
+	}
+	if v == nil {
+		return nil
+	}
+	err = json.NewDecoder(res.Body).Decode(v)
+	if errors.Is(err, io.EOF) {
+		// ignore EOF errors caused by empty response body
+		return nil
+	}
+	return err
+}
+
+// UploadFault returns the fault of an upload request which classifies a file rejected by the
+// provider as invalid
+func UploadFault(fault func(*http.Response) error) func(*http.Response) error {
+	return func(res *http.Response) error {
+		return NewInvalidFileError(res.StatusCode, fault(res))
+	}
+}
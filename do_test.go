@@ -0,0 +1,85 @@
+package activity_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+type doFault struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (f *doFault) Error() string {
+	return f.Message
+}
+
+func newDoFault(res *http.Response) error {
+	return activity.NewResponseError(res, &doFault{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)})
+}
+
+func TestDo(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ok", func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{"name":"ride"}`))
+	})
+	mux.HandleFunc("/empty", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"record not found"}`))
+	})
+	mux.HandleFunc("/invalid", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		_, _ = w.Write([]byte(`not json`))
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	do := func(ctx context.Context, path string, v any, fault func(*http.Response) error) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, svr.URL+path, nil)
+		if err != nil {
+			return err
+		}
+		return activity.Do(svr.Client(), req, v, fault)
+	}
+	ctx := context.Background()
+
+	var v struct{ Name string }
+	a.NoError(do(ctx, "/ok", &v, newDoFault))
+	a.Equal("ride", v.Name)
+	a.NoError(do(ctx, "/ok", nil, newDoFault))
+	a.NoError(do(ctx, "/empty", &v, newDoFault))
+
+	var fault *doFault
+	err := do(ctx, "/missing", &v, newDoFault)
+	a.ErrorIs(err, activity.ErrNotFound)
+	a.ErrorAs(err, &fault)
+	a.Equal(http.StatusNotFound, fault.Code)
+	a.Equal("record not found", fault.Message)
+
+	// a body which is not JSON is described by the status of the response
+	err = do(ctx, "/invalid", &v, newDoFault)
+	a.NotErrorIs(err, activity.ErrInvalidFile)
+	a.ErrorAs(err, &fault)
+	a.Equal(http.StatusText(http.StatusBadRequest), fault.Message)
+
+	err = do(ctx, "/invalid", &v, activity.UploadFault(newDoFault))
+	a.ErrorIs(err, activity.ErrInvalidFile)
+	err = do(ctx, "/missing", &v, activity.UploadFault(newDoFault))
+	a.ErrorIs(err, activity.ErrNotFound)
+
+	ctx, cancel := context.WithCancel(ctx)
+	cancel()
+	a.ErrorIs(do(ctx, "/ok", &v, newDoFault), context.Canceled)
+}
@@ -0,0 +1,146 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// The error taxonomy common to all providers
+//
+// Provider errors are classified by wrapping them in an Error, RateLimitError, or DuplicateError
+// so the kind can be tested with errors.Is while the provider error remains available to errors.As.
+var (
+	// ErrNotFound is returned when the requested resource does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrUnauthorized is returned when the credentials are missing, invalid, or expired
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrMissingScope is returned when the credentials are valid but lack the permission for the request
+	ErrMissingScope = errors.New("missing scope")
+	// ErrRateLimited is returned when the rate limit of the provider is exceeded
+	ErrRateLimited = errors.New("rate limited")
+	// ErrDuplicate is returned when an uploaded file duplicates an existing activity
+	ErrDuplicate = errors.New("duplicate upload")
+	// ErrInvalidFile is returned when an uploaded file is malformed or unsupported
+	ErrInvalidFile = errors.New("invalid file")
+	// ErrServer is returned when the provider fails to process a valid request
+	ErrServer = errors.New("server error")
+)
+
+// Error classifies a provider error by kind
+type Error struct {
+	// Kind is the sentinel error of the taxonomy
+	Kind error
+	// StatusCode of the response or 0 if the error did not originate from a response
+	StatusCode int
+	// Err is the provider error
+	Err error
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error()
+}
+
+func (e *Error) Unwrap() []error {
+	return []error{e.Kind, e.Err}
+}
+
+// RateLimitError is returned when the rate limit of the provider is exceeded
+type RateLimitError struct {
+	// Reset is the time after which requests will be accepted or zero if unknown
+	Reset time.Time
+	// Err is the provider error
+	Err error
+}
+
+func (e *RateLimitError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *RateLimitError) Is(target error) bool {
+	return target == ErrRateLimited
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return e.Err
+}
+
+// DuplicateError is returned when an uploaded file duplicates an existing activity
+type DuplicateError struct {
+	// ActivityID of the existing activity, 0 if unknown
+	ActivityID int64
+	// Message from the provider
+	Message string
+}
+
+func (e *DuplicateError) Error() string {
+	return e.Message
+}
+
+func (e *DuplicateError) Is(target error) bool {
+	return target == ErrDuplicate
+}
+
+// NewStatusError classifies the error of a failed request by the status of the response
+//
+// A forbidden response is classified as a missing scope since the credentials were accepted
+// but lack the permission for the request. If the status is not classified the error is
+// returned unchanged.
+func NewStatusError(res *http.Response, err error) error {
+	code := res.StatusCode
+	switch {
+	case code == http.StatusTooManyRequests:
+		return &RateLimitError{Reset: RetryAfter(res.Header, time.Now()), Err: err}
+	case code == http.StatusUnauthorized:
+		return &Error{Kind: ErrUnauthorized, StatusCode: code, Err: err}
+	case code == http.StatusForbidden:
+		return &Error{Kind: ErrMissingScope, StatusCode: code, Err: err}
+	case code == http.StatusNotFound:
+		return &Error{Kind: ErrNotFound, StatusCode: code, Err: err}
+	case code >= http.StatusInternalServerError:
+		return &Error{Kind: ErrServer, StatusCode: code, Err: err}
+	default:
+		return err
+	}
+}
+
+// NewResponseError classifies the fault of a failed response by the status of the response
+//
+// The JSON body of the response is decoded into the fault, which should be populated with the
+// status of the response beforehand so it describes the failure even if the body is not JSON.
+func NewResponseError(res *http.Response, fault error) error {
+	// error responses are not always JSON so a decoding failure is ignored
+	_ = json.NewDecoder(res.Body).Decode(fault)
+	return NewStatusError(res, fault)
+}
+
+// NewInvalidFileError classifies the error of a failed upload as an invalid file if the status
+// of the response indicates the file was rejected
+func NewInvalidFileError(code int, err error) error {
+	switch code {
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge,
+		http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity:
+		return &Error{Kind: ErrInvalidFile, StatusCode: code, Err: err}
+	default:
+		return err
+	}
+}
+
+// RetryAfter returns the time specified by the Retry-After header or zero if not specified
+//
+// The header is either a number of seconds or an HTTP date.
+func RetryAfter(header http.Header, now time.Time) time.Time {
+	value := header.Get("Retry-After")
+	if value == "" {
+		return time.Time{}
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		return now.Add(time.Duration(seconds) * time.Second)
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return t
+	}
+	return time.Time{}
+}
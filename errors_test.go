@@ -0,0 +1,113 @@
+package activity_test
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+type fault struct {
+	message string
+}
+
+func (f *fault) Error() string {
+	return f.message
+}
+
+func TestNewStatusError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name string
+		code int
+		kind error
+	}{
+		{name: "not found", code: http.StatusNotFound, kind: activity.ErrNotFound},
+		{name: "unauthorized", code: http.StatusUnauthorized, kind: activity.ErrUnauthorized},
+		{name: "forbidden", code: http.StatusForbidden, kind: activity.ErrMissingScope},
+		{name: "rate limited", code: http.StatusTooManyRequests, kind: activity.ErrRateLimited},
+		{name: "server", code: http.StatusBadGateway, kind: activity.ErrServer},
+		{name: "bad request", code: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f := &fault{message: "provider error"}
+			err := activity.NewStatusError(&http.Response{StatusCode: tt.code, Header: make(http.Header)}, f)
+			a.Equal("provider error", err.Error())
+			var x *fault
+			a.ErrorAs(err, &x)
+			a.Same(f, x)
+			if tt.kind == nil {
+				a.Same(f, err)
+				return
+			}
+			a.ErrorIs(err, tt.kind)
+			for _, kind := range []error{activity.ErrNotFound, activity.ErrUnauthorized, activity.ErrMissingScope,
+				activity.ErrRateLimited, activity.ErrServer, activity.ErrDuplicate, activity.ErrInvalidFile} {
+				if kind != tt.kind {
+					a.NotErrorIs(err, kind)
+				}
+			}
+		})
+	}
+}
+
+func TestRateLimitError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	header := make(http.Header)
+	header.Set("Retry-After", "120")
+	before := time.Now()
+	err := activity.NewStatusError(&http.Response{StatusCode: http.StatusTooManyRequests, Header: header},
+		errors.New("too many requests"))
+	var rle *activity.RateLimitError
+	a.ErrorAs(err, &rle)
+	a.WithinDuration(before.Add(2*time.Minute), rle.Reset, time.Second)
+	a.EqualError(err, "too many requests")
+}
+
+func TestRetryAfter(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	now := time.Date(2026, time.March, 1, 12, 0, 0, 0, time.UTC)
+	header := make(http.Header)
+	a.True(activity.RetryAfter(header, now).IsZero())
+	header.Set("Retry-After", "30")
+	a.Equal(now.Add(30*time.Second), activity.RetryAfter(header, now))
+	header.Set("Retry-After", "Sun, 01 Mar 2026 12:05:00 GMT")
+	a.Equal(now.Add(5*time.Minute), activity.RetryAfter(header, now))
+	header.Set("Retry-After", "soon")
+	a.True(activity.RetryAfter(header, now).IsZero())
+}
+
+func TestInvalidFileError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	f := &fault{message: "malformed"}
+	err := activity.NewInvalidFileError(http.StatusUnprocessableEntity, f)
+	a.ErrorIs(err, activity.ErrInvalidFile)
+	var x *activity.Error
+	a.ErrorAs(err, &x)
+	a.Equal(http.StatusUnprocessableEntity, x.StatusCode)
+	a.Same(f, activity.NewInvalidFileError(http.StatusNotFound, f))
+}
+
+func TestDuplicateError(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	err := error(&activity.DuplicateError{ActivityID: 10, Message: "duplicate of 10"})
+	a.ErrorIs(err, activity.ErrDuplicate)
+	a.NotErrorIs(err, activity.ErrInvalidFile)
+	a.EqualError(err, "duplicate of 10")
+}
@@ -0,0 +1,279 @@
+package activity
+
+import (
+	"cmp"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+// fingerprintSamples is the maximum number of coordinates sampled for the track of a fingerprint
+const fingerprintSamples = 64
+
+// LatLng is a coordinate in degrees
+type LatLng struct {
+	Lat float64 `json:"lat"`
+	Lng float64 `json:"lng"`
+}
+
+// Locator is optionally implemented by an activity to report its start and end coordinates
+type Locator interface {
+	// Endpoints returns the first and last coordinates of the activity or nil if unknown
+	Endpoints() (*LatLng, *LatLng)
+}
+
+// Fingerprint is the provider-neutral identity of an activity used to match copies of the same effort
+type Fingerprint struct {
+	Provider  string        `json:"provider"`
+	ID        int64         `json:"id"`
+	StartTime time.Time     `json:"start_time"`
+	Duration  unit.Duration `json:"duration" units:"s"`
+	Distance  unit.Length   `json:"distance" units:"m"`
+	// Start is the first coordinate, nil if unknown
+	Start *LatLng `json:"start,omitempty"`
+	// End is the last coordinate, nil if unknown
+	End *LatLng `json:"end,omitempty"`
+	// Track is a sample of the coordinates in order, empty if unknown
+	Track []LatLng `json:"track,omitempty"`
+}
+
+// NewFingerprint returns the fingerprint of the activity
+//
+// The duration is the elapsed time, or moving time if no elapsed time is available. If the
+// activity implements Locator its endpoints are used and if it implements SeriesEncoder the
+// coordinates of the series, if available, are sampled for the track.
+func NewFingerprint(src Summarizer) *Fingerprint {
+	s := src.Summarize()
+	fp := &Fingerprint{
+		Provider:  s.Provider,
+		ID:        s.ID,
+		StartTime: s.StartTime,
+		Duration:  s.ElapsedTime,
+		Distance:  s.Distance,
+	}
+	if fp.Duration <= 0 {
+		fp.Duration = s.MovingTime
+	}
+	if x, ok := src.(Locator); ok {
+		fp.Start, fp.End = x.Endpoints()
+	}
+	if x, ok := src.(SeriesEncoder); ok {
+		// the series is optional, for example activities queried without streams
+		if series, err := x.Series(); err == nil {
+			fp.Track = sample(series)
+		}
+	}
+	if n := len(fp.Track); n > 0 {
+		if fp.Start == nil {
+			fp.Start = &fp.Track[0]
+		}
+		if fp.End == nil {
+			fp.End = &fp.Track[n-1]
+		}
+	}
+	return fp
+}
+
+// sample returns up to fingerprintSamples evenly spaced coordinates of the series including the last
+func sample(series *Series) []LatLng {
+	var coords []LatLng
+	for i := range series.Len() {
+		if series.HasLatLng(i) {
+			coords = append(coords, LatLng{Lat: series.Latitude[i], Lng: series.Longitude[i]})
+		}
+	}
+	n := len(coords)
+	if n <= fingerprintSamples {
+		return coords
+	}
+	track := make([]LatLng, 0, fingerprintSamples)
+	for i := range fingerprintSamples {
+		track = append(track, coords[i*(n-1)/(fingerprintSamples-1)])
+	}
+	return track
+}
+
+// A MatchOption allows configuring a Matcher
+type MatchOption func(m *Matcher)
+
+// WithMatchStart sets the maximum difference of the start times
+func WithMatchStart(tolerance time.Duration) MatchOption {
+	return func(m *Matcher) {
+		m.start = tolerance
+	}
+}
+
+// WithMatchDuration sets the maximum relative difference of the durations
+func WithMatchDuration(tolerance float64) MatchOption {
+	return func(m *Matcher) {
+		m.duration = tolerance
+	}
+}
+
+// WithMatchDistance sets the maximum relative difference of the distances
+func WithMatchDistance(tolerance float64) MatchOption {
+	return func(m *Matcher) {
+		m.distance = tolerance
+	}
+}
+
+// WithMatchEndpoints sets the maximum distance between the start and between the end coordinates
+func WithMatchEndpoints(tolerance unit.Length) MatchOption {
+	return func(m *Matcher) {
+		m.endpoints = tolerance
+	}
+}
+
+// WithMatchTrack enables comparing the tracks
+//
+// Tracks match if at least the `similarity` fraction of the coordinates of each track are
+// within `tolerance` of the other track.
+func WithMatchTrack(tolerance unit.Length, similarity float64) MatchOption {
+	return func(m *Matcher) {
+		m.track = tolerance
+		m.similarity = similarity
+	}
+}
+
+// Matcher matches activities representing the same effort
+//
+// Activities match if the start times, durations, and distances are within tolerance. The
+// endpoints and, if enabled, the tracks are compared only if available for both activities
+// so activities without coordinates, such as trainer rides, match on time and distance alone.
+type Matcher struct {
+	start      time.Duration
+	duration   float64
+	distance   float64
+	endpoints  unit.Length
+	track      unit.Length
+	similarity float64
+}
+
+// NewMatcher returns a Matcher
+//
+// By default start times match within two minutes, durations and distances within 10% and
+// endpoints within 250 meters. Tracks are not compared.
+func NewMatcher(opts ...MatchOption) *Matcher {
+	m := &Matcher{
+		start:     2 * time.Minute,
+		duration:  0.1,
+		distance:  0.1,
+		endpoints: 250 * unit.Meter,
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Match returns true if the fingerprints represent the same effort
+func (m *Matcher) Match(a, b *Fingerprint) bool {
+	if a.StartTime.IsZero() || b.StartTime.IsZero() {
+		return false
+	}
+	if d := a.StartTime.Sub(b.StartTime); d > m.start || d < -m.start {
+		return false
+	}
+	if !within(a.Duration.Seconds(), b.Duration.Seconds(), m.duration) ||
+		!within(a.Distance.Meters(), b.Distance.Meters(), m.distance) {
+		return false
+	}
+	if !near(a.Start, b.Start, m.endpoints) || !near(a.End, b.End, m.endpoints) {
+		return false
+	}
+	if m.track > 0 && len(a.Track) > 0 && len(b.Track) > 0 {
+		return similarity(a.Track, b.Track, m.track) >= m.similarity &&
+			similarity(b.Track, a.Track, m.track) >= m.similarity
+	}
+	return true
+}
+
+// Find returns the first candidate matching the fingerprint or nil if none match
+func (m *Matcher) Find(fp *Fingerprint, candidates []*Fingerprint) *Fingerprint {
+	for _, c := range candidates {
+		if m.Match(fp, c) {
+			return c
+		}
+	}
+	return nil
+}
+
+// Link groups the fingerprints of different providers representing the same effort
+//
+// Only groups of two or more activities are returned, each ordered by provider and the groups by
+// start time. Activities of the same provider are linked only through a matching activity of
+// another provider.
+func (m *Matcher) Link(fps []*Fingerprint) [][]*Fingerprint {
+	sorted := slices.Clone(fps)
+	slices.SortFunc(sorted, func(a, b *Fingerprint) int { return a.StartTime.Compare(b.StartTime) })
+	parent := make([]int, len(sorted))
+	for i := range parent {
+		parent[i] = i
+	}
+	root := func(i int) int {
+		for parent[i] != i {
+			parent[i] = parent[parent[i]]
+			i = parent[i]
+		}
+		return i
+	}
+	for i, a := range sorted {
+		// the fingerprints are ordered by start time so only those starting within tolerance are compared
+		for j := i + 1; j < len(sorted) && sorted[j].StartTime.Sub(a.StartTime) <= m.start; j++ {
+			if b := sorted[j]; a.Provider != b.Provider && m.Match(a, b) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+	groups := make(map[int][]*Fingerprint)
+	var roots []int
+	for i, fp := range sorted {
+		r := root(i)
+		if _, ok := groups[r]; !ok {
+			roots = append(roots, r)
+		}
+		groups[r] = append(groups[r], fp)
+	}
+	var links [][]*Fingerprint
+	for _, r := range roots {
+		if group := groups[r]; len(group) > 1 {
+			slices.SortStableFunc(group, func(a, b *Fingerprint) int { return cmp.Compare(a.Provider, b.Provider) })
+			links = append(links, group)
+		}
+	}
+	return links
+}
+
+// within returns true if the values differ by at most the tolerance relative to the larger value
+//
+// A zero value is unknown and matches any value.
+func within(a, b, tolerance float64) bool {
+	if a <= 0 || b <= 0 {
+		return true
+	}
+	return math.Abs(a-b) <= tolerance*math.Max(a, b)
+}
+
+// near returns true if the coordinates are within the tolerance, unknown coordinates match any coordinate
+func near(a, b *LatLng, tolerance unit.Length) bool {
+	if a == nil || b == nil {
+		return true
+	}
+	return Haversine(a.Lat, a.Lng, b.Lat, b.Lng) <= tolerance
+}
+
+// similarity returns the fraction of the coordinates of `a` within the tolerance of a coordinate of `b`
+func similarity(a, b []LatLng, tolerance unit.Length) float64 {
+	var n int
+	for _, x := range a {
+		for _, y := range b {
+			if Haversine(x.Lat, x.Lng, y.Lat, y.Lng) <= tolerance {
+				n++
+				break
+			}
+		}
+	}
+	return float64(n) / float64(len(a))
+}
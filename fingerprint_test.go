@@ -0,0 +1,236 @@
+package activity_test
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+// effort is an activity with optional endpoints and series
+type effort struct {
+	summary    *activity.Summary
+	start, end *activity.LatLng
+	series     *activity.Series
+}
+
+func (e *effort) Summarize() *activity.Summary {
+	return e.summary
+}
+
+func (e *effort) Endpoints() (*activity.LatLng, *activity.LatLng) {
+	return e.start, e.end
+}
+
+func (e *effort) Series() (*activity.Series, error) {
+	if e.series == nil {
+		return nil, errors.New("no series")
+	}
+	return e.series, nil
+}
+
+// line returns a series heading north from the coordinate in n one second samples of ~11 meters
+func line(lat, lng float64, n int) *activity.Series {
+	s := &activity.Series{
+		Time:      make([]unit.Duration, n),
+		Latitude:  make([]float64, n),
+		Longitude: make([]float64, n),
+	}
+	for i := range n {
+		s.Time[i] = unit.Duration(i) * unit.Second
+		s.Latitude[i], s.Longitude[i] = lat+float64(i)*0.0001, lng
+	}
+	return s
+}
+
+func TestFingerprint(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	series := line(47.6, -122.3, 200)
+	series.Latitude[0], series.Longitude[0] = math.NaN(), math.NaN()
+	fp := activity.NewFingerprint(&effort{
+		summary: &activity.Summary{
+			Provider: "strava", ID: 1, StartTime: start, MovingTime: 3 * unit.Minute, Distance: 2 * unit.Kilometer},
+		end:    &activity.LatLng{Lat: 47.7, Lng: -122.3},
+		series: series,
+	})
+	a.Equal("strava", fp.Provider)
+	a.Equal(int64(1), fp.ID)
+	a.Equal(start, fp.StartTime)
+	a.InDelta(180, fp.Duration.Seconds(), 0.001)
+	a.Len(fp.Track, 64)
+	a.Equal(activity.LatLng{Lat: series.Latitude[1], Lng: -122.3}, fp.Track[0])
+	a.Equal(activity.LatLng{Lat: series.Latitude[199], Lng: -122.3}, fp.Track[63])
+	// the start is taken from the track and the end from the endpoints
+	a.Equal(&fp.Track[0], fp.Start)
+	a.Equal(&activity.LatLng{Lat: 47.7, Lng: -122.3}, fp.End)
+
+	fp = activity.NewFingerprint(&effort{summary: &activity.Summary{ElapsedTime: unit.Hour, MovingTime: unit.Minute}})
+	a.InDelta(3600, fp.Duration.Seconds(), 0.001)
+	a.Nil(fp.Start)
+	a.Nil(fp.End)
+	a.Empty(fp.Track)
+}
+
+func TestMatcher(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	base := func() *activity.Fingerprint {
+		return &activity.Fingerprint{
+			Provider:  "strava",
+			ID:        1,
+			StartTime: start,
+			Duration:  unit.Hour,
+			Distance:  30 * unit.Kilometer,
+			Start:     &activity.LatLng{Lat: 47.6, Lng: -122.3},
+			End:       &activity.LatLng{Lat: 47.6, Lng: -122.3},
+		}
+	}
+	tests := []struct {
+		name    string
+		opts    []activity.MatchOption
+		modify  func(fp *activity.Fingerprint)
+		matched bool
+	}{
+		{name: "identical", modify: func(*activity.Fingerprint) {}, matched: true},
+		{
+			name: "within tolerance",
+			modify: func(fp *activity.Fingerprint) {
+				fp.StartTime = start.Add(time.Minute)
+				fp.Distance = 28 * unit.Kilometer
+			},
+			matched: true,
+		},
+		{
+			name:   "late start",
+			modify: func(fp *activity.Fingerprint) { fp.StartTime = start.Add(5 * time.Minute) },
+		},
+		{
+			name:    "late start with tolerance",
+			opts:    []activity.MatchOption{activity.WithMatchStart(10 * time.Minute)},
+			modify:  func(fp *activity.Fingerprint) { fp.StartTime = start.Add(5 * time.Minute) },
+			matched: true,
+		},
+		{
+			name:   "no start time",
+			modify: func(fp *activity.Fingerprint) { fp.StartTime = time.Time{} },
+		},
+		{
+			name:   "short",
+			modify: func(fp *activity.Fingerprint) { fp.Duration = 30 * unit.Minute },
+		},
+		{
+			name:    "short with tolerance",
+			opts:    []activity.MatchOption{activity.WithMatchDuration(0.5)},
+			modify:  func(fp *activity.Fingerprint) { fp.Duration = 30 * unit.Minute },
+			matched: true,
+		},
+		{
+			name:   "far",
+			modify: func(fp *activity.Fingerprint) { fp.Distance = 20 * unit.Kilometer },
+		},
+		{
+			name:    "far with tolerance",
+			opts:    []activity.MatchOption{activity.WithMatchDistance(0.5)},
+			modify:  func(fp *activity.Fingerprint) { fp.Distance = 20 * unit.Kilometer },
+			matched: true,
+		},
+		{
+			name:   "different start",
+			modify: func(fp *activity.Fingerprint) { fp.Start = &activity.LatLng{Lat: 47.61, Lng: -122.3} },
+		},
+		{
+			name:    "different start with tolerance",
+			opts:    []activity.MatchOption{activity.WithMatchEndpoints(2 * unit.Kilometer)},
+			modify:  func(fp *activity.Fingerprint) { fp.Start = &activity.LatLng{Lat: 47.61, Lng: -122.3} },
+			matched: true,
+		},
+		{
+			name:    "trainer",
+			modify:  func(fp *activity.Fingerprint) { fp.Start, fp.End = nil, nil },
+			matched: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fp := base()
+			fp.Provider = "rwgps"
+			tt.modify(fp)
+			m := activity.NewMatcher(tt.opts...)
+			a.Equal(tt.matched, m.Match(base(), fp))
+			a.Equal(tt.matched, m.Match(fp, base()))
+		})
+	}
+}
+
+func TestMatcherTrack(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	fingerprint := func(provider string, lng float64) *activity.Fingerprint {
+		series := line(47.6, lng, 100)
+		return activity.NewFingerprint(&effort{
+			summary: &activity.Summary{Provider: provider, StartTime: start},
+			series:  series,
+		})
+	}
+	strava := fingerprint("strava", -122.3)
+	// a parallel track ~150 meters east with the same start and end
+	rwgps := fingerprint("rwgps", -122.298)
+	rwgps.Start, rwgps.End = strava.Start, strava.End
+
+	a.True(activity.NewMatcher().Match(strava, rwgps))
+	m := activity.NewMatcher(activity.WithMatchTrack(50*unit.Meter, 0.9))
+	a.False(m.Match(strava, rwgps))
+	a.True(m.Match(strava, fingerprint("zwift", -122.3)))
+	m = activity.NewMatcher(activity.WithMatchTrack(200*unit.Meter, 0.9))
+	a.True(m.Match(strava, rwgps))
+}
+
+func TestMatcherLink(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	fp := func(provider string, id int64, offset time.Duration) *activity.Fingerprint {
+		return &activity.Fingerprint{
+			Provider:  provider,
+			ID:        id,
+			StartTime: start.Add(offset),
+			Duration:  unit.Hour,
+			Distance:  30 * unit.Kilometer,
+		}
+	}
+	fps := []*activity.Fingerprint{
+		fp("zwift", 1, 24*time.Hour),
+		fp("strava", 2, 0),
+		fp("rwgps", 3, time.Minute),
+		fp("cyclinganalytics", 4, 90*time.Second),
+		fp("strava", 5, 24*time.Hour+30*time.Second),
+		fp("strava", 6, 12*time.Hour),
+		fp("strava", 7, 12*time.Hour+time.Second),
+	}
+
+	m := activity.NewMatcher()
+	links := m.Link(fps)
+	a.Len(links, 2)
+	a.Len(links[0], 3)
+	a.Equal([]string{"cyclinganalytics", "rwgps", "strava"},
+		[]string{links[0][0].Provider, links[0][1].Provider, links[0][2].Provider})
+	a.Equal(int64(5), links[1][0].ID)
+	a.Equal(int64(1), links[1][1].ID)
+
+	a.Equal(fps[2], m.Find(fps[1], fps[2:]))
+	a.Nil(m.Find(fps[1], fps[4:]))
+}
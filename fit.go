@@ -0,0 +1,370 @@
+package activity
+
+import (
+	"math"
+	"slices"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+// More information about the FIT protocol can be found at:
+//   https://developer.garmin.com/fit/protocol/
+
+const (
+	fitHeaderSize       = 14
+	fitHeaderSizeLegacy = 12
+	fitProtocolVersion  = 0x20
+	fitProfileVersion   = 2132
+	fitSemicircles      = 180.0 / (1 << 31)
+	// fitEpoch is the unix time of the FIT epoch (1989-12-31T00:00:00Z), all timestamps are seconds since this time
+	fitEpoch = 631065600
+)
+
+// global message numbers
+const (
+	fitMesgFileID           uint16 = 0
+	fitMesgSession          uint16 = 18
+	fitMesgLap              uint16 = 19
+	fitMesgRecord           uint16 = 20
+	fitMesgEvent            uint16 = 21
+	fitMesgCourse           uint16 = 31
+	fitMesgCoursePoint      uint16 = 32
+	fitMesgActivity         uint16 = 34
+	fitMesgFieldDescription uint16 = 206
+)
+
+// field numbers common to all messages
+const (
+	fitFieldTimestamp    uint8 = 253
+	fitFieldMessageIndex uint8 = 254
+)
+
+// base types
+const (
+	fitEnum    byte = 0x00
+	fitSint8   byte = 0x01
+	fitUint8   byte = 0x02
+	fitSint16  byte = 0x83
+	fitUint16  byte = 0x84
+	fitSint32  byte = 0x85
+	fitUint32  byte = 0x86
+	fitString  byte = 0x07
+	fitFloat32 byte = 0x88
+	fitFloat64 byte = 0x89
+	fitUint8z  byte = 0x0A
+	fitUint16z byte = 0x8B
+	fitUint32z byte = 0x8C
+	fitByte    byte = 0x0D
+	fitSint64  byte = 0x8E
+	fitUint64  byte = 0x8F
+	fitUint64z byte = 0x90
+)
+
+// file type enums
+const (
+	fitFileActivity uint8 = 4
+	fitFileCourse   uint8 = 6
+)
+
+// event and event type enums
+const (
+	fitEventTimer       uint8 = 0
+	fitEventSession     uint8 = 8
+	fitEventLap         uint8 = 9
+	fitEventActivity    uint8 = 26
+	fitEventTypeStart   uint8 = 0
+	fitEventTypeStop    uint8 = 1
+	fitEventTypeStopAll uint8 = 4
+)
+
+// sport and sub sport enums
+const (
+	fitSportGeneric    uint8 = 0
+	fitSportRunning    uint8 = 1
+	fitSportCycling    uint8 = 2
+	fitSportSwimming   uint8 = 5
+	fitSportWalking    uint8 = 11
+	fitSportHiking     uint8 = 17
+	fitSubSportIndoor  uint8 = 6
+	fitSubSportVirtual uint8 = 58
+)
+
+// manufacturer enums
+const (
+	fitManufacturerDevelopment uint16 = 255
+	fitManufacturerZwift       uint16 = 260
+)
+
+// FITHeader is the header of a FIT file
+type FITHeader struct {
+	Size            uint8  `json:"size"`
+	ProtocolVersion uint8  `json:"protocol_version"`
+	ProfileVersion  uint16 `json:"profile_version"`
+	DataSize        uint32 `json:"data_size"`
+}
+
+// FITFileID identifies the FIT file
+type FITFileID struct {
+	Type         uint8     `json:"type"`
+	Manufacturer uint16    `json:"manufacturer"`
+	Product      uint16    `json:"product"`
+	SerialNumber uint32    `json:"serial_number"`
+	TimeCreated  time.Time `json:"time_created"`
+}
+
+// FITDeveloperField describes a developer data field
+type FITDeveloperField struct {
+	DeveloperDataIndex uint8   `json:"developer_data_index"`
+	FieldNumber        uint8   `json:"field_number"`
+	BaseType           byte    `json:"base_type"`
+	Name               string  `json:"name"`
+	Units              string  `json:"units"`
+	Scale              float64 `json:"scale"`
+	Offset             float64 `json:"offset"`
+}
+
+// FITSession summarizes a session
+//
+// Numeric values not present in the file are NaN.
+type FITSession struct {
+	Timestamp        time.Time      `json:"timestamp"`
+	StartTime        time.Time      `json:"start_time"`
+	StartLatitude    float64        `json:"start_latitude"`
+	StartLongitude   float64        `json:"start_longitude"`
+	Sport            uint8          `json:"sport"`
+	SubSport         uint8          `json:"sub_sport"`
+	TotalElapsedTime unit.Duration  `json:"total_elapsed_time" units:"s"`
+	TotalTimerTime   unit.Duration  `json:"total_timer_time" units:"s"`
+	TotalDistance    unit.Length    `json:"total_distance" units:"m"`
+	TotalAscent      unit.Length    `json:"total_ascent" units:"m"`
+	TotalDescent     unit.Length    `json:"total_descent" units:"m"`
+	TotalCalories    float64        `json:"total_calories" units:"kcal"`
+	AvgSpeed         unit.Speed     `json:"avg_speed" units:"mps"`
+	MaxSpeed         unit.Speed     `json:"max_speed" units:"mps"`
+	AvgHeartRate     float64        `json:"avg_heart_rate" units:"bpm"`
+	MaxHeartRate     float64        `json:"max_heart_rate" units:"bpm"`
+	AvgCadence       float64        `json:"avg_cadence" units:"rpm"`
+	MaxCadence       float64        `json:"max_cadence" units:"rpm"`
+	AvgPower         unit.Power     `json:"avg_power" units:"W"`
+	MaxPower         unit.Power     `json:"max_power" units:"W"`
+	Developer        map[string]any `json:"developer,omitempty"`
+}
+
+// FITLap summarizes a lap
+//
+// Numeric values not present in the file are NaN.
+type FITLap struct {
+	FITSession
+	EndLatitude  float64 `json:"end_latitude"`
+	EndLongitude float64 `json:"end_longitude"`
+}
+
+// FITRecord is a single sample of activity data
+//
+// Numeric values not present in the file are NaN.
+type FITRecord struct {
+	Timestamp   time.Time        `json:"timestamp"`
+	Latitude    float64          `json:"latitude"`
+	Longitude   float64          `json:"longitude"`
+	Altitude    unit.Length      `json:"altitude" units:"m"`
+	Distance    unit.Length      `json:"distance" units:"m"`
+	Speed       unit.Speed       `json:"speed" units:"mps"`
+	HeartRate   float64          `json:"heart_rate" units:"bpm"`
+	Cadence     float64          `json:"cadence" units:"rpm"`
+	Power       unit.Power       `json:"power" units:"W"`
+	Temperature unit.Temperature `json:"temperature" units:"K"`
+	Grade       float64          `json:"grade" units:"%"`
+	Developer   map[string]any   `json:"developer,omitempty"`
+}
+
+// FITEvent is a timer, lap, or other event
+type FITEvent struct {
+	Timestamp time.Time `json:"timestamp"`
+	Event     uint8     `json:"event"`
+	EventType uint8     `json:"event_type"`
+	Data      uint32    `json:"data"`
+}
+
+// FIT is the decoded content of a FIT activity file
+type FIT struct {
+	Header          FITHeader            `json:"header"`
+	FileID          FITFileID            `json:"file_id"`
+	Sessions        []*FITSession        `json:"sessions,omitempty"`
+	Laps            []*FITLap            `json:"laps,omitempty"`
+	Records         []*FITRecord         `json:"records,omitempty"`
+	Events          []*FITEvent          `json:"events,omitempty"`
+	DeveloperFields []*FITDeveloperField `json:"developer_fields,omitempty"`
+}
+
+var _ Summarizer = (*FIT)(nil)
+var _ SeriesEncoder = (*FIT)(nil)
+
+// Summarize returns a provider-neutral summary of the first session
+//
+// If the file has no sessions the summary is derived from the records with a timestamp.
+func (f *FIT) Summarize() *Summary {
+	s := &Summary{Trainer: f.FileID.Manufacturer == fitManufacturerZwift}
+	if len(f.Sessions) > 0 {
+		x := f.Sessions[0]
+		s.Sport = fitToSport(x.Sport)
+		s.Trainer = s.Trainer || x.SubSport == fitSubSportIndoor || x.SubSport == fitSubSportVirtual
+		s.StartTime = x.StartTime
+		s.ElapsedTime = unit.Duration(nan(float64(x.TotalElapsedTime)))
+		s.MovingTime = unit.Duration(nan(float64(x.TotalTimerTime)))
+		s.Distance = unit.Length(nan(float64(x.TotalDistance)))
+		s.ElevationGain = unit.Length(nan(float64(x.TotalAscent)))
+		s.AveragePower = unit.Power(nan(float64(x.AvgPower)))
+		s.MaxPower = unit.Power(nan(float64(x.MaxPower)))
+		return s
+	}
+	records := f.timed()
+	n := len(records)
+	if n == 0 {
+		return s
+	}
+	first, last := records[0], records[n-1]
+	s.StartTime = first.Timestamp
+	s.ElapsedTime = unit.Duration(last.Timestamp.Sub(first.Timestamp).Seconds()) * unit.Second
+	for i := n - 1; i >= 0; i-- {
+		if !math.IsNaN(float64(records[i].Distance)) {
+			s.Distance = records[i].Distance
+			break
+		}
+	}
+	return s
+}
+
+// Series returns the records as a provider-neutral time series
+//
+// Records without a timestamp are skipped.
+func (f *FIT) Series() (*Series, error) {
+	records := f.timed()
+	n := len(records)
+	if n == 0 {
+		return nil, errNoRecords
+	}
+	start := records[0].Timestamp
+	x := &Series{
+		StartTime:   start,
+		Time:        make([]unit.Duration, n),
+		Latitude:    make([]float64, n),
+		Longitude:   make([]float64, n),
+		Elevation:   make([]unit.Length, n),
+		Distance:    make([]unit.Length, n),
+		Speed:       make([]unit.Speed, n),
+		HeartRate:   make([]float64, n),
+		Cadence:     make([]float64, n),
+		Power:       make([]unit.Power, n),
+		Temperature: make([]unit.Temperature, n),
+		Grade:       make([]float64, n),
+	}
+	var has struct{ latlng, ele, dst, spd, hr, cad, pwr, tmp, grd bool }
+	valid := func(f float64) bool { return !math.IsNaN(f) }
+	for i, r := range records {
+		x.Time[i] = unit.Duration(r.Timestamp.Sub(start).Seconds()) * unit.Second
+		x.Latitude[i], x.Longitude[i] = r.Latitude, r.Longitude
+		x.Elevation[i] = r.Altitude
+		x.Distance[i] = r.Distance
+		x.Speed[i] = r.Speed
+		x.HeartRate[i] = r.HeartRate
+		x.Cadence[i] = r.Cadence
+		x.Power[i] = r.Power
+		x.Temperature[i] = r.Temperature
+		x.Grade[i] = r.Grade
+		has.latlng = has.latlng || (valid(r.Latitude) && valid(r.Longitude))
+		has.ele = has.ele || valid(float64(r.Altitude))
+		has.dst = has.dst || valid(float64(r.Distance))
+		has.spd = has.spd || valid(float64(r.Speed))
+		has.hr = has.hr || valid(r.HeartRate)
+		has.cad = has.cad || valid(r.Cadence)
+		has.pwr = has.pwr || valid(float64(r.Power))
+		has.tmp = has.tmp || valid(float64(r.Temperature))
+		has.grd = has.grd || valid(r.Grade)
+	}
+	if !has.latlng {
+		x.Latitude, x.Longitude = nil, nil
+	}
+	if !has.ele {
+		x.Elevation = nil
+	}
+	if !has.dst {
+		x.Distance = nil
+	}
+	if !has.spd {
+		x.Speed = nil
+	}
+	if !has.hr {
+		x.HeartRate = nil
+	}
+	if !has.cad {
+		x.Cadence = nil
+	}
+	if !has.pwr {
+		x.Power = nil
+	}
+	if !has.tmp {
+		x.Temperature = nil
+	}
+	if !has.grd {
+		x.Grade = nil
+	}
+	return x, nil
+}
+
+// timed returns the records with a timestamp
+func (f *FIT) timed() []*FITRecord {
+	return slices.DeleteFunc(slices.Clone(f.Records), func(r *FITRecord) bool {
+		return r.Timestamp.IsZero()
+	})
+}
+
+func fitToSport(sport uint8) Sport {
+	switch sport {
+	case fitSportCycling:
+		return SportCycling
+	case fitSportRunning:
+		return SportRunning
+	case fitSportWalking:
+		return SportWalking
+	case fitSportHiking:
+		return SportHiking
+	case fitSportSwimming:
+		return SportSwimming
+	default:
+		return SportOther
+	}
+}
+
+// nan returns zero for NaN values
+func nan(f float64) float64 {
+	if math.IsNaN(f) {
+		return 0
+	}
+	return f
+}
+
+// fitCRC computes the FIT checksum of the data starting with `crc`
+func fitCRC(crc uint16, data []byte) uint16 {
+	table := [16]uint16{
+		0x0000, 0xCC01, 0xD801, 0x1400, 0xF001, 0x3C00, 0x2800, 0xE401,
+		0xA001, 0x6C00, 0x7800, 0xB401, 0x5000, 0x9C01, 0x8801, 0x4400,
+	}
+	for _, b := range data {
+		tmp := table[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ table[b&0xF]
+		tmp = table[crc&0xF]
+		crc = (crc >> 4) & 0x0FFF
+		crc = crc ^ tmp ^ table[(b>>4)&0xF]
+	}
+	return crc
+}
+
+// fitTime returns the time of the FIT timestamp, a missing or invalid timestamp is the zero time
+func fitTime(t uint32) time.Time {
+	if t == 0 || t == math.MaxUint32 {
+		return time.Time{}
+	}
+	return time.Unix(fitEpoch+int64(t), 0).UTC()
+}
@@ -0,0 +1,455 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+
+	"github.com/martinlindhe/unit"
+)
+
+var errNoRecords = errors.New("no records")
+
+type fitFieldDef struct {
+	num  uint8
+	size uint8
+	base byte
+}
+
+type fitDevFieldDef struct {
+	num   uint8
+	size  uint8
+	index uint8
+}
+
+type fitDefinition struct {
+	global uint16
+	order  binary.ByteOrder
+	fields []fitFieldDef
+	devs   []fitDevFieldDef
+}
+
+type fitField struct {
+	base  byte
+	data  []byte
+	order binary.ByteOrder
+}
+
+type fitMessage struct {
+	global    uint16
+	fields    map[uint8]fitField
+	developer map[string]any
+}
+
+// number returns the first value of the field scaled and offset or NaN if not present or invalid
+func (m *fitMessage) number(num uint8, scale, offset float64) float64 {
+	f, ok := m.fields[num]
+	if !ok {
+		return math.NaN()
+	}
+	v, ok := fitNumber(f.base, f.data, f.order)
+	if !ok {
+		return math.NaN()
+	}
+	return v/scale - offset
+}
+
+// uint returns the first value of the field or `def` if not present or invalid
+func (m *fitMessage) uint(num uint8, def uint64) uint64 {
+	v := m.number(num, 1, 0)
+	if math.IsNaN(v) {
+		return def
+	}
+	return uint64(v)
+}
+
+// string returns the NUL terminated string value of the field
+func (m *fitMessage) string(num uint8) string {
+	f, ok := m.fields[num]
+	if !ok {
+		return ""
+	}
+	if i := bytes.IndexByte(f.data, 0); i >= 0 {
+		return string(f.data[:i])
+	}
+	return string(f.data)
+}
+
+// degrees returns the field in semicircles as degrees
+func (m *fitMessage) degrees(num uint8) float64 {
+	return m.number(num, 1/fitSemicircles, 0)
+}
+
+// fitBaseSize returns the size in bytes of a single value of the base type
+func fitBaseSize(base byte) int {
+	switch base {
+	case fitSint16, fitUint16, fitUint16z:
+		return 2
+	case fitSint32, fitUint32, fitUint32z, fitFloat32:
+		return 4
+	case fitSint64, fitUint64, fitUint64z, fitFloat64:
+		return 8
+	default:
+		return 1
+	}
+}
+
+// fitNumber decodes the first value of the base type, returning false if the value is invalid
+func fitNumber(base byte, b []byte, order binary.ByteOrder) (float64, bool) { //nolint:gocyclo // enumeration
+	if len(b) < fitBaseSize(base) {
+		return 0, false
+	}
+	switch base {
+	case fitEnum, fitUint8, fitByte:
+		return float64(b[0]), b[0] != math.MaxUint8
+	case fitUint8z:
+		return float64(b[0]), b[0] != 0
+	case fitSint8:
+		return float64(int8(b[0])), b[0] != math.MaxInt8
+	case fitUint16:
+		v := order.Uint16(b)
+		return float64(v), v != math.MaxUint16
+	case fitUint16z:
+		v := order.Uint16(b)
+		return float64(v), v != 0
+	case fitSint16:
+		v := int16(order.Uint16(b)) //nolint:gosec // two's complement
+		return float64(v), v != math.MaxInt16
+	case fitUint32:
+		v := order.Uint32(b)
+		return float64(v), v != math.MaxUint32
+	case fitUint32z:
+		v := order.Uint32(b)
+		return float64(v), v != 0
+	case fitSint32:
+		v := int32(order.Uint32(b)) //nolint:gosec // two's complement
+		return float64(v), v != math.MaxInt32
+	case fitFloat32:
+		v := order.Uint32(b)
+		return float64(math.Float32frombits(v)), v != math.MaxUint32
+	case fitFloat64:
+		v := order.Uint64(b)
+		return math.Float64frombits(v), v != math.MaxUint64
+	case fitUint64:
+		v := order.Uint64(b)
+		return float64(v), v != math.MaxUint64
+	case fitUint64z:
+		v := order.Uint64(b)
+		return float64(v), v != 0
+	case fitSint64:
+		v := int64(order.Uint64(b)) //nolint:gosec // two's complement
+		return float64(v), v != math.MaxInt64
+	default:
+		return 0, false
+	}
+}
+
+type fitDecoder struct {
+	data      []byte
+	pos       int
+	timestamp uint32
+	defs      [16]*fitDefinition
+	fields    map[[2]uint8]*FITDeveloperField
+	fit       *FIT
+}
+
+// DecodeFIT decodes a FIT activity file
+//
+// The file_id, session, lap, record, and event messages are decoded along with any
+// developer fields, all other messages are ignored. Both the header and file checksums
+// are verified.
+func DecodeFIT(r io.Reader) (*FIT, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	d := &fitDecoder{data: data, fields: make(map[[2]uint8]*FITDeveloperField), fit: &FIT{}}
+	if err = d.header(); err != nil {
+		return nil, err
+	}
+	end := int(d.fit.Header.Size) + int(d.fit.Header.DataSize)
+	for d.pos < end {
+		if err = d.record(); err != nil {
+			return nil, err
+		}
+	}
+	if d.pos != end {
+		return nil, errors.New("invalid fit file: message exceeds data size")
+	}
+	return d.fit, nil
+}
+
+// FIT decodes the file as a FIT activity file
+//
+// The contents of the file are buffered so the file remains readable, for example to
+// upload the file after validating it.
+func (f *File) FIT() (*FIT, error) {
+	data, err := f.buffer()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeFIT(bytes.NewReader(data))
+}
+
+func (d *fitDecoder) header() error {
+	if len(d.data) < fitHeaderSizeLegacy {
+		return errors.New("invalid fit file: header too short")
+	}
+	size := int(d.data[0])
+	if size < fitHeaderSizeLegacy || len(d.data) < size {
+		return fmt.Errorf("invalid fit file: header size %d", size)
+	}
+	if string(d.data[8:12]) != ".FIT" {
+		return errors.New("invalid fit file: missing signature")
+	}
+	h := FITHeader{
+		Size:            d.data[0],
+		ProtocolVersion: d.data[1],
+		ProfileVersion:  binary.LittleEndian.Uint16(d.data[2:4]),
+		DataSize:        binary.LittleEndian.Uint32(d.data[4:8]),
+	}
+	if size >= fitHeaderSize {
+		crc := binary.LittleEndian.Uint16(d.data[12:14])
+		if crc != 0 && crc != fitCRC(0, d.data[:12]) {
+			return errors.New("invalid fit file: header checksum mismatch")
+		}
+	}
+	end := size + int(h.DataSize)
+	if len(d.data) < end+2 {
+		return errors.New("invalid fit file: truncated")
+	}
+	if binary.LittleEndian.Uint16(d.data[end:end+2]) != fitCRC(0, d.data[:end]) {
+		return errors.New("invalid fit file: checksum mismatch")
+	}
+	d.fit.Header = h
+	d.pos = size
+	return nil
+}
+
+func (d *fitDecoder) next(n int) ([]byte, error) {
+	if d.pos+n > len(d.data) {
+		return nil, errors.New("invalid fit file: unexpected end of data")
+	}
+	b := d.data[d.pos : d.pos+n]
+	d.pos += n
+	return b, nil
+}
+
+func (d *fitDecoder) record() error {
+	b, err := d.next(1)
+	if err != nil {
+		return err
+	}
+	h := b[0]
+	switch {
+	case h&0x80 != 0:
+		// compressed timestamp header
+		offset := uint32(h & 0x1F)
+		ts := d.timestamp&^0x1F + offset
+		if offset < d.timestamp&0x1F {
+			ts += 0x20
+		}
+		return d.message(d.defs[(h>>5)&0x03], &ts)
+	case h&0x40 != 0:
+		return d.definition(h&0x0F, h&0x20 != 0)
+	default:
+		return d.message(d.defs[h&0x0F], nil)
+	}
+}
+
+func (d *fitDecoder) definition(local uint8, developer bool) error {
+	b, err := d.next(5)
+	if err != nil {
+		return err
+	}
+	def := &fitDefinition{order: binary.LittleEndian}
+	if b[1] == 1 {
+		def.order = binary.BigEndian
+	}
+	def.global = def.order.Uint16(b[2:4])
+	n := int(b[4])
+	if b, err = d.next(3 * n); err != nil {
+		return err
+	}
+	def.fields = make([]fitFieldDef, n)
+	for i := range n {
+		def.fields[i] = fitFieldDef{num: b[3*i], size: b[3*i+1], base: b[3*i+2]}
+	}
+	if developer {
+		if b, err = d.next(1); err != nil {
+			return err
+		}
+		n = int(b[0])
+		if b, err = d.next(3 * n); err != nil {
+			return err
+		}
+		def.devs = make([]fitDevFieldDef, n)
+		for i := range n {
+			def.devs[i] = fitDevFieldDef{num: b[3*i], size: b[3*i+1], index: b[3*i+2]}
+		}
+	}
+	d.defs[local] = def
+	return nil
+}
+
+func (d *fitDecoder) message(def *fitDefinition, ts *uint32) error {
+	if def == nil {
+		return errors.New("invalid fit file: missing definition")
+	}
+	m := &fitMessage{global: def.global, fields: make(map[uint8]fitField, len(def.fields))}
+	for _, f := range def.fields {
+		b, err := d.next(int(f.size))
+		if err != nil {
+			return err
+		}
+		m.fields[f.num] = fitField{base: f.base, data: b, order: def.order}
+	}
+	for _, f := range def.devs {
+		b, err := d.next(int(f.size))
+		if err != nil {
+			return err
+		}
+		desc, ok := d.fields[[2]uint8{f.index, f.num}]
+		if !ok {
+			// without a description the value cannot be decoded
+			continue
+		}
+		if m.developer == nil {
+			m.developer = make(map[string]any)
+		}
+		switch desc.BaseType {
+		case fitString:
+			m.developer[desc.Name] = string(bytes.TrimRight(b, "\x00"))
+		case fitByte:
+			m.developer[desc.Name] = b
+		default:
+			if v, valid := fitNumber(desc.BaseType, b, def.order); valid {
+				m.developer[desc.Name] = v/desc.Scale - desc.Offset
+			}
+		}
+	}
+	switch {
+	case ts != nil:
+		d.timestamp = *ts
+		m.fields[fitFieldTimestamp] = fitField{
+			base: fitUint32, data: binary.LittleEndian.AppendUint32(nil, *ts), order: binary.LittleEndian}
+	default:
+		if v := m.uint(fitFieldTimestamp, 0); v > 0 {
+			d.timestamp = uint32(v)
+		}
+	}
+	d.decode(m)
+	return nil
+}
+
+func (d *fitDecoder) decode(m *fitMessage) {
+	switch m.global {
+	case fitMesgFileID:
+		d.fit.FileID = FITFileID{
+			Type:         uint8(m.uint(0, 0)),
+			Manufacturer: uint16(m.uint(1, 0)),
+			Product:      uint16(m.uint(2, 0)),
+			SerialNumber: uint32(m.uint(3, 0)),
+			TimeCreated:  fitTime(uint32(m.uint(4, 0))),
+		}
+	case fitMesgFieldDescription:
+		f := &FITDeveloperField{
+			DeveloperDataIndex: uint8(m.uint(0, 0)),
+			FieldNumber:        uint8(m.uint(1, 0)),
+			BaseType:           byte(m.uint(2, 0)),
+			Name:               m.string(3),
+			Units:              m.string(8),
+			Scale:              float64(m.uint(6, 1)),
+			Offset:             nan(m.number(7, 1, 0)),
+		}
+		if f.Scale == 0 {
+			f.Scale = 1
+		}
+		d.fields[[2]uint8{f.DeveloperDataIndex, f.FieldNumber}] = f
+		d.fit.DeveloperFields = append(d.fit.DeveloperFields, f)
+	case fitMesgSession:
+		s := fitSession(m, 14, 124)
+		d.fit.Sessions = append(d.fit.Sessions, &s)
+	case fitMesgLap:
+		d.fit.Laps = append(d.fit.Laps, fitLap(m))
+	case fitMesgRecord:
+		d.fit.Records = append(d.fit.Records, fitRecord(m))
+	case fitMesgEvent:
+		d.fit.Events = append(d.fit.Events, &FITEvent{
+			Timestamp: fitTime(uint32(m.uint(fitFieldTimestamp, 0))),
+			Event:     uint8(m.uint(0, 0)),
+			EventType: uint8(m.uint(1, 0)),
+			Data:      uint32(m.uint(3, 0)),
+		})
+	}
+}
+
+// enhanced returns the value of the enhanced field if available otherwise the standard field
+func (m *fitMessage) enhanced(num, enhanced uint8, scale, offset float64) float64 {
+	if v := m.number(enhanced, scale, offset); !math.IsNaN(v) {
+		return v
+	}
+	return m.number(num, scale, offset)
+}
+
+// fitSession decodes a session message
+//
+// The session and lap messages share fields in the 2-9 and 253 ranges but diverge afterwards
+// so the field number of the average speed (and enhanced average speed) is used to align them.
+func fitSession(m *fitMessage, speed, enhanced uint8) FITSession {
+	return FITSession{
+		Timestamp:        fitTime(uint32(m.uint(fitFieldTimestamp, 0))),
+		StartTime:        fitTime(uint32(m.uint(2, 0))),
+		StartLatitude:    m.degrees(3),
+		StartLongitude:   m.degrees(4),
+		Sport:            uint8(m.uint(5, uint64(fitSportGeneric))),
+		SubSport:         uint8(m.uint(6, 0)),
+		TotalElapsedTime: unit.Duration(m.number(7, 1000, 0)) * unit.Second,
+		TotalTimerTime:   unit.Duration(m.number(8, 1000, 0)) * unit.Second,
+		TotalDistance:    unit.Length(m.number(9, 100, 0)) * unit.Meter,
+		TotalCalories:    m.number(11, 1, 0),
+		AvgSpeed:         unit.Speed(m.enhanced(speed, enhanced, 1000, 0)) * unit.MetersPerSecond,
+		MaxSpeed:         unit.Speed(m.enhanced(speed+1, enhanced+1, 1000, 0)) * unit.MetersPerSecond,
+		AvgHeartRate:     m.number(speed+2, 1, 0),
+		MaxHeartRate:     m.number(speed+3, 1, 0),
+		AvgCadence:       m.number(speed+4, 1, 0),
+		MaxCadence:       m.number(speed+5, 1, 0),
+		AvgPower:         unit.Power(m.number(speed+6, 1, 0)) * unit.Watt,
+		MaxPower:         unit.Power(m.number(speed+7, 1, 0)) * unit.Watt,
+		TotalAscent:      unit.Length(m.number(speed+8, 1, 0)) * unit.Meter,
+		TotalDescent:     unit.Length(m.number(speed+9, 1, 0)) * unit.Meter,
+		Developer:        m.developer,
+	}
+}
+
+func fitLap(m *fitMessage) *FITLap {
+	lap := &FITLap{
+		FITSession:   fitSession(m, 13, 110),
+		EndLatitude:  m.degrees(5),
+		EndLongitude: m.degrees(6),
+	}
+	// the lap's start position is shared with the session but sport is not
+	lap.Sport = uint8(m.uint(25, uint64(fitSportGeneric)))
+	lap.SubSport = uint8(m.uint(39, 0))
+	return lap
+}
+
+func fitRecord(m *fitMessage) *FITRecord {
+	return &FITRecord{
+		Timestamp:   fitTime(uint32(m.uint(fitFieldTimestamp, 0))),
+		Latitude:    m.degrees(0),
+		Longitude:   m.degrees(1),
+		Altitude:    unit.Length(m.enhanced(2, 78, 5, 500)) * unit.Meter,
+		HeartRate:   m.number(3, 1, 0),
+		Cadence:     m.number(4, 1, 0),
+		Distance:    unit.Length(m.number(5, 100, 0)) * unit.Meter,
+		Speed:       unit.Speed(m.enhanced(6, 73, 1000, 0)) * unit.MetersPerSecond,
+		Power:       unit.Power(m.number(7, 1, 0)) * unit.Watt,
+		Grade:       m.number(9, 100, 0),
+		Temperature: unit.FromCelsius(m.number(13, 1, 0)),
+		Developer:   m.developer,
+	}
+}
@@ -0,0 +1,381 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+// fitValue is a single field of a data message to encode
+type fitValue struct {
+	num  uint8
+	base byte
+	// value is the raw numeric value, NaN values are encoded as invalid
+	value float64
+	// str is the value for string fields
+	str string
+}
+
+func fitNum(num uint8, base byte, value float64) fitValue {
+	return fitValue{num: num, base: base, value: value}
+}
+
+// fitScaled applies the FIT scale and offset to the value
+func fitScaled(num uint8, base byte, value, scale, offset float64) fitValue {
+	return fitValue{num: num, base: base, value: (value + offset) * scale}
+}
+
+func fitStr(num uint8, value string) fitValue {
+	return fitValue{num: num, base: fitString, str: value}
+}
+
+func fitTimestamp(num uint8, t time.Time) fitValue {
+	return fitValue{num: num, base: fitUint32, value: float64(t.Unix() - fitEpoch)}
+}
+
+func fitPosition(num uint8, deg float64) fitValue {
+	return fitValue{num: num, base: fitSint32, value: deg / fitSemicircles}
+}
+
+func (v fitValue) size() int {
+	if v.base == fitString {
+		// strings are NUL terminated and limited by the single byte field size
+		return min(len(v.str), math.MaxUint8-1) + 1
+	}
+	return fitBaseSize(v.base)
+}
+
+// fitInvalid returns the invalid value for the base type
+func fitInvalid(base byte) uint64 {
+	switch base {
+	case fitUint8z, fitUint16z, fitUint32z, fitUint64z:
+		return 0
+	case fitSint8:
+		return math.MaxInt8
+	case fitSint16:
+		return math.MaxInt16
+	case fitSint32:
+		return math.MaxInt32
+	case fitSint64:
+		return math.MaxInt64
+	default:
+		return uint64(math.MaxUint64) >> (64 - 8*fitBaseSize(base))
+	}
+}
+
+func (v fitValue) append(b []byte) []byte {
+	if v.base == fitString {
+		s := []byte(v.str)[:v.size()-1]
+		return append(append(b, s...), 0)
+	}
+	n := fitBaseSize(v.base)
+	raw := fitInvalid(v.base)
+	switch {
+	case math.IsNaN(v.value):
+	case v.base == fitFloat32:
+		raw = uint64(math.Float32bits(float32(v.value)))
+	case v.base == fitFloat64:
+		raw = math.Float64bits(v.value)
+	case v.base == fitSint8, v.base == fitSint16, v.base == fitSint32, v.base == fitSint64:
+		// clamp to the valid range, the maximum is reserved as invalid
+		hi := float64(fitInvalid(v.base)) - 1
+		raw = uint64(int64(math.Max(-hi, math.Min(hi, math.Round(v.value))))) //nolint:gosec // two's complement
+	default:
+		// the maximum is reserved as invalid except for the z types which reserve zero
+		hi := uint64(math.MaxUint64) >> (64 - 8*n)
+		if raw == hi {
+			hi--
+		}
+		raw = uint64(math.Max(0, math.Min(float64(hi), math.Round(v.value))))
+	}
+	for i := range n {
+		b = append(b, byte(raw>>(8*i)))
+	}
+	return b
+}
+
+type fitEncoder struct {
+	buf   bytes.Buffer
+	local map[uint16]uint8
+	defs  map[uint16][]byte
+}
+
+func newFITEncoder() *fitEncoder {
+	return &fitEncoder{local: make(map[uint16]uint8), defs: make(map[uint16][]byte)}
+}
+
+// write a data message, writing the definition message first if it changed
+func (e *fitEncoder) write(global uint16, values ...fitValue) {
+	local, ok := e.local[global]
+	if !ok {
+		local = uint8(len(e.local) % 16) //nolint:gosec // bounded
+		e.local[global] = local
+	}
+	def := []byte{0, 0}
+	def = binary.LittleEndian.AppendUint16(def, global)
+	def = append(def, byte(len(values)))
+	for _, v := range values {
+		def = append(def, v.num, byte(v.size()), v.base)
+	}
+	if !bytes.Equal(def, e.defs[global]) {
+		e.buf.WriteByte(0x40 | local)
+		e.buf.Write(def)
+		e.defs[global] = def
+	}
+	b := []byte{local}
+	for _, v := range values {
+		b = v.append(b)
+	}
+	e.buf.Write(b)
+}
+
+// flush writes the header, data messages, and checksum
+func (e *fitEncoder) flush(w io.Writer) error {
+	data := e.buf.Bytes()
+	header := []byte{fitHeaderSize, fitProtocolVersion}
+	header = binary.LittleEndian.AppendUint16(header, fitProfileVersion)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(data))) //nolint:gosec // bounded by memory
+	header = append(header, ".FIT"...)
+	header = binary.LittleEndian.AppendUint16(header, fitCRC(0, header))
+	crc := fitCRC(fitCRC(0, header), data)
+	for _, b := range [][]byte{header, data, binary.LittleEndian.AppendUint16(nil, crc)} {
+		if _, err := w.Write(b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func sportToFIT(sport Sport) uint8 {
+	switch sport {
+	case SportCycling:
+		return fitSportCycling
+	case SportRunning:
+		return fitSportRunning
+	case SportWalking:
+		return fitSportWalking
+	case SportHiking:
+		return fitSportHiking
+	case SportSwimming:
+		return fitSportSwimming
+	case SportOther:
+		return fitSportGeneric
+	default:
+		return fitSportGeneric
+	}
+}
+
+// at returns the value at index i for a (possibly nil) channel
+func at[T ~float64](x []T, i int) float64 {
+	if x == nil {
+		return math.NaN()
+	}
+	return float64(x[i])
+}
+
+// EncodeFIT encodes the series as a FIT activity file
+//
+// The summary is optional and, if provided, is used for the sport and the session and lap
+// totals, otherwise the totals are derived from the series.
+func EncodeFIT(w io.Writer, summary *Summary, series *Series) error {
+	if series == nil {
+		return errors.New("missing series")
+	}
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	n := series.Len()
+	start, end := series.Timestamp(0), series.Timestamp(n-1)
+	s := &Summary{
+		StartTime:   start,
+		ElapsedTime: unit.Duration(end.Sub(start).Seconds()) * unit.Second,
+		MovingTime:  unit.Duration(end.Sub(start).Seconds()) * unit.Second,
+	}
+	if series.Distance != nil {
+		s.Distance = series.Distance[n-1]
+	}
+	if summary != nil {
+		s.Sport, s.Trainer = summary.Sport, summary.Trainer
+		s.ElevationGain, s.AveragePower, s.MaxPower = summary.ElevationGain, summary.AveragePower, summary.MaxPower
+		// the totals of the summary are used only if known
+		if summary.ElapsedTime > 0 {
+			s.ElapsedTime = summary.ElapsedTime
+		}
+		if summary.MovingTime > 0 {
+			s.MovingTime = summary.MovingTime
+		}
+		if summary.Distance > 0 {
+			s.Distance = summary.Distance
+		}
+	}
+	if s.MovingTime == 0 {
+		s.MovingTime = s.ElapsedTime
+	}
+
+	e := newFITEncoder()
+	e.write(fitMesgFileID,
+		fitNum(0, fitEnum, float64(fitFileActivity)),
+		fitNum(1, fitUint16, float64(fitManufacturerDevelopment)),
+		fitNum(2, fitUint16, 0),
+		fitTimestamp(4, start))
+	e.write(fitMesgEvent,
+		fitTimestamp(fitFieldTimestamp, start),
+		fitNum(0, fitEnum, float64(fitEventTimer)),
+		fitNum(1, fitEnum, float64(fitEventTypeStart)))
+	for i := range n {
+		e.write(fitMesgRecord, fitRecordValues(series, i)...)
+	}
+	e.write(fitMesgEvent,
+		fitTimestamp(fitFieldTimestamp, end),
+		fitNum(0, fitEnum, float64(fitEventTimer)),
+		fitNum(1, fitEnum, float64(fitEventTypeStopAll)))
+
+	sport := sportToFIT(s.Sport)
+	var sub float64
+	if s.Trainer && sport == fitSportCycling {
+		sub = float64(fitSubSportIndoor)
+	}
+	elapsed := fitScaled(7, fitUint32, s.ElapsedTime.Seconds(), 1000, 0)
+	timer := fitScaled(8, fitUint32, s.MovingTime.Seconds(), 1000, 0)
+	distance := fitScaled(9, fitUint32, s.Distance.Meters(), 100, 0)
+	e.write(fitMesgLap,
+		fitTimestamp(fitFieldTimestamp, end),
+		fitNum(0, fitEnum, float64(fitEventLap)),
+		fitNum(1, fitEnum, float64(fitEventTypeStop)),
+		fitTimestamp(2, start),
+		elapsed, timer, distance,
+		fitNum(19, fitUint16, s.AveragePower.Watts()),
+		fitNum(20, fitUint16, s.MaxPower.Watts()),
+		fitNum(21, fitUint16, s.ElevationGain.Meters()),
+		fitNum(25, fitEnum, float64(sport)))
+	e.write(fitMesgSession,
+		fitTimestamp(fitFieldTimestamp, end),
+		fitNum(0, fitEnum, float64(fitEventSession)),
+		fitNum(1, fitEnum, float64(fitEventTypeStop)),
+		fitTimestamp(2, start),
+		fitNum(5, fitEnum, float64(sport)),
+		fitNum(6, fitEnum, sub),
+		elapsed, timer, distance,
+		fitNum(20, fitUint16, s.AveragePower.Watts()),
+		fitNum(21, fitUint16, s.MaxPower.Watts()),
+		fitNum(22, fitUint16, s.ElevationGain.Meters()),
+		fitNum(25, fitUint16, 0),
+		fitNum(26, fitUint16, 1))
+	e.write(fitMesgActivity,
+		fitTimestamp(fitFieldTimestamp, end),
+		fitScaled(0, fitUint32, s.MovingTime.Seconds(), 1000, 0),
+		fitNum(1, fitUint16, 1),
+		fitNum(2, fitEnum, 0),
+		fitNum(3, fitEnum, float64(fitEventActivity)),
+		fitNum(4, fitEnum, float64(fitEventTypeStop)))
+	return e.flush(w)
+}
+
+// fitRecordValues returns the record fields for all channels available in the series
+func fitRecordValues(series *Series, i int) []fitValue {
+	values := []fitValue{fitTimestamp(fitFieldTimestamp, series.Timestamp(i))}
+	if series.Latitude != nil {
+		values = append(values, fitPosition(0, series.Latitude[i]), fitPosition(1, series.Longitude[i]))
+	}
+	if series.Elevation != nil {
+		values = append(values, fitScaled(2, fitUint16, at(series.Elevation, i), 5, 500))
+	}
+	if series.HeartRate != nil {
+		values = append(values, fitNum(3, fitUint8, at(series.HeartRate, i)))
+	}
+	if series.Cadence != nil {
+		values = append(values, fitNum(4, fitUint8, at(series.Cadence, i)))
+	}
+	if series.Distance != nil {
+		values = append(values, fitScaled(5, fitUint32, at(series.Distance, i), 100, 0))
+	}
+	if series.Speed != nil {
+		values = append(values, fitScaled(6, fitUint16, at(series.Speed, i), 1000, 0))
+	}
+	if series.Power != nil {
+		values = append(values, fitNum(7, fitUint16, at(series.Power, i)))
+	}
+	if series.Grade != nil {
+		values = append(values, fitScaled(9, fitSint16, at(series.Grade, i), 100, 0))
+	}
+	if series.Temperature != nil {
+		values = append(values, fitNum(13, fitSint8, series.Temperature[i].Celsius()))
+	}
+	return values
+}
+
+// EncodeFITCourse encodes the course as a FIT course file
+//
+// Records and course points require timestamps so they are generated from the cumulative
+// distance at a nominal speed starting from the current time.
+func EncodeFITCourse(w io.Writer, course *Course) error {
+	if course == nil {
+		return errors.New("missing course")
+	}
+	if err := course.Validate(); err != nil {
+		return err
+	}
+	n := course.Len()
+	distance := course.Distance()
+	start := time.Now().UTC().Truncate(time.Second)
+	timestamps := course.timestamps(start)
+	end := timestamps[n-1]
+	elapsed := end.Sub(start).Seconds()
+
+	e := newFITEncoder()
+	e.write(fitMesgFileID,
+		fitNum(0, fitEnum, float64(fitFileCourse)),
+		fitNum(1, fitUint16, float64(fitManufacturerDevelopment)),
+		fitNum(2, fitUint16, 0),
+		fitTimestamp(4, start))
+	e.write(fitMesgCourse,
+		fitNum(4, fitEnum, float64(sportToFIT(course.Sport))),
+		fitStr(5, course.Name))
+	e.write(fitMesgLap,
+		fitTimestamp(fitFieldTimestamp, start),
+		fitTimestamp(2, start),
+		fitPosition(3, course.Latitude[0]),
+		fitPosition(4, course.Longitude[0]),
+		fitPosition(5, course.Latitude[n-1]),
+		fitPosition(6, course.Longitude[n-1]),
+		fitScaled(7, fitUint32, elapsed, 1000, 0),
+		fitScaled(8, fitUint32, elapsed, 1000, 0),
+		fitScaled(9, fitUint32, distance[n-1].Meters(), 100, 0))
+	e.write(fitMesgEvent,
+		fitTimestamp(fitFieldTimestamp, start),
+		fitNum(0, fitEnum, float64(fitEventTimer)),
+		fitNum(1, fitEnum, float64(fitEventTypeStart)))
+	for i := range n {
+		values := []fitValue{
+			fitTimestamp(fitFieldTimestamp, timestamps[i]),
+			fitPosition(0, course.Latitude[i]),
+			fitPosition(1, course.Longitude[i]),
+			fitScaled(5, fitUint32, distance[i].Meters(), 100, 0),
+		}
+		if course.Elevation != nil {
+			values = slices.Insert(values, 3, fitScaled(2, fitUint16, course.Elevation[i].Meters(), 5, 500))
+		}
+		e.write(fitMesgRecord, values...)
+	}
+	e.write(fitMesgEvent,
+		fitTimestamp(fitFieldTimestamp, end),
+		fitNum(0, fitEnum, float64(fitEventTimer)),
+		fitNum(1, fitEnum, float64(fitEventTypeStopAll)))
+	for i, p := range course.Points {
+		e.write(fitMesgCoursePoint,
+			fitNum(fitFieldMessageIndex, fitUint16, float64(i)),
+			fitTimestamp(1, timestamps[p.Index]),
+			fitPosition(2, course.Latitude[p.Index]),
+			fitPosition(3, course.Longitude[p.Index]),
+			fitScaled(4, fitUint32, distance[p.Index].Meters(), 100, 0),
+			fitNum(5, fitEnum, float64(p.Type)),
+			fitStr(6, p.Name))
+	}
+	return e.flush(w)
+}
@@ -0,0 +1,135 @@
+package activity_test
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestEncodeFIT(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	series := &activity.Series{
+		StartTime:   start,
+		Time:        []unit.Duration{0, 1, 2},
+		Latitude:    []float64{math.NaN(), 47.1, 47.2},
+		Longitude:   []float64{math.NaN(), -122.1, -122.2},
+		Elevation:   []unit.Length{100, 101.2, 102.4},
+		Distance:    []unit.Length{0, 10, 20.5},
+		Speed:       []unit.Speed{10, 10.25, 10.5},
+		HeartRate:   []float64{120, math.NaN(), 122},
+		Power:       []unit.Power{100, 200, 3000},
+		Temperature: []unit.Temperature{unit.FromCelsius(18), unit.FromCelsius(19), unit.FromCelsius(20)},
+	}
+	summary := &activity.Summary{
+		Sport:        activity.SportCycling,
+		Trainer:      true,
+		ElapsedTime:  2 * unit.Second,
+		Distance:     20.5 * unit.Meter,
+		AveragePower: 1100 * unit.Watt,
+		MaxPower:     3000 * unit.Watt,
+	}
+
+	var buf bytes.Buffer
+	a.NoError(activity.EncodeFIT(&buf, summary, series))
+	fit, err := activity.DecodeFIT(&buf)
+	a.NoError(err)
+	a.Len(fit.Records, 3)
+	a.Len(fit.Laps, 1)
+	a.Len(fit.Sessions, 1)
+	a.Len(fit.Events, 2)
+
+	s := fit.Summarize()
+	a.Equal(activity.SportCycling, s.Sport)
+	a.True(s.Trainer)
+	a.Equal(start, s.StartTime)
+	a.InDelta(2, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(2, s.MovingTime.Seconds(), 0.001)
+	a.InDelta(20.5, s.Distance.Meters(), 0.001)
+	a.InDelta(1100, s.AveragePower.Watts(), 0.001)
+
+	x, err := fit.Series()
+	a.NoError(err)
+	a.Equal(3, x.Len())
+	a.False(x.HasLatLng(0))
+	a.True(x.HasLatLng(1))
+	a.InDelta(47.2, x.Latitude[2], 0.00001)
+	a.InDelta(-122.2, x.Longitude[2], 0.00001)
+	a.InDelta(102.4, x.Elevation[2].Meters(), 0.001)
+	a.InDelta(20.5, x.Distance[2].Meters(), 0.001)
+	a.InDelta(10.25, x.Speed[1].MetersPerSecond(), 0.001)
+	a.True(math.IsNaN(x.HeartRate[1]))
+	a.InDelta(3000, x.Power[2].Watts(), 0.001)
+	a.InDelta(19, x.Temperature[1].Celsius(), 0.001)
+	a.Nil(x.Cadence)
+
+	// without a summary the totals are derived from the series
+	buf.Reset()
+	a.NoError(activity.EncodeFIT(&buf, nil, series))
+	fit, err = activity.DecodeFIT(&buf)
+	a.NoError(err)
+	s = fit.Summarize()
+	a.Equal(activity.SportOther, s.Sport)
+	a.False(s.Trainer)
+	a.InDelta(20.5, s.Distance.Meters(), 0.001)
+	a.InDelta(2, s.ElapsedTime.Seconds(), 0.001)
+
+	// unknown totals of the summary are derived from the series
+	buf.Reset()
+	a.NoError(activity.EncodeFIT(&buf, &activity.Summary{Sport: activity.SportCycling}, series))
+	fit, err = activity.DecodeFIT(&buf)
+	a.NoError(err)
+	s = fit.Summarize()
+	a.Equal(activity.SportCycling, s.Sport)
+	a.InDelta(20.5, s.Distance.Meters(), 0.001)
+	a.InDelta(2, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(2, s.MovingTime.Seconds(), 0.001)
+
+	a.Error(activity.EncodeFIT(&buf, nil, nil))
+	a.Error(activity.EncodeFIT(&buf, nil, &activity.Series{}))
+}
+
+func TestEncodeFITCourse(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	course := &activity.Course{
+		Name:      "Loop",
+		Sport:     activity.SportRunning,
+		Latitude:  []float64{47.1, 47.2, 47.3},
+		Longitude: []float64{-122.1, -122.2, -122.3},
+		Elevation: []unit.Length{10, 20, 30},
+		Points:    []*activity.CoursePoint{{Name: "Top", Type: activity.CoursePointSummit, Index: 2}},
+	}
+
+	var buf bytes.Buffer
+	a.NoError(activity.EncodeFITCourse(&buf, course))
+	fit, err := activity.DecodeFIT(&buf)
+	a.NoError(err)
+	a.Equal(uint8(6), fit.FileID.Type)
+	a.Len(fit.Records, 3)
+	a.Len(fit.Laps, 1)
+	a.InDelta(47.3, fit.Laps[0].EndLatitude, 0.00001)
+
+	distance := course.Distance()
+	for i, r := range fit.Records {
+		a.InDelta(course.Latitude[i], r.Latitude, 0.00001)
+		a.InDelta(course.Longitude[i], r.Longitude, 0.00001)
+		a.InDelta(course.Elevation[i].Meters(), r.Altitude.Meters(), 0.2)
+		a.InDelta(distance[i].Meters(), r.Distance.Meters(), 0.01)
+		if i > 0 {
+			a.True(r.Timestamp.After(fit.Records[i-1].Timestamp))
+		}
+	}
+
+	a.Error(activity.EncodeFITCourse(&buf, nil))
+	a.Error(activity.EncodeFITCourse(&buf, &activity.Course{}))
+}
@@ -0,0 +1,298 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// fitBuilder assembles FIT files for testing the decoder
+type fitBuilder struct {
+	buf bytes.Buffer
+}
+
+func (b *fitBuilder) definition(local uint8, global uint16, fields [][3]byte, devs [][3]byte) {
+	h := 0x40 | local
+	if len(devs) > 0 {
+		h |= 0x20
+	}
+	b.buf.WriteByte(h)
+	b.buf.Write([]byte{0, 0})
+	b.buf.Write(binary.LittleEndian.AppendUint16(nil, global))
+	b.buf.WriteByte(byte(len(fields)))
+	for _, f := range fields {
+		b.buf.Write(f[:])
+	}
+	if len(devs) > 0 {
+		b.buf.WriteByte(byte(len(devs)))
+		for _, f := range devs {
+			b.buf.Write(f[:])
+		}
+	}
+}
+
+func (b *fitBuilder) message(header byte, values ...any) {
+	b.buf.WriteByte(header)
+	for _, v := range values {
+		switch x := v.(type) {
+		case string:
+			b.buf.WriteString(x)
+		default:
+			if err := binary.Write(&b.buf, binary.LittleEndian, x); err != nil {
+				panic(err)
+			}
+		}
+	}
+}
+
+func (b *fitBuilder) bytes() []byte {
+	data := b.buf.Bytes()
+	header := []byte{fitHeaderSize, 0x20}
+	header = binary.LittleEndian.AppendUint16(header, 2132)
+	header = binary.LittleEndian.AppendUint32(header, uint32(len(data)))
+	header = append(header, ".FIT"...)
+	header = binary.LittleEndian.AppendUint16(header, fitCRC(0, header))
+	out := append(header, data...)
+	return binary.LittleEndian.AppendUint16(out, fitCRC(0, out))
+}
+
+func semicircles(deg float64) int32 {
+	return int32(deg / fitSemicircles)
+}
+
+func newTestFIT(start time.Time) []byte {
+	ts := uint32(start.Unix() - fitEpoch)
+	b := &fitBuilder{}
+	// file_id
+	b.definition(0, fitMesgFileID, [][3]byte{{0, 1, fitEnum}, {1, 2, fitUint16}, {4, 4, fitUint32}}, nil)
+	b.message(0, uint8(4), fitManufacturerZwift, ts)
+	// developer field description
+	b.definition(1, fitMesgFieldDescription,
+		[][3]byte{{0, 1, fitUint8}, {1, 1, fitUint8}, {2, 1, fitUint8}, {3, 8, fitString}, {8, 4, fitString}}, nil)
+	b.message(1, uint8(0), uint8(0), fitUint16, "Balance\x00", "%\x00\x00\x00")
+	// record with a developer field
+	b.definition(2, fitMesgRecord, [][3]byte{
+		{253, 4, fitUint32}, {0, 4, fitSint32}, {1, 4, fitSint32}, {2, 2, fitUint16}, {3, 1, fitUint8},
+		{5, 4, fitUint32}, {6, 2, fitUint16}, {7, 2, fitUint16}, {13, 1, fitSint8},
+	}, [][3]byte{{0, 2, 0}})
+	for i := range 3 {
+		hr := uint8(120 + i)
+		if i == 1 {
+			// invalid heart rate
+			hr = math.MaxUint8
+		}
+		b.message(2, ts+uint32(i), semicircles(47.6), semicircles(-122.3),
+			uint16((100+500)*5), hr, uint32(i*1000), uint16(5000), uint16(200+i), int8(18), uint16(48+i))
+	}
+	// record with a compressed timestamp
+	b.definition(3, fitMesgRecord, [][3]byte{{7, 2, fitUint16}}, nil)
+	b.message(0x80|(3<<5)|byte((ts+3)&0x1F), uint16(250))
+	// event
+	b.definition(0, fitMesgEvent, [][3]byte{{253, 4, fitUint32}, {0, 1, fitEnum}, {1, 1, fitEnum}}, nil)
+	b.message(0, ts+3, uint8(0), uint8(4))
+	// lap
+	b.definition(1, fitMesgLap, [][3]byte{
+		{253, 4, fitUint32}, {2, 4, fitUint32}, {7, 4, fitUint32}, {9, 4, fitUint32}, {19, 2, fitUint16}, {25, 1, fitEnum},
+	}, nil)
+	b.message(1, ts+3, ts, uint32(3000), uint32(300000), uint16(210), fitSportCycling)
+	// session
+	b.definition(1, fitMesgSession, [][3]byte{
+		{253, 4, fitUint32}, {2, 4, fitUint32}, {5, 1, fitEnum}, {6, 1, fitEnum}, {7, 4, fitUint32},
+		{8, 4, fitUint32}, {9, 4, fitUint32}, {20, 2, fitUint16}, {21, 2, fitUint16}, {22, 2, fitUint16},
+	}, nil)
+	b.message(1, ts+3, ts, fitSportCycling, fitSubSportVirtual, uint32(3000), uint32(2500), uint32(300000),
+		uint16(210), uint16(400), uint16(25))
+	return b.bytes()
+}
+
+func TestDecodeFIT(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2020, time.November, 24, 7, 28, 35, 0, time.UTC)
+	fit, err := DecodeFIT(bytes.NewReader(newTestFIT(start)))
+	a.NoError(err)
+	a.NotNil(fit)
+
+	a.Equal(uint8(fitHeaderSize), fit.Header.Size)
+	a.Equal(fitManufacturerZwift, fit.FileID.Manufacturer)
+	a.Equal(start, fit.FileID.TimeCreated)
+	a.Len(fit.DeveloperFields, 1)
+	a.Equal("Balance", fit.DeveloperFields[0].Name)
+	a.Equal("%", fit.DeveloperFields[0].Units)
+
+	a.Len(fit.Records, 4)
+	r := fit.Records[0]
+	a.Equal(start, r.Timestamp)
+	a.InDelta(47.6, r.Latitude, 0.00001)
+	a.InDelta(-122.3, r.Longitude, 0.00001)
+	a.InDelta(100, r.Altitude.Meters(), 0.001)
+	a.InDelta(5, r.Speed.MetersPerSecond(), 0.001)
+	a.InDelta(18, r.Temperature.Celsius(), 0.001)
+	a.Equal(float64(120), r.HeartRate)
+	a.Equal(float64(48), r.Developer["Balance"])
+	a.True(math.IsNaN(fit.Records[1].HeartRate))
+	a.True(math.IsNaN(r.Cadence))
+	a.Equal(start.Add(3*time.Second), fit.Records[3].Timestamp)
+	a.InDelta(250, fit.Records[3].Power.Watts(), 0.001)
+
+	a.Len(fit.Events, 1)
+	a.Equal(uint8(4), fit.Events[0].EventType)
+	a.Len(fit.Laps, 1)
+	a.Equal(fitSportCycling, fit.Laps[0].Sport)
+	a.InDelta(3000, fit.Laps[0].TotalDistance.Meters(), 0.001)
+	a.InDelta(210, fit.Laps[0].AvgPower.Watts(), 0.001)
+	a.Len(fit.Sessions, 1)
+
+	s := fit.Summarize()
+	a.Equal(SportCycling, s.Sport)
+	a.True(s.Trainer)
+	a.Equal(start, s.StartTime)
+	a.InDelta(3, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(2.5, s.MovingTime.Seconds(), 0.001)
+	a.InDelta(3000, s.Distance.Meters(), 0.001)
+	a.InDelta(25, s.ElevationGain.Meters(), 0.001)
+	a.InDelta(210, s.AveragePower.Watts(), 0.001)
+	a.InDelta(400, s.MaxPower.Watts(), 0.001)
+
+	fit.Sessions = nil
+	s = fit.Summarize()
+	a.Equal(SportOther, s.Sport)
+	a.InDelta(3, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(20, s.Distance.Meters(), 0.001)
+
+	series, err := fit.Series()
+	a.NoError(err)
+	a.NoError(series.Validate())
+	a.Equal(4, series.Len())
+	a.Equal(start, series.StartTime)
+	a.InDelta(3, series.Time[3].Seconds(), 0.001)
+	a.False(series.HasLatLng(3))
+	a.NotNil(series.Power)
+	a.Nil(series.Cadence)
+	a.Nil(series.Grade)
+
+	// records without a timestamp are skipped
+	fit.Records = append([]*FITRecord{{Distance: 5}}, fit.Records...)
+	fit.Records = append(fit.Records, &FITRecord{Distance: 50})
+	s = fit.Summarize()
+	a.Equal(start, s.StartTime)
+	a.InDelta(3, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(20, s.Distance.Meters(), 0.001)
+	series, err = fit.Series()
+	a.NoError(err)
+	a.Equal(4, series.Len())
+	a.Equal(start, series.StartTime)
+	a.InDelta(3, series.Time[3].Seconds(), 0.001)
+
+	fit.Records = []*FITRecord{{Distance: 5}}
+	series, err = fit.Series()
+	a.Error(err)
+	a.Nil(series)
+
+	fit.Records = nil
+	series, err = fit.Series()
+	a.Error(err)
+	a.Nil(series)
+}
+
+func TestDecodeFITErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	valid := newTestFIT(time.Now())
+	tests := []struct {
+		name string
+		data func() []byte
+		err  string
+	}{
+		{
+			name: "too short",
+			data: func() []byte { return valid[:8] },
+			err:  "header too short",
+		},
+		{
+			name: "signature",
+			data: func() []byte {
+				x := bytes.Clone(valid)
+				x[8] = 'X'
+				return x
+			},
+			err: "missing signature",
+		},
+		{
+			name: "header checksum",
+			data: func() []byte {
+				x := bytes.Clone(valid)
+				x[1] = 0x10
+				return x
+			},
+			err: "header checksum mismatch",
+		},
+		{
+			name: "truncated",
+			data: func() []byte { return valid[:len(valid)-10] },
+			err:  "truncated",
+		},
+		{
+			name: "checksum",
+			data: func() []byte {
+				x := bytes.Clone(valid)
+				x[20]++
+				return x
+			},
+			err: "checksum mismatch",
+		},
+		{
+			name: "missing definition",
+			data: func() []byte {
+				b := &fitBuilder{}
+				b.message(5, uint8(1))
+				return b.bytes()
+			},
+			err: "missing definition",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			fit, err := DecodeFIT(bytes.NewReader(tt.data()))
+			a.Error(err)
+			a.Nil(fit)
+			a.Contains(err.Error(), tt.err)
+		})
+	}
+}
+
+func TestFileFIT(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data := newTestFIT(time.Now())
+	f := &File{Reader: bytes.NewReader(data), Name: "ride.fit", Format: FormatFIT}
+	fit, err := f.FIT()
+	a.NoError(err)
+	a.NotNil(fit)
+
+	// the file remains readable after decoding
+	fit, err = DecodeFIT(f)
+	a.NoError(err)
+	a.Len(fit.Records, 4)
+
+	fit, err = (&File{}).FIT()
+	a.Error(err)
+	a.Nil(fit)
+}
+
+func TestFITTime(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.True(fitTime(0).IsZero())
+	a.True(fitTime(math.MaxUint32).IsZero())
+	a.Equal(time.Date(1989, time.December, 31, 0, 0, 1, 0, time.UTC), fitTime(1))
+}
@@ -0,0 +1,143 @@
+package activity
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// sniffLength is the number of bytes used to detect the format of the content
+const sniffLength = 1024
+
+const extGzip = ".gz"
+
+// isGzip returns true if the data starts with the gzip magic number
+func isGzip(data []byte) bool {
+	return len(data) >= 2 && data[0] == 0x1f && data[1] == 0x8b
+}
+
+// DetectFormat returns the Format of the content
+//
+// FIT files are detected by the header signature and GPX and TCX files by the XML root element.
+// Gzip compressed content is decompressed before detection. If the format cannot be detected
+// the Format Original is returned.
+func DetectFormat(data []byte) Format {
+	if isGzip(data) {
+		gz, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return FormatOriginal
+		}
+		defer gz.Close()
+		// the content is likely truncated so unexpected EOFs are expected
+		head, _ := io.ReadAll(io.LimitReader(gz, sniffLength))
+		if isGzip(head) {
+			return FormatOriginal
+		}
+		return DetectFormat(head)
+	}
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return FormatFIT
+	}
+	dec := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return FormatOriginal
+		}
+		switch x := tok.(type) {
+		case xml.StartElement:
+			switch x.Name.Local {
+			case "gpx":
+				return FormatGPX
+			case "TrainingCenterDatabase":
+				return FormatTCX
+			default:
+				return FormatOriginal
+			}
+		case xml.CharData:
+			if len(bytes.TrimSpace(x)) > 0 {
+				return FormatOriginal
+			}
+		}
+	}
+}
+
+// readCloser closes all the closers in order
+type readCloser struct {
+	io.Reader
+	closers []io.Closer
+}
+
+func (r *readCloser) Close() error {
+	var errs []error
+	for _, c := range r.closers {
+		errs = append(errs, c.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// NewFile returns a File for the content of the reader
+//
+// The format is detected from the content, falling back to the extension of the filename if
+// the content is not recognized. Gzip compressed content is transparently decompressed and the
+// `.gz` extension removed from the name. Closing the File closes the reader if supported.
+func NewFile(r io.Reader, filename string) (*File, error) {
+	var closers []io.Closer
+	if c, ok := r.(io.Closer); ok {
+		closers = append(closers, c)
+	}
+	br := bufio.NewReaderSize(r, sniffLength)
+	head, err := br.Peek(sniffLength)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, err
+	}
+	name := filepath.Base(filename)
+	var content io.Reader = br
+	if isGzip(head) {
+		gz, gerr := gzip.NewReader(br)
+		if gerr != nil {
+			return nil, gerr
+		}
+		closers = append([]io.Closer{gz}, closers...)
+		gr := bufio.NewReaderSize(gz, sniffLength)
+		head, err = gr.Peek(sniffLength)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, errors.Join(err, gz.Close())
+		}
+		content = gr
+		if strings.EqualFold(filepath.Ext(name), extGzip) {
+			name = strings.TrimSuffix(name, filepath.Ext(name))
+		}
+	}
+	format := DetectFormat(head)
+	if format == FormatOriginal {
+		format = ToFormat(filepath.Ext(name))
+	}
+	return &File{
+		Reader:   &readCloser{Reader: content, closers: closers},
+		Filename: filename,
+		Name:     name,
+		Format:   format,
+	}, nil
+}
+
+// OpenFile opens the named file and returns a File with the detected format
+//
+// The caller is responsible for closing the File.
+func OpenFile(filename string) (*File, error) {
+	fp, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	f, err := NewFile(fp, filename)
+	if err != nil {
+		return nil, errors.Join(err, fp.Close())
+	}
+	return f, nil
+}
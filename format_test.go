@@ -0,0 +1,137 @@
+package activity_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+const testGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<!-- a comment before the root -->
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1"></gpx>`
+
+func newFIT(t *testing.T) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	series := &activity.Series{StartTime: time.Now(), Time: []unit.Duration{0, 1}}
+	if err := activity.EncodeFIT(&buf, nil, series); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func compress(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gz := gzip.NewWriter(&buf)
+	if _, err := gz.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := gz.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestDetectFormat(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tcx, err := os.ReadFile("testdata/activity.tcx")
+	a.NoError(err)
+	fit := newFIT(t)
+	gz := compress(t, tcx)
+
+	tests := []struct {
+		name   string
+		data   []byte
+		format activity.Format
+	}{
+		{name: "empty", data: nil, format: activity.FormatOriginal},
+		{name: "fit", data: fit, format: activity.FormatFIT},
+		{name: "gpx", data: []byte(testGPX), format: activity.FormatGPX},
+		{name: "tcx", data: tcx, format: activity.FormatTCX},
+		{name: "gzip fit", data: compress(t, fit), format: activity.FormatFIT},
+		{name: "gzip gpx", data: compress(t, []byte(testGPX)), format: activity.FormatGPX},
+		{name: "truncated gzip", data: gz[:len(gz)/2], format: activity.FormatTCX},
+		{name: "other xml", data: []byte(`<kml></kml>`), format: activity.FormatOriginal},
+		{name: "text", data: []byte(`not an activity`), format: activity.FormatOriginal},
+		{name: "json", data: []byte(`{"gpx": true}`), format: activity.FormatOriginal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a.Equal(tt.format, activity.DetectFormat(tt.data))
+		})
+	}
+}
+
+func TestNewFile(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	fit := newFIT(t)
+	tests := []struct {
+		name, filename, expected string
+		data                     []byte
+		format                   activity.Format
+	}{
+		{name: "fit without extension", filename: "ride.bin", expected: "ride.bin", data: fit, format: activity.FormatFIT},
+		{name: "gzip fit", filename: "rides/ride.fit.gz", expected: "ride.fit",
+			data: compress(t, fit), format: activity.FormatFIT},
+		{name: "gzip gpx", filename: "ride.gpx.gz", expected: "ride.gpx",
+			data: compress(t, []byte(testGPX)), format: activity.FormatGPX},
+		{name: "extension", filename: "ride.tcx", expected: "ride.tcx", data: []byte("unknown"), format: activity.FormatTCX},
+		{name: "unknown", filename: "ride", expected: "ride", data: []byte("unknown"), format: activity.FormatOriginal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			f, err := activity.NewFile(io.NopCloser(bytes.NewReader(tt.data)), tt.filename)
+			a.NoError(err)
+			a.Equal(tt.format, f.Format)
+			a.Equal(tt.expected, f.Name)
+			a.Equal(tt.filename, f.Filename)
+			data, err := io.ReadAll(f)
+			a.NoError(err)
+			if tt.format == activity.FormatFIT {
+				a.Equal(fit, data)
+			}
+			a.NoError(f.Close())
+		})
+	}
+
+	f, err := activity.NewFile(bytes.NewReader([]byte{0x1f, 0x8b, 0x00}), "bad.gz")
+	a.Error(err)
+	a.Nil(f)
+}
+
+func TestOpenFile(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	filename := filepath.Join(t.TempDir(), "ride.fit.gz")
+	a.NoError(os.WriteFile(filename, compress(t, newFIT(t)), 0o600))
+
+	f, err := activity.OpenFile(filename)
+	a.NoError(err)
+	a.Equal(activity.FormatFIT, f.Format)
+	a.Equal("ride.fit", f.Name)
+	fit, err := f.FIT()
+	a.NoError(err)
+	a.Len(fit.Records, 2)
+	a.NoError(f.Close())
+
+	f, err = activity.OpenFile(filepath.Join(t.TempDir(), "missing.fit"))
+	a.Error(err)
+	a.Nil(f)
+}
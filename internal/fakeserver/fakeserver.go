@@ -0,0 +1,81 @@
+// Package fakeserver provides the parts shared by the stateful fakes of the provider APIs
+//
+// Each provider's fake implements the routing and wire format of its API on top of the http
+// server, resource ids, and upload processing provided here. All state of a fake is kept in
+// memory and guarded by the fake's lock.
+package fakeserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+)
+
+// firstID is the id preceding the first id assigned to a resource
+const firstID = 1000
+
+// Server is the http server of a fake
+type Server struct {
+	// URL of the server suitable for use with the provider's WithBaseURL option
+	URL string
+
+	svr *httptest.Server
+}
+
+// Start starts and returns a Server for the handler, the caller should call Close when finished
+func Start(h http.Handler) *Server {
+	svr := httptest.NewServer(h)
+	return &Server{URL: svr.URL, svr: svr}
+}
+
+// Close shuts down the server
+func (s *Server) Close() {
+	s.svr.Close()
+}
+
+// IDs assigns increasing ids to the resources created by a fake, the zero value is ready to use
+//
+// IDs are not safe for concurrent use, the lock of the fake must be held.
+type IDs struct {
+	n int64
+}
+
+// Next returns the next id
+func (i *IDs) Next() int64 {
+	i.n++
+	return firstID + i.n
+}
+
+// Encode writes the value as JSON with the status code
+func Encode(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// Page returns up to limit items starting at the offset, an empty slice if the offset is out of range
+func Page[T any](items []T, offset, limit int) []T {
+	if offset < 0 || offset >= len(items) {
+		return []T{}
+	}
+	return items[offset:min(offset+limit, len(items))]
+}
+
+// Fields returns the JSON encoded fields of the value with the names, unknown names are ignored
+func Fields(v any, names []string) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	all := make(map[string]json.RawMessage)
+	if err = json.Unmarshal(data, &all); err != nil {
+		return nil, err
+	}
+	fields := make(map[string]json.RawMessage)
+	for _, name := range names {
+		if x, ok := all[name]; ok {
+			fields[name] = x
+		}
+	}
+	return fields, nil
+}
@@ -0,0 +1,71 @@
+package fakeserver_test
+
+import (
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+)
+
+func TestServer(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := fakeserver.Start(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		fakeserver.Encode(w, http.StatusCreated, map[string]int{"id": 1})
+	}))
+	defer svr.Close()
+
+	res, err := http.Get(svr.URL)
+	a.NoError(err)
+	defer res.Body.Close()
+	data, err := io.ReadAll(res.Body)
+	a.NoError(err)
+	a.Equal(http.StatusCreated, res.StatusCode)
+	a.Equal("application/json", res.Header.Get("Content-Type"))
+	a.JSONEq(`{"id":1}`, string(data))
+}
+
+func TestIDs(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var ids fakeserver.IDs
+	a.Equal(int64(1001), ids.Next())
+	a.Equal(int64(1002), ids.Next())
+}
+
+func TestPage(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	items := []int{1, 2, 3, 4, 5}
+	a.Equal([]int{1, 2}, fakeserver.Page(items, 0, 2))
+	a.Equal([]int{5}, fakeserver.Page(items, 4, 2))
+	a.Equal([]int{}, fakeserver.Page(items, 5, 2))
+	a.Equal([]int{}, fakeserver.Page(items, -1, 2))
+}
+
+func TestFields(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	v := struct {
+		Power []int `json:"power"`
+		Speed []int `json:"speed"`
+	}{Power: []int{200}, Speed: []int{10}}
+	fields, err := fakeserver.Fields(v, []string{"power", "missing"})
+	a.NoError(err)
+	a.Len(fields, 1)
+	a.JSONEq(`[200]`, string(fields["power"]))
+
+	fields, err = fakeserver.Fields(nil, []string{"power"})
+	a.NoError(err)
+	a.Empty(fields)
+
+	_, err = fakeserver.Fields(func() {}, nil)
+	a.Error(err)
+}
@@ -0,0 +1,131 @@
+package fakeserver
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// ErrEmpty is the processing error of an upload of an empty file
+var ErrEmpty = errors.New("the file is empty")
+
+// DuplicateError is the processing error of an upload of a file already uploaded
+type DuplicateError struct {
+	// ID of the resource created from the file
+	ID int64
+}
+
+func (e *DuplicateError) Error() string {
+	return fmt.Sprintf("duplicate of %d", e.ID)
+}
+
+// Upload is a file accepted for processing and the outcome of processing
+type Upload[T any] struct {
+	// ID of the upload
+	ID int64
+	// Filename of the uploaded file
+	Filename string
+	// Size of the file in bytes
+	Size int64
+	// Details of the upload specific to the provider
+	Details T
+	// Created is the time the upload was accepted
+	Created time.Time
+	// Updated is the time the upload was last processed
+	Updated time.Time
+	// ResourceID is the id of the resource created by processing
+	ResourceID int64
+	// Err is the processing error
+	Err error
+
+	hash      string
+	remaining int
+}
+
+// NewUpload reads the file returning an Upload to be added to Uploads
+func NewUpload[T any](filename string, r io.Reader, details T) (*Upload[T], error) {
+	hash := sha256.New()
+	size, err := io.Copy(hash, r)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().UTC().Truncate(time.Second)
+	return &Upload[T]{
+		Filename: filename,
+		Size:     size,
+		Details:  details,
+		Created:  now,
+		Updated:  now,
+		hash:     hex.EncodeToString(hash.Sum(nil)),
+	}, nil
+}
+
+// Done returns true once the upload is processed
+func (u *Upload[T]) Done() bool {
+	return u.ResourceID != 0 || u.Err != nil
+}
+
+// Uploads processes each upload after a number of status requests
+//
+// Uploads are not safe for concurrent use, the lock of the fake must be held.
+type Uploads[T any] struct {
+	polls   int
+	uploads map[int64]*Upload[T]
+	hashes  map[string]int64
+}
+
+// NewUploads returns Uploads reporting an upload as processing for the number of status requests
+func NewUploads[T any](polls int) *Uploads[T] {
+	return &Uploads[T]{
+		polls:   max(polls, 0),
+		uploads: make(map[int64]*Upload[T]),
+		hashes:  make(map[string]int64),
+	}
+}
+
+// Add the upload with the id
+func (u *Uploads[T]) Add(id int64, upload *Upload[T]) {
+	upload.ID = id
+	upload.remaining = u.polls
+	u.uploads[id] = upload
+}
+
+// Status counts a status request of the upload, processing it once no status requests remain
+//
+// Processing fails with ErrEmpty if the file is empty or a DuplicateError if a resource created
+// from the same contents still exists. Otherwise create returns the id of the new resource or the
+// processing error. False is returned if the upload does not exist.
+func (u *Uploads[T]) Status(
+	id int64, exists func(int64) bool, create func(*Upload[T]) (int64, error)) (*Upload[T], bool) {
+	upload, ok := u.uploads[id]
+	if !ok {
+		return nil, false
+	}
+	switch {
+	case upload.Done():
+	case upload.remaining > 0:
+		upload.remaining--
+	default:
+		u.process(upload, exists, create)
+	}
+	return upload, true
+}
+
+func (u *Uploads[T]) process(upload *Upload[T], exists func(int64) bool, create func(*Upload[T]) (int64, error)) {
+	upload.Updated = time.Now().UTC().Truncate(time.Second)
+	if upload.Size == 0 {
+		upload.Err = ErrEmpty
+		return
+	}
+	if id, ok := u.hashes[upload.hash]; ok && exists(id) {
+		upload.Err = &DuplicateError{ID: id}
+		return
+	}
+	upload.ResourceID, upload.Err = create(upload)
+	if upload.Err == nil {
+		u.hashes[upload.hash] = upload.ResourceID
+	}
+}
@@ -0,0 +1,109 @@
+package fakeserver_test
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+)
+
+// resources records the resources created by processing uploads
+type resources struct {
+	ids     fakeserver.IDs
+	created map[int64]string
+	err     error
+}
+
+func (r *resources) exists(id int64) bool {
+	_, ok := r.created[id]
+	return ok
+}
+
+func (r *resources) create(u *fakeserver.Upload[string]) (int64, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+	id := r.ids.Next()
+	r.created[id] = u.Details
+	return id, nil
+}
+
+func upload(t *testing.T, contents string) *fakeserver.Upload[string] {
+	t.Helper()
+	u, err := fakeserver.NewUpload("ride.fit", strings.NewReader(contents), "details")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return u
+}
+
+func TestUploads(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	res := &resources{created: make(map[int64]string)}
+	uploads := fakeserver.NewUploads[string](2)
+
+	u := upload(t, "fit data")
+	a.Equal(int64(8), u.Size)
+	a.False(u.Created.IsZero())
+	uploads.Add(1, u)
+	for range 2 {
+		x, ok := uploads.Status(1, res.exists, res.create)
+		a.True(ok)
+		a.False(x.Done())
+	}
+	x, ok := uploads.Status(1, res.exists, res.create)
+	a.True(ok)
+	a.True(x.Done())
+	a.NoError(x.Err)
+	a.Equal("details", res.created[x.ResourceID])
+	id := x.ResourceID
+
+	// processed uploads are unchanged by further status requests
+	x, ok = uploads.Status(1, res.exists, res.create)
+	a.True(ok)
+	a.Equal(id, x.ResourceID)
+
+	_, ok = uploads.Status(2, res.exists, res.create)
+	a.False(ok)
+}
+
+func TestUploadsFailure(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	res := &resources{created: make(map[int64]string)}
+	uploads := fakeserver.NewUploads[string](-1)
+	status := func(id int64, contents string) *fakeserver.Upload[string] {
+		uploads.Add(id, upload(t, contents))
+		x, ok := uploads.Status(id, res.exists, res.create)
+		a.True(ok)
+		a.True(x.Done())
+		return x
+	}
+
+	a.ErrorIs(status(1, "").Err, fakeserver.ErrEmpty)
+
+	created := status(2, "fit data")
+	a.NoError(created.Err)
+	var dup *fakeserver.DuplicateError
+	a.ErrorAs(status(3, "fit data").Err, &dup)
+	a.Equal(created.ResourceID, dup.ID)
+
+	// once the resource is deleted the contents can be uploaded again
+	delete(res.created, created.ResourceID)
+	a.NoError(status(4, "fit data").Err)
+
+	res.err = errors.New("rejected")
+	x := status(5, "other data")
+	a.EqualError(x.Err, "rejected")
+	a.Zero(x.ResourceID)
+
+	_, err := fakeserver.NewUpload("ride.fit", iotest.ErrReader(errors.New("unreadable")), "")
+	a.Error(err)
+}
@@ -0,0 +1,134 @@
+package activity
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// Mapping links an activity of a source provider to the activity uploaded to a target provider
+type Mapping struct {
+	Source   string `json:"source"`
+	SourceID int64  `json:"source_id"`
+	Target   string `json:"target"`
+	// TargetID is the id of the activity on the target, 0 if the target does not report it
+	TargetID int64     `json:"target_id,omitempty"`
+	UploadID UploadID  `json:"upload_id"`
+	Created  time.Time `json:"created"`
+}
+
+// Mappings stores the mappings of source activities to target activities
+type Mappings interface {
+	// Mapping returns the mapping of the source activity to the target or nil if none exists
+	Mapping(ctx context.Context, source string, sourceID int64, target string) (*Mapping, error)
+	// Add records the mapping, replacing any existing mapping for the source activity and target
+	Add(ctx context.Context, mapping *Mapping) error
+}
+
+type mappingKey struct {
+	source, target string
+	sourceID       int64
+}
+
+func keyOf(m *Mapping) mappingKey {
+	return mappingKey{source: m.Source, target: m.Target, sourceID: m.SourceID}
+}
+
+type memoryMappings struct {
+	mu       sync.Mutex
+	mappings map[mappingKey]*Mapping
+	// save is called with all the mappings after a mapping is added
+	save func([]*Mapping) error
+}
+
+// NewMemoryMappings returns Mappings stored in memory
+func NewMemoryMappings() Mappings {
+	return &memoryMappings{mappings: make(map[mappingKey]*Mapping)}
+}
+
+// NewFileMappings returns Mappings stored as JSON in the named file
+//
+// The file is read if it exists and rewritten after each added mapping.
+func NewFileMappings(filename string) (Mappings, error) {
+	m := &memoryMappings{mappings: make(map[mappingKey]*Mapping)}
+	data, err := os.ReadFile(filename)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		return nil, err
+	default:
+		var mappings []*Mapping
+		if err = json.Unmarshal(data, &mappings); err != nil {
+			return nil, err
+		}
+		for _, x := range mappings {
+			m.mappings[keyOf(x)] = x
+		}
+	}
+	m.save = func(mappings []*Mapping) error {
+		return writeJSON(filename, mappings)
+	}
+	return m, nil
+}
+
+func (m *memoryMappings) Mapping(_ context.Context, source string, sourceID int64, target string) (*Mapping, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.mappings[mappingKey{source: source, target: target, sourceID: sourceID}], nil
+}
+
+func (m *memoryMappings) Add(_ context.Context, mapping *Mapping) error {
+	if mapping == nil || mapping.Source == "" || mapping.Target == "" {
+		return errors.New("missing mapping, source, or target")
+	}
+	key := keyOf(mapping)
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.save != nil {
+		// the mapping is added only once saved so a failure leaves the mappings unchanged
+		mappings := make([]*Mapping, 0, len(m.mappings)+1)
+		mappings = append(mappings, mapping)
+		for k, x := range m.mappings {
+			if k != key {
+				mappings = append(mappings, x)
+			}
+		}
+		// a stable order keeps the file diff-able
+		slices.SortFunc(mappings, func(a, b *Mapping) int {
+			return cmp.Or(
+				cmp.Compare(a.Source, b.Source), cmp.Compare(a.SourceID, b.SourceID), cmp.Compare(a.Target, b.Target))
+		})
+		if err := m.save(mappings); err != nil {
+			return err
+		}
+	}
+	m.mappings[key] = mapping
+	return nil
+}
+
+// writeJSON writes the value as JSON to a temporary file and renames it to the filename
+func writeJSON(filename string, v any) error {
+	data, err := json.MarshalIndent(v, "", " ")
+	if err != nil {
+		return err
+	}
+	fp, err := os.CreateTemp(filepath.Dir(filename), filepath.Base(filename)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fp.Name())
+	if _, err = fp.Write(data); err != nil {
+		return errors.Join(err, fp.Close())
+	}
+	if err = fp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fp.Name(), filename)
+}
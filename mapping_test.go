@@ -0,0 +1,74 @@
+package activity_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestMemoryMappings(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	mappings := activity.NewMemoryMappings()
+	mapping, err := mappings.Mapping(ctx, "zwift", 1, "strava")
+	a.NoError(err)
+	a.Nil(mapping)
+
+	a.NoError(mappings.Add(ctx, &activity.Mapping{Source: "zwift", SourceID: 1, Target: "strava", TargetID: 11}))
+	mapping, err = mappings.Mapping(ctx, "zwift", 1, "strava")
+	a.NoError(err)
+	a.Equal(int64(11), mapping.TargetID)
+
+	mapping, err = mappings.Mapping(ctx, "zwift", 1, "rwgps")
+	a.NoError(err)
+	a.Nil(mapping)
+
+	a.Error(mappings.Add(ctx, nil))
+	a.Error(mappings.Add(ctx, &activity.Mapping{Source: "zwift"}))
+}
+
+func TestFileMappings(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	filename := filepath.Join(t.TempDir(), "mappings.json")
+	mappings, err := activity.NewFileMappings(filename)
+	a.NoError(err)
+	a.NoError(mappings.Add(ctx, &activity.Mapping{Source: "zwift", SourceID: 2, Target: "strava", TargetID: 22}))
+	a.NoError(mappings.Add(ctx, &activity.Mapping{Source: "zwift", SourceID: 1, Target: "strava", TargetID: 11}))
+
+	// the mappings are available after reloading the file
+	mappings, err = activity.NewFileMappings(filename)
+	a.NoError(err)
+	mapping, err := mappings.Mapping(ctx, "zwift", 2, "strava")
+	a.NoError(err)
+	a.Equal(int64(22), mapping.TargetID)
+
+	a.NoError(os.WriteFile(filename, []byte("{"), 0o600))
+	mappings, err = activity.NewFileMappings(filename)
+	a.Error(err)
+	a.Nil(mappings)
+
+	mappings, err = activity.NewFileMappings(t.TempDir())
+	a.Error(err)
+	a.Nil(mappings)
+
+	// a mapping which fails to save is not added
+	dir := filepath.Join(t.TempDir(), "mappings")
+	a.NoError(os.Mkdir(dir, 0o700))
+	mappings, err = activity.NewFileMappings(filepath.Join(dir, "mappings.json"))
+	a.NoError(err)
+	a.NoError(os.Remove(dir))
+	a.Error(mappings.Add(ctx, &activity.Mapping{Source: "zwift", SourceID: 1, Target: "strava", TargetID: 11}))
+	mapping, err = mappings.Mapping(ctx, "zwift", 1, "strava")
+	a.NoError(err)
+	a.Nil(mapping)
+}
@@ -3,8 +3,13 @@ package activity
 import (
 	"context"
 	"errors"
+	"iter"
+	"sync"
 )
 
+// errStopIteration stops pagination when the consumer of an iterator stops early
+var errStopIteration = errors.New("stop iteration")
+
 // Pagination specifies how to paginate through resources
 type Pagination struct {
 	// Total number of resources to query
@@ -13,6 +18,14 @@ type Pagination struct {
 	Start int
 	// Count of the number of resources to query per page
 	Count int
+	// Offset of the first resource of the page for paginators using an offset, maintained by Paginate
+	Offset int
+	// Cursor of the page for paginators using a cursor, maintained by Paginate
+	Cursor string
+	// Prefetch is the number of pages queried concurrently ahead of the current page by Iter
+	//
+	// Prefetching is not supported when paginating by cursor.
+	Prefetch int
 }
 
 // Paginator paginates through results
@@ -26,31 +39,54 @@ type Paginator interface {
 	Do(ctx context.Context, spec Pagination) (int, error)
 }
 
+// CursorPaginator paginates through results using the cursor returned with each page
+type CursorPaginator interface {
+	Paginator
+	// Cursor returns the cursor of the next page or the empty string if no more pages exist
+	Cursor() string
+}
+
 func Paginate(ctx context.Context, paginator Paginator, spec Pagination) error {
+	spec, err := normalize(paginator.PageSize(), spec)
+	if err != nil {
+		return err
+	}
+	return do(ctx, paginator, spec)
+}
+
+// normalize returns the pagination specification with defaults applied
+func normalize(pageSize int, spec Pagination) (Pagination, error) {
 	var (
 		start = spec.Start
 		count = spec.Count
 		total = spec.Total
 	)
 	if total < 0 {
-		return errors.New("total less than zero")
+		return spec, errors.New("total less than zero")
 	}
 	if start <= 0 {
 		start = 1
 	}
 	if count <= 0 {
-		count = paginator.PageSize()
+		count = pageSize
 	}
 	if total > 0 {
 		if total <= count {
 			count = total
 		}
 		// if requesting only one page of data then optimize
-		if start <= 1 && total < paginator.PageSize() {
+		if start <= 1 && total < pageSize {
 			count = total
 		}
 	}
-	return do(ctx, paginator, Pagination{Total: total, Start: start, Count: count})
+	return Pagination{
+		Total:    total,
+		Start:    start,
+		Count:    count,
+		Offset:   (start - 1) * count,
+		Cursor:   spec.Cursor,
+		Prefetch: max(spec.Prefetch, 0),
+	}, nil
 }
 
 func do(ctx context.Context, paginator Paginator, spec Pagination) error {
@@ -70,7 +106,182 @@ func do(ctx context.Context, paginator Paginator, spec Pagination) error {
 		if spec.Total > 0 && all >= spec.Total {
 			break
 		}
+		if x, ok := paginator.(CursorPaginator); ok {
+			cursor := x.Cursor()
+			if cursor == "" {
+				break
+			}
+			spec.Cursor = cursor
+		}
 		spec.Start++
+		spec.Offset += n
+	}
+	return nil
+}
+
+// Page queries the resources of one page using the pagination specification
+type Page[T any] func(ctx context.Context, spec Pagination) ([]T, error)
+
+// CursorPage queries the resources of one page using the pagination specification
+// returning the resources and the cursor of the next page
+type CursorPage[T any] func(ctx context.Context, spec Pagination) ([]T, string, error)
+
+type iterPaginator[T any] struct {
+	pageSize int
+	count    int
+	page     Page[T]
+	yield    func(T, error) bool
+}
+
+func (p *iterPaginator[T]) PageSize() int {
+	return p.pageSize
+}
+
+func (p *iterPaginator[T]) Count() int {
+	return p.count
+}
+
+func (p *iterPaginator[T]) Do(ctx context.Context, spec Pagination) (int, error) {
+	items, err := p.page(ctx, spec)
+	if err != nil {
+		return 0, err
+	}
+	for _, item := range items {
+		if spec.Total > 0 && p.count >= spec.Total {
+			break
+		}
+		p.count++
+		if !p.yield(item, nil) {
+			return 0, errStopIteration
+		}
+	}
+	return len(items), nil
+}
+
+type cursorIterPaginator[T any] struct {
+	*iterPaginator[T]
+	cursor string
+}
+
+func (p *cursorIterPaginator[T]) Cursor() string {
+	return p.cursor
+}
+
+// Iter returns an iterator over the resources queried one page at a time using Paginate
+//
+// Only a single page of resources is held in memory and no further pages are queried once
+// the consumer stops iterating. An error ends the iteration.
+//
+// If the specification includes Prefetch, up to that many pages following the current page
+// are queried concurrently. Each page is assumed to be full so the offset of a prefetched
+// page is derived from its page number. The page function is responsible for any rate
+// limiting, typically by the transport of the provider client.
+func Iter[T any](ctx context.Context, pageSize int, page Page[T], spec Pagination) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var err error
+		if spec.Prefetch > 0 {
+			err = prefetch(ctx, pageSize, page, spec, yield)
+		} else {
+			err = Paginate(ctx, &iterPaginator[T]{pageSize: pageSize, page: page, yield: yield}, spec)
+		}
+		if err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+// IterCursor returns an iterator over the resources queried one page at a time using Paginate
+//
+// The cursor returned with each page is used to query the next page until an empty cursor is returned.
+func IterCursor[T any](ctx context.Context, pageSize int, page CursorPage[T], spec Pagination) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		p := &cursorIterPaginator[T]{iterPaginator: &iterPaginator[T]{pageSize: pageSize, yield: yield}}
+		p.page = func(ctx context.Context, spec Pagination) ([]T, error) {
+			items, cursor, err := page(ctx, spec)
+			if err != nil {
+				return nil, err
+			}
+			p.cursor = cursor
+			return items, nil
+		}
+		if err := Paginate(ctx, p, spec); err != nil && !errors.Is(err, errStopIteration) {
+			var zero T
+			yield(zero, err)
+		}
+	}
+}
+
+type pageResult[T any] struct {
+	items []T
+	err   error
+}
+
+// prefetch queries pages concurrently yielding the resources in order
+func prefetch[T any](
+	ctx context.Context, pageSize int, page Page[T], spec Pagination, yield func(T, error) bool) error {
+	spec, err := normalize(pageSize, spec)
+	if err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var next int
+	var queue []<-chan *pageResult[T]
+	fill := func() {
+		for len(queue) <= spec.Prefetch {
+			if spec.Total > 0 && next*spec.Count >= spec.Total {
+				// no more pages are needed to fulfill the request
+				return
+			}
+			s := spec
+			s.Start += next
+			s.Offset += next * spec.Count
+			res := make(chan *pageResult[T], 1)
+			wg.Go(func() {
+				items, perr := page(ctx, s)
+				res <- &pageResult[T]{items: items, err: perr}
+			})
+			queue = append(queue, res)
+			next++
+		}
+	}
+
+	var count int
+	for fill(); len(queue) > 0; fill() {
+		res := <-queue[0]
+		queue = queue[1:]
+		if res.err != nil {
+			return res.err
+		}
+		if len(res.items) == 0 {
+			return nil
+		}
+		for _, item := range res.items {
+			if spec.Total > 0 && count >= spec.Total {
+				return nil
+			}
+			count++
+			if !yield(item, nil) {
+				return nil
+			}
+		}
 	}
 	return nil
 }
+
+// Collect returns the resources of the iterator or the first error
+func Collect[T any](seq iter.Seq2[T, error]) ([]T, error) {
+	items := make([]T, 0)
+	for item, err := range seq {
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
@@ -3,7 +3,10 @@ package activity_test
 import (
 	"context"
 	"errors"
+	"fmt"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 
@@ -114,3 +117,216 @@ func TestPaginate(t *testing.T) {
 		})
 	}
 }
+
+func TestIter(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var pages []activity.Pagination
+	page := func(_ context.Context, spec activity.Pagination) ([]int, error) {
+		pages = append(pages, spec)
+		if spec.Start > 3 {
+			return nil, nil
+		}
+		items := make([]int, spec.Count)
+		for i := range items {
+			items[i] = (spec.Start-1)*spec.Count + i
+		}
+		return items, nil
+	}
+
+	items, err := activity.Collect(activity.Iter(context.Background(), 10, page, activity.Pagination{}))
+	a.NoError(err)
+	a.Len(items, 30)
+	a.Len(pages, 4)
+
+	pages = nil
+	items, err = activity.Collect(activity.Iter(context.Background(), 10, page, activity.Pagination{Total: 15}))
+	a.NoError(err)
+	a.Len(items, 15)
+	a.Equal(14, items[14])
+	a.Len(pages, 2)
+
+	// no more pages are queried once the consumer stops
+	pages = nil
+	var n int
+	for item, ierr := range activity.Iter(context.Background(), 10, page, activity.Pagination{}) {
+		a.NoError(ierr)
+		if item == 12 {
+			break
+		}
+		n++
+	}
+	a.Equal(12, n)
+	a.Len(pages, 2)
+
+	items, err = activity.Collect(activity.Iter(context.Background(), 10,
+		func(_ context.Context, _ activity.Pagination) ([]int, error) {
+			return nil, errors.New("page error")
+		}, activity.Pagination{}))
+	a.EqualError(err, "page error")
+	a.Nil(items)
+
+	items, err = activity.Collect(activity.Iter(context.Background(), 10, page, activity.Pagination{Total: -1}))
+	a.Error(err)
+	a.Nil(items)
+}
+
+type cursorPaginator struct {
+	specs  []activity.Pagination
+	count  int
+	cursor string
+}
+
+func (p *cursorPaginator) PageSize() int {
+	return 5
+}
+
+func (p *cursorPaginator) Count() int {
+	return p.count
+}
+
+func (p *cursorPaginator) Cursor() string {
+	return p.cursor
+}
+
+func (p *cursorPaginator) Do(_ context.Context, spec activity.Pagination) (int, error) {
+	p.specs = append(p.specs, spec)
+	p.count += spec.Count
+	p.cursor = ""
+	if len(p.specs) < 3 {
+		p.cursor = fmt.Sprintf("cursor-%d", len(p.specs))
+	}
+	return spec.Count, nil
+}
+
+func TestPaginateCursor(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := &cursorPaginator{}
+	a.NoError(activity.Paginate(context.Background(), p, activity.Pagination{Cursor: "cursor-0"}))
+	a.Len(p.specs, 3)
+	for i, spec := range p.specs {
+		a.Equal(fmt.Sprintf("cursor-%d", i), spec.Cursor)
+		a.Equal(i*5, spec.Offset)
+		a.Equal(i+1, spec.Start)
+	}
+}
+
+func TestIterOffset(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var offsets []int
+	page := func(_ context.Context, spec activity.Pagination) ([]int, error) {
+		offsets = append(offsets, spec.Offset)
+		// fewer resources than requested does not end pagination
+		return make([]int, spec.Count-1), nil
+	}
+	items, err := activity.Collect(activity.Iter(context.Background(), 100, page,
+		activity.Pagination{Start: 2, Count: 10, Total: 20}))
+	a.NoError(err)
+	a.Len(items, 20)
+	a.Equal([]int{10, 19, 28}, offsets)
+}
+
+func TestIterCursor(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var cursors []string
+	page := func(_ context.Context, spec activity.Pagination) ([]string, string, error) {
+		cursors = append(cursors, spec.Cursor)
+		if len(cursors) == 3 {
+			return []string{"c"}, "", nil
+		}
+		return []string{"a", "b"}, fmt.Sprintf("next-%d", len(cursors)), nil
+	}
+	items, err := activity.Collect(activity.IterCursor(context.Background(), 2, page, activity.Pagination{}))
+	a.NoError(err)
+	a.Equal([]string{"a", "b", "a", "b", "c"}, items)
+	a.Equal([]string{"", "next-1", "next-2"}, cursors)
+
+	items, err = activity.Collect(activity.IterCursor(context.Background(), 2,
+		func(_ context.Context, _ activity.Pagination) ([]string, string, error) {
+			return nil, "", errors.New("cursor error")
+		}, activity.Pagination{}))
+	a.EqualError(err, "cursor error")
+	a.Nil(items)
+}
+
+func TestIterPrefetch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var mu sync.Mutex
+	var active, peak int
+	var starts []int
+	page := func(_ context.Context, spec activity.Pagination) ([]int, error) {
+		mu.Lock()
+		active++
+		peak = max(peak, active)
+		starts = append(starts, spec.Start)
+		mu.Unlock()
+		defer func() {
+			mu.Lock()
+			defer mu.Unlock()
+			active--
+		}()
+		a.Equal((spec.Start-1)*spec.Count, spec.Offset)
+		// later pages complete first
+		time.Sleep(time.Duration(10-spec.Start) * time.Millisecond)
+		if spec.Start > 6 {
+			return nil, nil
+		}
+		items := make([]int, spec.Count)
+		for i := range items {
+			items[i] = spec.Offset + i
+		}
+		return items, nil
+	}
+
+	items, err := activity.Collect(activity.Iter(context.Background(), 10, page, activity.Pagination{Prefetch: 2}))
+	a.NoError(err)
+	a.Len(items, 60)
+	for i, item := range items {
+		a.Equal(i, item)
+	}
+	a.LessOrEqual(peak, 3)
+	a.Greater(peak, 1)
+
+	// no pages beyond the total are queried
+	starts = nil
+	items, err = activity.Collect(activity.Iter(context.Background(), 10, page,
+		activity.Pagination{Total: 25, Prefetch: 5}))
+	a.NoError(err)
+	a.Len(items, 25)
+	a.ElementsMatch([]int{1, 2, 3}, starts)
+
+	// in-flight pages complete before the iterator returns
+	starts = nil
+	for item, ierr := range activity.Iter(context.Background(), 10, page, activity.Pagination{Prefetch: 3}) {
+		a.NoError(ierr)
+		if item == 5 {
+			break
+		}
+	}
+	a.Len(starts, 4)
+	a.Zero(active)
+
+	items, err = activity.Collect(activity.Iter(context.Background(), 10,
+		func(_ context.Context, spec activity.Pagination) ([]int, error) {
+			if spec.Start == 2 {
+				return nil, errors.New("page error")
+			}
+			return make([]int, spec.Count), nil
+		}, activity.Pagination{Prefetch: 2}))
+	a.EqualError(err, "page error")
+	a.Nil(items)
+
+	items, err = activity.Collect(activity.Iter(context.Background(), 10, page,
+		activity.Pagination{Total: -1, Prefetch: 2}))
+	a.Error(err)
+	a.Nil(items)
+}
@@ -0,0 +1,454 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// redacted replaces the value of secrets in recorded interactions
+const redacted = "REDACTED"
+
+// RecorderMode is the mode of a Recorder
+type RecorderMode int
+
+const (
+	// RecorderRecord forwards requests to the transport and records the interactions
+	RecorderRecord RecorderMode = iota
+	// RecorderReplay serves responses from the recorded interactions without network access
+	RecorderReplay
+)
+
+// RecordedRequest is a recorded request with all secrets redacted
+type RecordedRequest struct {
+	Method string      `json:"method"`
+	URL    string      `json:"url"`
+	Header http.Header `json:"header,omitempty"`
+	Body   string      `json:"body,omitempty"`
+	// Encoding is "base64" if the body is not valid UTF-8, empty otherwise
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// RecordedResponse is a recorded response with all secrets redacted
+type RecordedResponse struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+	// Encoding is "base64" if the body is not valid UTF-8, empty otherwise
+	Encoding string `json:"encoding,omitempty"`
+}
+
+// Interaction is a request and its response
+type Interaction struct {
+	Request  *RecordedRequest  `json:"request"`
+	Response *RecordedResponse `json:"response"`
+}
+
+// Cassette is the recorded interactions persisted in a file
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// A RecorderOption allows configuring a Recorder
+type RecorderOption func(r *Recorder)
+
+// WithRecorderTransport sets the transport used to execute requests when recording
+func WithRecorderTransport(t http.RoundTripper) RecorderOption {
+	return func(r *Recorder) {
+		if t != nil {
+			r.transport = t
+		}
+	}
+}
+
+// WithRecorderSecrets adds the names of query parameters, form fields, and JSON keys whose values are redacted
+func WithRecorderSecrets(names ...string) RecorderOption {
+	return func(r *Recorder) {
+		for _, name := range names {
+			r.secrets[strings.ToLower(name)] = true
+		}
+	}
+}
+
+// WithRecorderLenient matches requests on the method and path only when replaying
+//
+// By default the query and the JSON or form body must match as well and each interaction is
+// replayed once. Leniently matched interactions are replayed in order and the last match is
+// repeated once all matches are used.
+func WithRecorderLenient() RecorderOption {
+	return func(r *Recorder) {
+		r.lenient = true
+	}
+}
+
+// Recorder is an http.RoundTripper which records interactions to, or replays interactions from, a cassette file
+//
+// When recording the values of the secrets, by default `access_token`, `refresh_token`, `apikey`,
+// `auth_token`, `client_secret`, and `password`, are redacted from the query, JSON and form bodies,
+// and the Authorization header. Any other occurrence of a redacted value, for example in a multipart
+// or binary body or a header, is also redacted. The cassette is written after each interaction.
+type Recorder struct {
+	filename  string
+	mode      RecorderMode
+	transport http.RoundTripper
+	secrets   map[string]bool
+	lenient   bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	// values are the redacted values of the secrets
+	values map[string]bool
+	// used are the indexes of the replayed interactions
+	used map[int]bool
+}
+
+// NewRecorder returns a Recorder for the cassette file
+//
+// The cassette must exist for replaying and is replaced when recording.
+func NewRecorder(filename string, mode RecorderMode, opts ...RecorderOption) (*Recorder, error) {
+	r := &Recorder{
+		filename:  filename,
+		mode:      mode,
+		transport: http.DefaultTransport,
+		secrets:   make(map[string]bool),
+		cassette:  &Cassette{},
+		values:    make(map[string]bool),
+		used:      make(map[int]bool),
+	}
+	WithRecorderSecrets("access_token", "refresh_token", "apikey", "auth_token", "client_secret", "password")(r)
+	for _, opt := range opts {
+		opt(r)
+	}
+	switch mode {
+	case RecorderRecord:
+	case RecorderReplay:
+		data, err := os.ReadFile(filename)
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil, fmt.Errorf("cassette %s not found", filename)
+			}
+			return nil, err
+		}
+		if err = json.Unmarshal(data, r.cassette); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unknown recorder mode %d", mode)
+	}
+	return r, nil
+}
+
+// Interactions returns the interactions of the cassette
+func (r *Recorder) Interactions() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]*Interaction(nil), r.cassette.Interactions...)
+}
+
+// RoundTrip records or replays the request
+//
+// The body of the request is read and closed but the request is not modified.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := requestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.mode == RecorderReplay {
+		return r.replay(req, body)
+	}
+	return r.record(req, body)
+}
+
+func (r *Recorder) record(req *http.Request, body []byte) (*http.Response, error) {
+	// the request is cloned with a new body since a RoundTripper must not modify the request
+	out := req
+	if body != nil {
+		out = req.Clone(req.Context())
+		out.Body = io.NopCloser(bytes.NewReader(body))
+	}
+	res, err := r.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	data, err := io.ReadAll(res.Body)
+	if err = errors.Join(err, res.Body.Close()); err != nil {
+		return nil, err
+	}
+	res.Body = io.NopCloser(bytes.NewReader(data))
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	header := req.Header.Clone()
+	if auth := header.Get("Authorization"); auth != "" {
+		_, token, _ := strings.Cut(auth, " ")
+		r.values[token] = true
+		header.Set("Authorization", redacted)
+	}
+	if header.Get("Cookie") != "" {
+		header.Set("Cookie", redacted)
+	}
+	rec := &RecordedRequest{Method: req.Method, URL: r.scrubURL(req.URL), Header: header}
+	rec.Body, rec.Encoding = r.encode(req.Header.Get("Content-Type"), body)
+	resp := &RecordedResponse{StatusCode: res.StatusCode, Header: res.Header.Clone()}
+	if resp.Header.Get("Set-Cookie") != "" {
+		resp.Header.Set("Set-Cookie", redacted)
+	}
+	resp.Body, resp.Encoding = r.encode(res.Header.Get("Content-Type"), data)
+	r.cassette.Interactions = append(r.cassette.Interactions, &Interaction{Request: rec, Response: resp})
+	// values learned from this interaction may occur in earlier interactions
+	for _, x := range r.cassette.Interactions {
+		x.Request.URL = r.redact(x.Request.URL)
+		x.Request.Body = r.redactBody(x.Request.Body, x.Request.Encoding)
+		r.redactHeader(x.Request.Header)
+		x.Response.Body = r.redactBody(x.Response.Body, x.Response.Encoding)
+		r.redactHeader(x.Response.Header)
+	}
+	if err = writeJSON(r.filename, r.cassette); err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (r *Recorder) replay(req *http.Request, body []byte) (*http.Response, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := r.scrubURL(req.URL)
+	b, _ := encodeBody(r.scrubBody(req.Header.Get("Content-Type"), body))
+	last := -1
+	for i, x := range r.cassette.Interactions {
+		if !r.match(req, u, b, x.Request) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			r.used[i] = true
+			return response(req, x.Response)
+		}
+	}
+	if r.lenient && last >= 0 {
+		return response(req, r.cassette.Interactions[last].Response)
+	}
+	return nil, fmt.Errorf("no recorded interaction for %s %s", req.Method, u)
+}
+
+// match returns true if the scrubbed request matches the recorded request
+func (r *Recorder) match(req *http.Request, u, body string, rec *RecordedRequest) bool {
+	if req.Method != rec.Method {
+		return false
+	}
+	if r.lenient {
+		x, err := url.Parse(rec.URL)
+		return err == nil && x.Path == req.URL.Path
+	}
+	if u != rec.URL {
+		return false
+	}
+	// multipart and binary bodies contain random boundaries or opaque content so are not compared
+	switch mediaType(req.Header.Get("Content-Type")) {
+	case "application/json", "application/x-www-form-urlencoded":
+		return body == rec.Body
+	default:
+		return true
+	}
+}
+
+// encode returns the body with the secrets redacted as a string and its encoding
+func (r *Recorder) encode(contentType string, body []byte) (string, string) {
+	return encodeBody([]byte(r.redact(string(r.scrubBody(contentType, body)))))
+}
+
+// scrubURL returns the url with the secrets of the query redacted
+func (r *Recorder) scrubURL(u *url.URL) string {
+	x := *u
+	q := x.Query()
+	if r.scrubValues(q) {
+		x.RawQuery = q.Encode()
+	}
+	return x.String()
+}
+
+// scrubValues redacts the secrets of the values returning true if any were redacted
+func (r *Recorder) scrubValues(values url.Values) bool {
+	var scrubbed bool
+	for k, v := range values {
+		if !r.secrets[strings.ToLower(k)] {
+			continue
+		}
+		for i := range v {
+			r.values[v[i]] = true
+			v[i] = redacted
+		}
+		scrubbed = true
+	}
+	return scrubbed
+}
+
+// scrubJSON redacts the values of the secrets in the decoded JSON returning true if any were redacted
+func (r *Recorder) scrubJSON(v any) bool {
+	var scrubbed bool
+	switch x := v.(type) {
+	case map[string]any:
+		for k, val := range x {
+			if r.secrets[strings.ToLower(k)] {
+				if s, ok := val.(string); ok {
+					r.values[s] = true
+				}
+				x[k] = redacted
+				scrubbed = true
+				continue
+			}
+			scrubbed = r.scrubJSON(val) || scrubbed
+		}
+	case []any:
+		for _, val := range x {
+			scrubbed = r.scrubJSON(val) || scrubbed
+		}
+	}
+	return scrubbed
+}
+
+// scrubBody redacts the secrets of JSON and form bodies
+func (r *Recorder) scrubBody(contentType string, body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	switch mediaType(contentType) {
+	case "application/json":
+		var v any
+		dec := json.NewDecoder(bytes.NewReader(body))
+		// numbers are preserved as is rather than converted to floats
+		dec.UseNumber()
+		if err := dec.Decode(&v); err != nil || !r.scrubJSON(v) {
+			return body
+		}
+		if b, err := json.Marshal(v); err == nil {
+			return b
+		}
+	case "application/x-www-form-urlencoded":
+		q, err := url.ParseQuery(string(body))
+		if err != nil {
+			return body
+		}
+		r.scrubValues(q)
+		return []byte(q.Encode())
+	case "multipart/form-data":
+		// the fields are not rewritten but their values are redacted wherever they occur
+		r.scrubMultipart(contentType, body)
+	}
+	return body
+}
+
+// scrubMultipart collects the values of the secret fields of the multipart body
+func (r *Recorder) scrubMultipart(contentType string, body []byte) {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return
+	}
+	form, err := multipart.NewReader(bytes.NewReader(body), params["boundary"]).ReadForm(int64(len(body)))
+	if err != nil {
+		return
+	}
+	defer form.RemoveAll()
+	r.scrubValues(form.Value)
+}
+
+// redact replaces all the occurrences of the redacted values
+func (r *Recorder) redact(s string) string {
+	for v := range r.values {
+		// short values are likely to occur by chance
+		if len(v) >= 8 && v != redacted {
+			s = strings.ReplaceAll(s, v, redacted)
+			s = strings.ReplaceAll(s, url.QueryEscape(v), redacted)
+		}
+	}
+	return s
+}
+
+// redactBody replaces all the occurrences of the redacted values in the body with the encoding
+func (r *Recorder) redactBody(body, encoding string) string {
+	if encoding != "base64" {
+		return r.redact(body)
+	}
+	data, err := base64.StdEncoding.DecodeString(body)
+	if err != nil {
+		return body
+	}
+	return base64.StdEncoding.EncodeToString([]byte(r.redact(string(data))))
+}
+
+// redactHeader replaces all the occurrences of the redacted values in the header values
+func (r *Recorder) redactHeader(header http.Header) {
+	for _, v := range header {
+		for i := range v {
+			v[i] = r.redact(v[i])
+		}
+	}
+}
+
+// requestBody reads and closes the body of the request
+func requestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	if err = errors.Join(err, req.Body.Close()); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// response returns an http.Response for the recorded response
+func response(req *http.Request, rec *RecordedResponse) (*http.Response, error) {
+	body := []byte(rec.Body)
+	if rec.Encoding == "base64" {
+		var err error
+		if body, err = base64.StdEncoding.DecodeString(rec.Body); err != nil {
+			return nil, err
+		}
+	}
+	header := rec.Header.Clone()
+	if header == nil {
+		header = make(http.Header)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", rec.StatusCode, http.StatusText(rec.StatusCode)),
+		StatusCode:    rec.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
+
+// encodeBody returns the body as a string and its encoding
+func encodeBody(body []byte) (string, string) {
+	if utf8.Valid(body) {
+		return string(body), ""
+	}
+	return base64.StdEncoding.EncodeToString(body), "base64"
+}
+
+func mediaType(contentType string) string {
+	mt, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return mt
+}
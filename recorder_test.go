@@ -0,0 +1,239 @@
+package activity_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func newRecorderServer(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /athlete", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"name": "athlete", "page": r.URL.Query().Get("page")})
+	})
+	mux.HandleFunc("POST /oauth/token", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"access_token": "response-secret", "expires_in": 3600})
+	})
+	mux.HandleFunc("POST /trips", func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]any{"echo": body["auth_token"]})
+	})
+	mux.HandleFunc("POST /uploads", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/octet-stream")
+		_, _ = w.Write([]byte{0xff, 0xfe, 0x00, 0x01})
+	})
+	svr := httptest.NewServer(mux)
+	t.Cleanup(svr.Close)
+	return svr
+}
+
+// exercise sends a request of each kind returning the response bodies
+func exercise(t *testing.T, client *http.Client, baseURL string) []string {
+	a := assert.New(t)
+	var bodies []string
+	do := func(req *http.Request, err error) {
+		a.NoError(err)
+		res, err := client.Do(req)
+		a.NoError(err)
+		defer res.Body.Close()
+		data, err := io.ReadAll(res.Body)
+		a.NoError(err)
+		bodies = append(bodies, string(data))
+	}
+
+	req, err := http.NewRequest(http.MethodGet, baseURL+"/athlete?page=1&access_token=query-secret", nil)
+	req.Header.Set("Authorization", "Bearer bearer-secret")
+	do(req, err)
+
+	form := url.Values{"client_secret": {"form-secret"}, "grant_type": {"refresh_token"}}
+	req, err = http.NewRequest(http.MethodPost, baseURL+"/oauth/token", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	do(req, err)
+
+	req, err = http.NewRequest(http.MethodPost, baseURL+"/trips", strings.NewReader(`{"auth_token":"json-secret"}`))
+	req.Header.Set("Content-Type", "application/json")
+	do(req, err)
+
+	var buf bytes.Buffer
+	mw := multipart.NewWriter(&buf)
+	a.NoError(mw.WriteField("apikey", "multipart-secret"))
+	fw, err := mw.CreateFormFile("file", "ride.fit")
+	a.NoError(err)
+	_, err = fw.Write([]byte{0xff, 0x00, 'm', 'u', 'l', 't', 'i', 'p', 'a', 'r', 't', '-', 's', 'e', 'c', 'r', 'e', 't'})
+	a.NoError(err)
+	a.NoError(mw.Close())
+	req, err = http.NewRequest(http.MethodPost, baseURL+"/uploads", &buf)
+	req.Header.Set("Content-Type", mw.FormDataContentType())
+	do(req, err)
+
+	return bodies
+}
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := newRecorderServer(t)
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+
+	rec, err := activity.NewRecorder(cassette, activity.RecorderRecord)
+	a.NoError(err)
+	recorded := exercise(t, &http.Client{Transport: rec}, svr.URL)
+	a.Len(rec.Interactions(), 4)
+	// the responses are returned unmodified when recording
+	a.Contains(recorded[1], "response-secret")
+	a.Contains(recorded[2], "json-secret")
+
+	data, err := os.ReadFile(cassette)
+	a.NoError(err)
+	for _, secret := range []string{
+		"query-secret", "bearer-secret", "form-secret", "json-secret", "multipart-secret", "response-secret"} {
+		a.NotContains(string(data), secret)
+	}
+	a.Contains(string(data), "REDACTED")
+
+	// replay without the server
+	svr.Close()
+	rep, err := activity.NewRecorder(cassette, activity.RecorderReplay)
+	a.NoError(err)
+	client := &http.Client{Transport: rep}
+	replayed := exercise(t, client, svr.URL)
+	a.Equal(recorded[0], replayed[0])
+	a.Equal([]byte{0xff, 0xfe, 0x00, 0x01}, []byte(replayed[3]))
+	a.Contains(replayed[1], "REDACTED")
+
+	// each interaction is replayed once
+	res, err := client.Get(svr.URL + "/athlete?page=1")
+	a.ErrorContains(err, "no recorded interaction for GET")
+	a.Nil(res)
+}
+
+func TestRecorderMatching(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := newRecorderServer(t)
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := activity.NewRecorder(cassette, activity.RecorderRecord)
+	a.NoError(err)
+	exercise(t, &http.Client{Transport: rec}, svr.URL)
+
+	get := func(client *http.Client, uri string) (string, error) {
+		res, gerr := client.Get(svr.URL + uri)
+		if gerr != nil {
+			return "", gerr
+		}
+		defer res.Body.Close()
+		data, gerr := io.ReadAll(res.Body)
+		return string(data), gerr
+	}
+
+	// strict matching compares the query with secrets redacted
+	rep, err := activity.NewRecorder(cassette, activity.RecorderReplay)
+	a.NoError(err)
+	client := &http.Client{Transport: rep}
+	_, err = get(client, "/athlete?page=2&access_token=other")
+	a.Error(err)
+	body, err := get(client, "/athlete?page=1&access_token=other")
+	a.NoError(err)
+	a.Contains(body, `"page":"1"`)
+
+	// lenient matching compares the method and path and repeats the last match
+	rep, err = activity.NewRecorder(cassette, activity.RecorderReplay, activity.WithRecorderLenient())
+	a.NoError(err)
+	client = &http.Client{Transport: rep}
+	for range 2 {
+		body, err = get(client, "/athlete?page=2")
+		a.NoError(err)
+		a.Contains(body, `"page":"1"`)
+	}
+	_, err = get(client, "/routes")
+	a.Error(err)
+
+	// strict matching compares the json body
+	rep, err = activity.NewRecorder(cassette, activity.RecorderReplay)
+	a.NoError(err)
+	res, err := (&http.Client{Transport: rep}).Post(
+		svr.URL+"/trips", "application/json", strings.NewReader(`{"auth_token":"x","other":1}`))
+	a.Error(err)
+	a.Nil(res)
+	res, err = (&http.Client{Transport: rep}).Post(
+		svr.URL+"/trips", "application/json", strings.NewReader(`{"auth_token":"x"}`))
+	a.NoError(err)
+	a.NoError(res.Body.Close())
+	a.Equal(http.StatusOK, res.StatusCode)
+
+	_, err = activity.NewRecorder(filepath.Join(t.TempDir(), "missing.json"), activity.RecorderReplay)
+	a.ErrorContains(err, "not found")
+}
+
+func TestRecorderSecrets(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := newRecorderServer(t)
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := activity.NewRecorder(cassette, activity.RecorderRecord, activity.WithRecorderSecrets("Page"))
+	a.NoError(err)
+	res, err := (&http.Client{Transport: rec}).Get(svr.URL + "/athlete?page=12345")
+	a.NoError(err)
+	a.NoError(res.Body.Close())
+	data, err := os.ReadFile(cassette)
+	a.NoError(err)
+	a.NotContains(string(data), "12345")
+}
+
+func TestRecorderRedactsEarlierInteractions(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := newRecorderServer(t)
+	cassette := filepath.Join(t.TempDir(), "cassette.json")
+	rec, err := activity.NewRecorder(cassette, activity.RecorderRecord)
+	a.NoError(err)
+
+	// the secret occurs in a header and a binary body before it is known to be a secret
+	body := io.NopCloser(bytes.NewReader(append([]byte{0xff, 0xfe}, "learned-secret"...)))
+	req, err := http.NewRequest(http.MethodPost, svr.URL+"/uploads", body)
+	a.NoError(err)
+	req.Header.Set("Content-Type", "application/octet-stream")
+	req.Header.Set("X-Session", "learned-secret")
+	res, err := rec.RoundTrip(req)
+	a.NoError(err)
+	a.NoError(res.Body.Close())
+	// the request is not modified
+	a.Equal(body, req.Body)
+
+	res, err = (&http.Client{Transport: rec}).Get(svr.URL + "/athlete?access_token=learned-secret")
+	a.NoError(err)
+	a.NoError(res.Body.Close())
+
+	interactions := rec.Interactions()
+	a.Len(interactions, 2)
+	x := interactions[0].Request
+	a.Equal("base64", x.Encoding)
+	data, err := base64.StdEncoding.DecodeString(x.Body)
+	a.NoError(err)
+	a.Equal(append([]byte{0xff, 0xfe}, "REDACTED"...), data)
+	a.Equal("REDACTED", x.Header.Get("X-Session"))
+	data, err = os.ReadFile(cassette)
+	a.NoError(err)
+	a.NotContains(string(data), "learned-secret")
+}
@@ -0,0 +1,211 @@
+package activity
+
+//go:generate stringer -type=Capability -linecomment -output=registry_string.go
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Capability is a feature supported by a provider
+type Capability int
+
+const (
+	// CapabilityUpload is a provider which uploads activity files
+	CapabilityUpload Capability = iota // upload
+	// CapabilityExport is a provider which exports activity files
+	CapabilityExport // export
+	// CapabilityList is a provider which lists activities
+	CapabilityList // list
+	// CapabilityRoutes is a provider which manages routes
+	CapabilityRoutes // routes
+	// CapabilityWebhooks is a provider which sends webhook events
+	CapabilityWebhooks // webhooks
+)
+
+// MarshalJSON converts a Capability enum to a string representation
+func (c Capability) MarshalJSON() ([]byte, error) {
+	return json.Marshal(c.String())
+}
+
+// ProviderConfig is the configuration of a provider client
+//
+// Each provider uses only the fields applicable to it.
+type ProviderConfig struct {
+	// ClientID of the application
+	ClientID string `json:"client_id,omitempty" yaml:"client_id,omitempty"`
+	// ClientSecret of the application
+	ClientSecret string `json:"client_secret,omitempty" yaml:"client_secret,omitempty"`
+	// AccessToken of the authenticated user
+	AccessToken string `json:"access_token,omitempty" yaml:"access_token,omitempty"`
+	// RefreshToken of the authenticated user
+	RefreshToken string `json:"refresh_token,omitempty" yaml:"refresh_token,omitempty"`
+	// Expiry of the access token, zero if unknown
+	Expiry time.Time `json:"expiry,omitzero" yaml:"expiry,omitempty"`
+	// Username of the authenticated user for providers which acquire tokens with a password
+	Username string `json:"username,omitempty" yaml:"username,omitempty"`
+	// Password of the authenticated user for providers which acquire tokens with a password
+	Password string `json:"password,omitempty" yaml:"password,omitempty"`
+	// TokenFile is the path of a FileTokenStore which takes precedence over the token credentials
+	TokenFile string `json:"token_file,omitempty" yaml:"token_file,omitempty"`
+	// BaseURL of the provider api, the default if empty
+	BaseURL string `json:"base_url,omitempty" yaml:"base_url,omitempty"`
+}
+
+// Config is the configuration of the provider clients keyed by provider name
+type Config struct {
+	Providers map[string]ProviderConfig `json:"providers" yaml:"providers"`
+}
+
+// LoadConfig reads the configuration from a YAML or JSON file based on the extension
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	config := new(Config)
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".json":
+		err = json.Unmarshal(data, config)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(data, config)
+	default:
+		return nil, fmt.Errorf("unsupported config format: %q", ext)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Constructor creates a provider client from the configuration
+type Constructor func(ctx context.Context, config ProviderConfig) (any, error)
+
+// Provider describes a provider and how to construct its client
+type Provider struct {
+	// Name of the provider
+	Name string `json:"name"`
+	// Capabilities of the provider
+	Capabilities []Capability `json:"capabilities"`
+	// New creates a client for the provider
+	New Constructor `json:"-"`
+}
+
+// Supports returns true if the provider has the capability
+func (p *Provider) Supports(capability Capability) bool {
+	return slices.Contains(p.Capabilities, capability)
+}
+
+// Registry of the available providers
+type Registry struct {
+	mu        sync.RWMutex
+	providers map[string]*Provider
+}
+
+// NewRegistry returns a Registry of the providers
+func NewRegistry(providers ...*Provider) (*Registry, error) {
+	r := &Registry{providers: make(map[string]*Provider)}
+	for _, provider := range providers {
+		if err := r.Register(provider); err != nil {
+			return nil, err
+		}
+	}
+	return r, nil
+}
+
+// Register adds the provider to the registry
+func (r *Registry) Register(provider *Provider) error {
+	if provider == nil || provider.Name == "" || provider.New == nil {
+		return errors.New("provider requires a name and constructor")
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.providers[provider.Name]; ok {
+		return fmt.Errorf("provider %q already registered", provider.Name)
+	}
+	r.providers[provider.Name] = provider
+	return nil
+}
+
+// Provider returns the provider registered with the name
+func (r *Registry) Provider(name string) (*Provider, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	provider, ok := r.providers[name]
+	if !ok {
+		return nil, &Error{Kind: ErrNotFound, Err: fmt.Errorf("provider %q not registered", name)}
+	}
+	return provider, nil
+}
+
+// Providers returns the registered providers sorted by name
+func (r *Registry) Providers() []*Provider {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	providers := make([]*Provider, 0, len(r.providers))
+	for _, provider := range r.providers {
+		providers = append(providers, provider)
+	}
+	slices.SortFunc(providers, func(a, b *Provider) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return providers
+}
+
+// Clients creates a client for each provider in the configuration
+//
+// An error is returned if the configuration includes an unregistered provider.
+func (r *Registry) Clients(ctx context.Context, config *Config) (Clients, error) {
+	clients := make(Clients)
+	for name, cfg := range config.Providers {
+		provider, err := r.Provider(name)
+		if err != nil {
+			return nil, err
+		}
+		client, err := provider.New(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		clients[name] = client
+	}
+	return clients, nil
+}
+
+// Clients are provider clients keyed by provider name
+type Clients map[string]any
+
+// Uploaders returns the Uploader of each client which supports uploading
+func (c Clients) Uploaders() map[string]Uploader {
+	return collect(c, func(client interface{ Uploader() Uploader }) Uploader { return client.Uploader() })
+}
+
+// Exporters returns the Exporter of each client which supports exporting
+func (c Clients) Exporters() map[string]Exporter {
+	return collect(c, func(client interface{ Exporter() Exporter }) Exporter { return client.Exporter() })
+}
+
+// Listers returns the Lister of each client which supports listing activities
+func (c Clients) Listers() map[string]Lister {
+	return collect(c, func(client interface{ Lister() Lister }) Lister { return client.Lister() })
+}
+
+// collect returns the value of f for each client implementing T
+func collect[T, V any](clients Clients, f func(T) V) map[string]V {
+	values := make(map[string]V)
+	for name, client := range clients {
+		if x, ok := client.(T); ok {
+			values[name] = f(x)
+		}
+	}
+	return values
+}
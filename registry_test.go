@@ -0,0 +1,149 @@
+package activity_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+type uploaderClient struct {
+	config activity.ProviderConfig
+}
+
+func (c *uploaderClient) Uploader() activity.Uploader {
+	return nil
+}
+
+type exporterClient struct{}
+
+func (c *exporterClient) Exporter() activity.Exporter {
+	return nil
+}
+
+func (c *exporterClient) Lister() activity.Lister {
+	return nil
+}
+
+func newRegistry(t *testing.T) *activity.Registry {
+	registry, err := activity.NewRegistry(
+		&activity.Provider{
+			Name:         "up",
+			Capabilities: []activity.Capability{activity.CapabilityUpload},
+			New: func(_ context.Context, config activity.ProviderConfig) (any, error) {
+				return &uploaderClient{config: config}, nil
+			},
+		},
+		&activity.Provider{
+			Name:         "down",
+			Capabilities: []activity.Capability{activity.CapabilityExport, activity.CapabilityList},
+			New: func(_ context.Context, config activity.ProviderConfig) (any, error) {
+				if config.AccessToken == "" {
+					return nil, errors.New("missing access token")
+				}
+				return &exporterClient{}, nil
+			},
+		},
+	)
+	assert.NoError(t, err)
+	return registry
+}
+
+func TestRegistry(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	registry := newRegistry(t)
+	providers := registry.Providers()
+	a.Len(providers, 2)
+	a.Equal("down", providers[0].Name)
+	a.Equal("up", providers[1].Name)
+	a.True(providers[0].Supports(activity.CapabilityList))
+	a.False(providers[0].Supports(activity.CapabilityUpload))
+
+	provider, err := registry.Provider("up")
+	a.NoError(err)
+	a.Equal("up", provider.Name)
+	provider, err = registry.Provider("sideways")
+	a.Nil(provider)
+	a.ErrorIs(err, activity.ErrNotFound)
+
+	a.Error(registry.Register(&activity.Provider{Name: "up", New: providers[1].New}))
+	a.Error(registry.Register(&activity.Provider{Name: "sideways"}))
+	a.Error(registry.Register(nil))
+
+	data, err := json.Marshal(providers[0])
+	a.NoError(err)
+	a.JSONEq(`{"name":"down","capabilities":["export","list"]}`, string(data))
+}
+
+func TestRegistryClients(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	registry := newRegistry(t)
+	clients, err := registry.Clients(context.TODO(), &activity.Config{
+		Providers: map[string]activity.ProviderConfig{
+			"up":   {ClientID: "client"},
+			"down": {AccessToken: "token"},
+		},
+	})
+	a.NoError(err)
+	a.Len(clients, 2)
+	a.Equal("client", clients["up"].(*uploaderClient).config.ClientID)
+
+	uploaders := clients.Uploaders()
+	a.Len(uploaders, 1)
+	a.Contains(uploaders, "up")
+	a.Len(clients.Exporters(), 1)
+	a.Contains(clients.Exporters(), "down")
+	a.Len(clients.Listers(), 1)
+
+	clients, err = registry.Clients(context.TODO(), &activity.Config{
+		Providers: map[string]activity.ProviderConfig{"down": {}},
+	})
+	a.Nil(clients)
+	a.EqualError(err, "down: missing access token")
+
+	clients, err = registry.Clients(context.TODO(), &activity.Config{
+		Providers: map[string]activity.ProviderConfig{"sideways": {}},
+	})
+	a.Nil(clients)
+	a.ErrorIs(err, activity.ErrNotFound)
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	for _, path := range []string{"testdata/config.yaml", "testdata/config.json"} {
+		config, err := activity.LoadConfig(path)
+		a.NoError(err, path)
+		a.Len(config.Providers, 2)
+		strava := config.Providers["strava"]
+		a.Equal("12345", strava.ClientID)
+		a.Equal("strava-refresh", strava.RefreshToken)
+		a.True(time.Date(2021, time.March, 7, 13, 22, 5, 0, time.UTC).Equal(strava.Expiry))
+		zwift := config.Providers["zwift"]
+		a.Equal("barney@example.com", zwift.Username)
+		a.Equal("/tmp/zwift.json", zwift.TokenFile)
+		a.Empty(zwift.ClientID)
+	}
+
+	config, err := activity.LoadConfig("testdata/missing.yaml")
+	a.Nil(config)
+	a.Error(err)
+
+	path := filepath.Join(t.TempDir(), "config.toml")
+	a.NoError(os.WriteFile(path, []byte("providers = {}"), 0o600))
+	config, err = activity.LoadConfig(path)
+	a.Nil(config)
+	a.ErrorContains(err, "unsupported config format")
+}
@@ -0,0 +1,250 @@
+package activity
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	retryRetries = 3
+	retryBackoff = time.Second
+	retryMaxWait = 15 * time.Minute
+)
+
+// RateLimit is the limit and usage of requests within a window
+type RateLimit struct {
+	// Window is the duration of the window
+	Window time.Duration `json:"window"`
+	// Limit is the number of requests allowed within the window
+	Limit int `json:"limit"`
+	// Usage is the number of requests made within the window
+	Usage int `json:"usage"`
+	// Reset is the time at which the window ends and usage resets
+	Reset time.Time `json:"reset"`
+}
+
+// Exceeded returns true if no more requests are allowed within the window
+func (r RateLimit) Exceeded() bool {
+	return r.Usage >= r.Limit
+}
+
+// RateLimitParser returns the rate limits reported in the headers of a response or nil if none
+type RateLimitParser func(header http.Header, now time.Time) []RateLimit
+
+// RetryTransport retries failed requests and enforces the rate limits reported by a provider
+//
+// Idempotent requests are retried when the response is rate limited or a server error, waiting
+// for the duration specified by the Retry-After header, the reset of an exceeded rate limit,
+// or an exponential backoff. Before each request the rate limits are checked and, if any is
+// exhausted, the request waits for the window to reset. If a wait would exceed MaxWait the
+// request fails with a RateLimitError or, for retries, the failed response is returned.
+type RetryTransport struct {
+	// Transport is the underlying transport, http.DefaultTransport if nil
+	Transport http.RoundTripper
+	// Parser of the rate limits reported by the provider, nil if the provider does not report rate limits
+	Parser RateLimitParser
+	// Retries is the max number of retries of a request
+	Retries int
+	// Backoff is the initial duration between retries if the response does not specify one
+	Backoff time.Duration
+	// MaxWait is the max duration to wait for a retry or the reset of a rate limit
+	MaxWait time.Duration
+
+	mu     sync.Mutex
+	limits []RateLimit
+}
+
+// Usage returns the current usage of the rate limits
+func (t *RetryTransport) Usage() []RateLimit {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.roll(time.Now())
+	return slices.Clone(t.limits)
+}
+
+// RoundTrip executes the request within the rate limits retrying failures
+func (t *RetryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	retryable := idempotent(req)
+	for attempt := 0; ; attempt++ {
+		if err := t.reserve(req); err != nil {
+			return nil, err
+		}
+		r := req
+		if attempt > 0 && req.Body != nil && req.Body != http.NoBody {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			r = req.Clone(ctx)
+			r.Body = body
+		}
+		res, err := t.transport().RoundTrip(r)
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		t.update(res.Header, now)
+		if !retryable || attempt >= t.retries() || !retryStatus(res.StatusCode) {
+			return res, nil
+		}
+		wait := t.delay(res, attempt, now)
+		if wait > t.maxWait() {
+			return res, nil
+		}
+		// the body is drained so the connection can be reused
+		_, _ = io.Copy(io.Discard, res.Body)
+		res.Body.Close()
+		if err = sleep(ctx, wait); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// reserve waits until a request is allowed within all rate limits and counts the request
+func (t *RetryTransport) reserve(req *http.Request) error {
+	for {
+		t.mu.Lock()
+		now := time.Now()
+		t.roll(now)
+		reset := t.reset()
+		if reset.IsZero() {
+			for i := range t.limits {
+				t.limits[i].Usage++
+			}
+			t.mu.Unlock()
+			return nil
+		}
+		t.mu.Unlock()
+		wait := reset.Sub(now)
+		if wait > t.maxWait() {
+			return &RateLimitError{Reset: reset, Err: errors.New("rate limit exceeded")}
+		}
+		if err := sleep(req.Context(), wait); err != nil {
+			return err
+		}
+	}
+}
+
+// roll resets the usage of the rate limits with windows which have ended
+func (t *RetryTransport) roll(now time.Time) {
+	for i := range t.limits {
+		limit := &t.limits[i]
+		if limit.Window <= 0 || limit.Reset.IsZero() || now.Before(limit.Reset) {
+			continue
+		}
+		limit.Usage = 0
+		for !now.Before(limit.Reset) {
+			limit.Reset = limit.Reset.Add(limit.Window)
+		}
+	}
+}
+
+// reset returns the latest reset of the exceeded rate limits or zero if none are exceeded
+func (t *RetryTransport) reset() time.Time {
+	var reset time.Time
+	for _, limit := range t.limits {
+		if limit.Exceeded() && limit.Reset.After(reset) {
+			reset = limit.Reset
+		}
+	}
+	return reset
+}
+
+// update replaces the rate limits with those reported in the response
+func (t *RetryTransport) update(header http.Header, now time.Time) {
+	if t.Parser == nil {
+		return
+	}
+	limits := t.Parser(header, now)
+	if len(limits) == 0 {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.limits = limits
+}
+
+// delay returns the duration to wait before retrying the request
+func (t *RetryTransport) delay(res *http.Response, attempt int, now time.Time) time.Duration {
+	if after := RetryAfter(res.Header, now); !after.IsZero() {
+		return max(after.Sub(now), 0)
+	}
+	if res.StatusCode == http.StatusTooManyRequests {
+		t.mu.Lock()
+		defer t.mu.Unlock()
+		if reset := t.reset(); !reset.IsZero() {
+			return max(reset.Sub(now), 0)
+		}
+	}
+	return t.backoff() << attempt
+}
+
+func (t *RetryTransport) transport() http.RoundTripper {
+	if t.Transport == nil {
+		return http.DefaultTransport
+	}
+	return t.Transport
+}
+
+func (t *RetryTransport) retries() int {
+	if t.Retries <= 0 {
+		return retryRetries
+	}
+	return t.Retries
+}
+
+func (t *RetryTransport) backoff() time.Duration {
+	if t.Backoff <= 0 {
+		return retryBackoff
+	}
+	return t.Backoff
+}
+
+func (t *RetryTransport) maxWait() time.Duration {
+	if t.MaxWait <= 0 {
+		return retryMaxWait
+	}
+	return t.MaxWait
+}
+
+// idempotent returns true if the request can be safely retried
+func idempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace, http.MethodPut, http.MethodDelete:
+		return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+	default:
+		return false
+	}
+}
+
+// retryStatus returns true if the status of the response is transient
+func retryStatus(code int) bool {
+	switch code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	default:
+		return false
+	}
+}
+
+// sleep waits for the duration or until the context is done
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,181 @@
+package activity_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func newRetryServer(statuses ...int) (*httptest.Server, *atomic.Int32) {
+	var n atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		i := int(n.Add(1)) - 1
+		status := http.StatusOK
+		if i < len(statuses) {
+			status = statuses[i]
+		}
+		if status == http.StatusServiceUnavailable {
+			w.Header().Set("Retry-After", "0")
+		}
+		w.WriteHeader(status)
+	}))
+	return svr, &n
+}
+
+func TestRetryTransport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name     string
+		method   string
+		statuses []int
+		status   int
+		requests int32
+	}{
+		{
+			name:     "success",
+			method:   http.MethodGet,
+			status:   http.StatusOK,
+			requests: 1,
+		},
+		{
+			name:     "retry after",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			status:   http.StatusOK,
+			requests: 3,
+		},
+		{
+			name:     "backoff",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway},
+			status:   http.StatusOK,
+			requests: 2,
+		},
+		{
+			name:     "retries exhausted",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusBadGateway, http.StatusBadGateway, http.StatusBadGateway},
+			status:   http.StatusBadGateway,
+			requests: 3,
+		},
+		{
+			name:     "not retryable status",
+			method:   http.MethodGet,
+			statuses: []int{http.StatusNotFound},
+			status:   http.StatusNotFound,
+			requests: 1,
+		},
+		{
+			name:     "not idempotent",
+			method:   http.MethodPost,
+			statuses: []int{http.StatusServiceUnavailable},
+			status:   http.StatusServiceUnavailable,
+			requests: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svr, n := newRetryServer(tt.statuses...)
+			defer svr.Close()
+			client := &http.Client{Transport: &activity.RetryTransport{Retries: 2, Backoff: time.Millisecond}}
+			req, err := http.NewRequestWithContext(context.TODO(), tt.method, svr.URL, strings.NewReader("body"))
+			a.NoError(err)
+			res, err := client.Do(req)
+			a.NoError(err)
+			defer res.Body.Close()
+			a.Equal(tt.status, res.StatusCode)
+			a.Equal(tt.requests, n.Load())
+		})
+	}
+}
+
+func TestRetryTransportMaxWait(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "3600")
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer svr.Close()
+	client := &http.Client{Transport: &activity.RetryTransport{MaxWait: time.Minute}}
+	req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, svr.URL, http.NoBody)
+	a.NoError(err)
+	res, err := client.Do(req)
+	a.NoError(err)
+	defer res.Body.Close()
+	a.Equal(http.StatusTooManyRequests, res.StatusCode)
+}
+
+func TestRetryTransportRateLimits(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var n atomic.Int32
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		n.Add(1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer svr.Close()
+
+	reset := time.Now().Add(time.Hour)
+	transport := &activity.RetryTransport{
+		MaxWait: time.Minute,
+		Parser: func(http.Header, time.Time) []activity.RateLimit {
+			return []activity.RateLimit{{Window: time.Hour, Limit: 2, Usage: int(n.Load()), Reset: reset}}
+		},
+	}
+	client := &http.Client{Transport: transport}
+	a.Empty(transport.Usage())
+
+	for i := range 3 {
+		req, err := http.NewRequestWithContext(context.TODO(), http.MethodGet, svr.URL, http.NoBody)
+		a.NoError(err)
+		res, err := client.Do(req)
+		if i < 2 {
+			a.NoError(err)
+			a.NoError(res.Body.Close())
+			continue
+		}
+		// the limit is exhausted and the reset exceeds the max wait
+		a.Nil(res)
+		a.ErrorIs(err, activity.ErrRateLimited)
+		var rle *activity.RateLimitError
+		a.ErrorAs(err, &rle)
+		a.True(reset.Equal(rle.Reset))
+	}
+	a.Equal(int32(2), n.Load())
+
+	usage := transport.Usage()
+	a.Len(usage, 1)
+	a.Equal(2, usage[0].Usage)
+	a.True(usage[0].Exceeded())
+}
+
+func TestRetryTransportCanceled(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, n := newRetryServer(http.StatusBadGateway, http.StatusBadGateway)
+	defer svr.Close()
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	client := &http.Client{Transport: &activity.RetryTransport{Backoff: time.Hour, MaxWait: 2 * time.Hour}}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, svr.URL, http.NoBody)
+	a.NoError(err)
+	res, err := client.Do(req) //nolint:bodyclose // the response is nil
+	a.Nil(res)
+	a.ErrorIs(err, context.DeadlineExceeded)
+	a.Equal(int32(1), n.Load())
+}
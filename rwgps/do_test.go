@@ -0,0 +1,46 @@
+package rwgps_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+func TestDoErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name   string
+		status int
+		kind   error
+	}{
+		{name: "unauthorized", status: http.StatusUnauthorized, kind: activity.ErrUnauthorized},
+		{name: "not found", status: http.StatusNotFound, kind: activity.ErrNotFound},
+		{name: "rate limited", status: http.StatusTooManyRequests, kind: activity.ErrRateLimited},
+		{name: "server", status: http.StatusServiceUnavailable, kind: activity.ErrServer},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, svr := newClient(func(mux *http.ServeMux) {
+				mux.HandleFunc("/users/current.json", func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(tt.status)
+				})
+			})
+			defer svr.Close()
+			user, err := client.Users.AuthenticatedUser(context.TODO())
+			a.Nil(user)
+			a.ErrorIs(err, tt.kind)
+			var fault *rwgps.Fault
+			a.ErrorAs(err, &fault)
+			a.Equal(tt.status, fault.Code)
+			a.Equal(http.StatusText(tt.status), fault.Message)
+		})
+	}
+}
@@ -1,8 +1,12 @@
 package rwgps
 
 import (
+	"errors"
+	"math"
 	"strconv"
+	"time"
 
+	"github.com/martinlindhe/unit"
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-gpx"
 
@@ -10,6 +14,8 @@ import (
 )
 
 var _ activity.GPXEncoder = (*Trip)(nil)
+var _ activity.SeriesEncoder = (*Trip)(nil)
+var _ activity.CourseEncoder = (*Trip)(nil)
 
 func (t *Trip) GPX() (*gpx.GPX, error) {
 	var layout geom.Layout
@@ -61,3 +67,122 @@ func (t *Trip) GPX() (*gpx.GPX, error) {
 	}
 	return x, nil
 }
+
+// Series representation of a trip
+//
+// Channels with no non-zero values across all track points are omitted,
+// samples missing from a track point of an available channel are NaN and
+// track points without coordinates have NaN latitude and longitude.
+func (t *Trip) Series() (*activity.Series, error) {
+	if t.Type == TypeRoute.String() {
+		// routes do not have a `time` dimension
+		return nil, errors.New("routes do not support series encoding")
+	}
+	n := len(t.TrackPoints)
+	if n == 0 {
+		return nil, errors.New("no track points available for series encoding")
+	}
+	start := t.TrackPoints[0].Time
+	x := &activity.Series{
+		StartTime: time.Unix(0, int64(start*float64(time.Second))).UTC(),
+		Time:      make([]unit.Duration, n),
+		Latitude:  make([]float64, n),
+		Longitude: make([]float64, n),
+		Elevation: make([]unit.Length, n),
+		Distance:  make([]unit.Length, n),
+		Speed:     make([]unit.Speed, n),
+		HeartRate: make([]float64, n),
+		Cadence:   make([]float64, n),
+		Power:     make([]unit.Power, n),
+		Grade:     make([]float64, n),
+	}
+	var has struct{ latlng, ele, dst, spd, hr, cad, pwr, grd bool }
+	for i, tp := range t.TrackPoints {
+		x.Time[i] = unit.Duration(tp.Time-start) * unit.Second
+		x.Latitude[i], x.Longitude[i] = math.NaN(), math.NaN()
+		if tp.Latitude != 0 || tp.Longitude != 0 {
+			x.Latitude[i], x.Longitude[i] = tp.Latitude, tp.Longitude
+			has.latlng = true
+		}
+		x.Elevation[i] = unit.Length(math.NaN())
+		if tp.has(fieldElevation) {
+			x.Elevation[i] = tp.Elevation
+			has.ele = has.ele || tp.Elevation != 0
+		}
+		x.Distance[i] = unit.Length(math.NaN())
+		if tp.has(fieldDistance) {
+			x.Distance[i] = tp.Distance
+			has.dst = has.dst || tp.Distance != 0
+		}
+		x.Speed[i] = unit.Speed(math.NaN())
+		if tp.has(fieldSpeed) {
+			// the speed unit is kph
+			x.Speed[i] = tp.Speed * unit.KilometersPerHour
+			has.spd = has.spd || tp.Speed != 0
+		}
+		x.HeartRate[i] = math.NaN()
+		if tp.has(fieldHeartRate) {
+			x.HeartRate[i] = tp.HeartRate
+			has.hr = has.hr || tp.HeartRate != 0
+		}
+		x.Cadence[i] = math.NaN()
+		if tp.has(fieldCadence) {
+			x.Cadence[i] = tp.Cadence
+			has.cad = has.cad || tp.Cadence != 0
+		}
+		x.Power[i] = unit.Power(math.NaN())
+		if tp.has(fieldPower) {
+			x.Power[i] = unit.Power(tp.Power) * unit.Watt
+			has.pwr = has.pwr || tp.Power != 0
+		}
+		x.Grade[i] = math.NaN()
+		if tp.has(fieldGrade) {
+			x.Grade[i] = tp.Grade
+			has.grd = has.grd || tp.Grade != 0
+		}
+	}
+	if !has.latlng {
+		x.Latitude, x.Longitude = nil, nil
+	}
+	if !has.ele {
+		x.Elevation = nil
+	}
+	if !has.dst {
+		x.Distance = nil
+	}
+	if !has.spd {
+		x.Speed = nil
+	}
+	if !has.hr {
+		x.HeartRate = nil
+	}
+	if !has.cad {
+		x.Cadence = nil
+	}
+	if !has.pwr {
+		x.Power = nil
+	}
+	if !has.grd {
+		x.Grade = nil
+	}
+	return x, nil
+}
+
+// Course representation of a trip or route
+//
+// Track points without coordinates are skipped.
+func (t *Trip) Course() (*activity.Course, error) {
+	c := &activity.Course{Name: t.Name, Sport: activity.SportCycling}
+	for _, tp := range t.TrackPoints {
+		if tp.Latitude == 0 && tp.Longitude == 0 {
+			continue
+		}
+		c.Latitude = append(c.Latitude, tp.Latitude)
+		c.Longitude = append(c.Longitude, tp.Longitude)
+		c.Elevation = append(c.Elevation, tp.Elevation)
+	}
+	if c.Len() == 0 {
+		return nil, errors.New("no track points available for course encoding")
+	}
+	return c, nil
+}
@@ -2,6 +2,8 @@ package rwgps_test
 
 import (
 	"context"
+	"encoding/json"
+	"math"
 	"net/http"
 	"testing"
 
@@ -51,3 +53,88 @@ func TestTripEncoding(t *testing.T) {
 		})
 	}
 }
+
+func TestTripSeries(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/trips/94.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rwgps_trip_94.json")
+		})
+	})
+	defer svr.Close()
+	trip, err := client.Trips.Trip(context.TODO(), 94)
+	a.NoError(err)
+
+	series, err := trip.Series()
+	a.NoError(err)
+	a.NotNil(series)
+	a.NoError(series.Validate())
+	a.Equal(1465, series.Len())
+	a.Equal(int64(1216570739), series.StartTime.Unix())
+	a.InDelta(1, series.Time[1].Seconds(), 0.001)
+	// the first track point has no coordinates
+	a.False(series.HasLatLng(0))
+	a.True(series.HasLatLng(1))
+	a.Equal(45.384904, series.Latitude[1])
+	a.Equal(float64(144), series.HeartRate[0])
+	a.Equal(float64(85), series.Cadence[0])
+	a.Nil(series.Power)
+
+	// a channel with a value keeps NaN for the samples missing it
+	var partial rwgps.Trip
+	a.NoError(json.Unmarshal([]byte(`{"type": "trip", "track_points": [
+		{"t": 1216570739, "h": 144, "s": 0, "e": 46.7},
+		{"t": 1216570740, "s": 12.5, "p": null},
+		{"t": 1216570741, "h": 150, "g": 0}
+	]}`), &partial))
+	series, err = partial.Series()
+	a.NoError(err)
+	a.NoError(series.Validate())
+	a.Equal(float64(144), series.HeartRate[0])
+	a.True(math.IsNaN(series.HeartRate[1]))
+	a.Equal(float64(150), series.HeartRate[2])
+	a.Equal(0.0, series.Speed[0].KilometersPerHour())
+	a.InDelta(12.5, series.Speed[1].KilometersPerHour(), 0.001)
+	a.True(math.IsNaN(series.Speed[2].KilometersPerHour()))
+	a.Equal(46.7, series.Elevation[0].Meters())
+	a.True(math.IsNaN(series.Elevation[1].Meters()))
+	a.Nil(series.Power)
+	a.Nil(series.Grade)
+	a.Nil(series.Distance)
+
+	series, err = (&rwgps.Trip{Type: rwgps.TypeRoute.String()}).Series()
+	a.Error(err)
+	a.Nil(series)
+
+	series, err = (&rwgps.Trip{Type: rwgps.TypeTrip.String()}).Series()
+	a.Error(err)
+	a.Nil(series)
+}
+
+func TestTripCourse(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/trips/94.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rwgps_trip_94.json")
+		})
+	})
+	defer svr.Close()
+	trip, err := client.Trips.Trip(context.TODO(), 94)
+	a.NoError(err)
+
+	course, err := trip.Course()
+	a.NoError(err)
+	a.NoError(course.Validate())
+	a.Equal(trip.Name, course.Name)
+	// the first track point has no coordinates
+	a.Equal(1464, course.Len())
+	a.Equal(45.384904, course.Latitude[0])
+
+	course, err = (&rwgps.Trip{}).Course()
+	a.Error(err)
+	a.Nil(course)
+}
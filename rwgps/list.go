@@ -0,0 +1,31 @@
+package rwgps
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+type lister struct {
+	s *TripsService
+}
+
+func newLister(s *TripsService) activity.Lister {
+	return &lister{s: s}
+}
+
+// List returns summaries of the authenticated user's trips
+func (l *lister) List(ctx context.Context, spec activity.Pagination) ([]*activity.Summary, error) {
+	user, err := l.s.client.Users.AuthenticatedUser(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []*activity.Summary
+	for trip, terr := range l.s.TripsSeq(ctx, user.ID, spec) {
+		if terr != nil {
+			return nil, terr
+		}
+		summaries = append(summaries, trip.Summarize())
+	}
+	return summaries, nil
+}
@@ -0,0 +1,53 @@
+package rwgps_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/users/current.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rwgps_users_1122.json")
+		})
+		mux.HandleFunc("/users/1122/trips.json", func(w http.ResponseWriter, _ *http.Request) {
+			a.NoError(json.NewEncoder(w).Encode(struct {
+				Results []*rwgps.Trip `json:"results"`
+			}{
+				Results: []*rwgps.Trip{{ID: 110}, {ID: 210}},
+			}))
+		})
+	})
+	defer svr.Close()
+
+	summaries, err := client.Lister().List(context.Background(), activity.Pagination{Total: 2})
+	a.NoError(err)
+	a.Len(summaries, 2)
+	a.Equal(int64(210), summaries[1].ID)
+	a.Equal("rwgps", summaries[1].Provider)
+}
+
+func TestUploadOutcome(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var upload activity.Outcome = &rwgps.Upload{Success: 1}
+	a.Zero(upload.ActivityIdentifier())
+	a.NoError(upload.Err())
+
+	upload = &rwgps.Upload{Success: -1}
+	a.Error(upload.Err())
+
+	upload = &rwgps.Upload{Tasks: []*rwgps.Task{{Status: -1, Message: "invalid file"}}}
+	a.EqualError(upload.Err(), "upload failed: invalid file")
+}
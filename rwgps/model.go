@@ -3,6 +3,9 @@ package rwgps
 //go:generate stringer -type=Type -linecomment -output=model_string.go
 
 import (
+	"encoding/json"
+	"errors"
+	"fmt"
 	"time"
 
 	"github.com/martinlindhe/unit"
@@ -93,8 +96,61 @@ type TrackPoint struct {
 	Distance  unit.Length `json:"d" units:"m"`
 	Time      float64     `json:"t"` // seconds since epoch, unix timestamp
 	Cadence   float64     `json:"c"`
+	HeartRate float64     `json:"h"`
+	Power     float64     `json:"p"`
 	Grade     float64     `json:"g"`
 	Speed     unit.Speed  `json:"s" units:"kph"`
+
+	// absent records the sample fields missing from a decoded track point
+	absent trackPointField
+}
+
+// trackPointField identifies an optional sample field of a track point
+type trackPointField uint8
+
+const (
+	fieldElevation trackPointField = 1 << iota
+	fieldDistance
+	fieldCadence
+	fieldHeartRate
+	fieldPower
+	fieldGrade
+	fieldSpeed
+)
+
+// has reports whether the field was present when the track point was decoded
+//
+// Track points not decoded from JSON have all fields present.
+func (tp *TrackPoint) has(field trackPointField) bool {
+	return tp.absent&field == 0
+}
+
+// UnmarshalJSON decodes a track point and records which sample fields are absent
+func (tp *TrackPoint) UnmarshalJSON(data []byte) error {
+	type point TrackPoint
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	var p point
+	if err := json.Unmarshal(data, &p); err != nil {
+		return err
+	}
+	*tp = TrackPoint(p)
+	for key, field := range map[string]trackPointField{
+		"e": fieldElevation,
+		"d": fieldDistance,
+		"c": fieldCadence,
+		"h": fieldHeartRate,
+		"p": fieldPower,
+		"g": fieldGrade,
+		"s": fieldSpeed,
+	} {
+		if v, ok := fields[key]; !ok || string(v) == "null" {
+			tp.absent |= field
+		}
+	}
+	return nil
 }
 
 // A Trip represents both a planned and completed activity
@@ -163,3 +219,35 @@ func (u *Upload) Done() bool {
 		return ok
 	}
 }
+
+// Stage is queued until the status includes the task
+func (u *Upload) Stage() activity.UploadStatus {
+	switch {
+	case !u.Done() && len(u.Tasks) == 0:
+		return activity.StatusQueued
+	case !u.Done():
+		return activity.StatusProcessing
+	case u.Err() != nil:
+		return activity.StatusError
+	default:
+		return activity.StatusReady
+	}
+}
+
+// ActivityIdentifier is always 0 as the status does not include the id of the trip
+func (u *Upload) ActivityIdentifier() int64 {
+	return 0
+}
+
+// Err is the processing error of the upload or of its first failed task
+func (u *Upload) Err() error {
+	if u.Success < 0 {
+		return &activity.Error{Kind: activity.ErrInvalidFile, Err: errors.New("upload failed")}
+	}
+	for _, task := range u.Tasks {
+		if task.Status < 0 {
+			return &activity.Error{Kind: activity.ErrInvalidFile, Err: fmt.Errorf("upload failed: %s", task.Message)}
+		}
+	}
+	return nil
+}
@@ -19,32 +19,37 @@ func TestUpload(t *testing.T) {
 	tests := []struct {
 		name   string
 		done   bool
+		status activity.UploadStatus
 		upload *rwgps.Upload
 	}{
 		// no tasks
-		{name: "only task id - success: 0", done: false, upload: &rwgps.Upload{Success: 0}},
-		{name: "only task id - success: -1", done: true, upload: &rwgps.Upload{Success: -1}},
-		{name: "only task id - success: 1", done: true, upload: &rwgps.Upload{Success: 1}},
+		{name: "only task id - success: 0", done: false, status: activity.StatusQueued,
+			upload: &rwgps.Upload{Success: 0}},
+		{name: "only task id - success: -1", done: true, status: activity.StatusError,
+			upload: &rwgps.Upload{Success: -1}},
+		{name: "only task id - success: 1", done: true, status: activity.StatusReady,
+			upload: &rwgps.Upload{Success: 1}},
 		// one task
-		{name: "one task - success: 0, status: 1", done: true, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: 1}}}},
-		{name: "one task - success: -1, status: 0", done: false, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: 0}}}},
-		{name: "one task - success: 1, status: -1", done: true, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: -1}}}},
+		{name: "one task - success: 0, status: 1", done: true, status: activity.StatusReady,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: 1}}}},
+		{name: "one task - success: -1, status: 0", done: false, status: activity.StatusProcessing,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: 0}}}},
+		{name: "one task - success: 1, status: -1", done: true, status: activity.StatusError,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: -1}}}},
 		// more than one task
-		{name: "more than one task - success: 0, status: 1,-1", done: true, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: 1}, {Status: -1}}}},
-		{name: "more than one task - success: -1, status: 0,1", done: false, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: 0}, {Status: 1}}}},
-		{name: "more than one task - success: 1, status: -1,0", done: false, upload: &rwgps.Upload{
-			Success: 0, Tasks: []*rwgps.Task{{Status: -1}, {Status: 0}}}},
+		{name: "more than one task - success: 0, status: 1,-1", done: true, status: activity.StatusError,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: 1}, {Status: -1}}}},
+		{name: "more than one task - success: -1, status: 0,1", done: false, status: activity.StatusProcessing,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: 0}, {Status: 1}}}},
+		{name: "more than one task - success: 1, status: -1,0", done: false, status: activity.StatusProcessing,
+			upload: &rwgps.Upload{Success: 0, Tasks: []*rwgps.Task{{Status: -1}, {Status: 0}}}},
 	}
 	for _, tt := range tests {
 		tt := tt
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 			a.Equal(tt.done, tt.upload.Done())
+			a.Equal(tt.status, tt.upload.Stage())
 		})
 	}
 }
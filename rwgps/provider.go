@@ -0,0 +1,30 @@
+package rwgps
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+// Provider returns the registration of RideWithGPS for an activity.Registry
+func Provider() *activity.Provider {
+	return &activity.Provider{
+		Name: "rwgps",
+		Capabilities: []activity.Capability{
+			activity.CapabilityUpload, activity.CapabilityList, activity.CapabilityRoutes,
+		},
+		New: func(ctx context.Context, config activity.ProviderConfig) (any, error) {
+			opts := []Option{
+				WithClientCredentials(config.ClientID, config.ClientSecret),
+				WithTokenCredentials(config.AccessToken, config.RefreshToken, config.Expiry),
+			}
+			if config.BaseURL != "" {
+				opts = append(opts, WithBaseURL(config.BaseURL))
+			}
+			if config.TokenFile != "" {
+				opts = append(opts, WithTokenStore(ctx, activity.NewFileTokenStore(config.TokenFile)))
+			}
+			return NewClient(opts...)
+		},
+	}
+}
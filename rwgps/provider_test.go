@@ -0,0 +1,42 @@
+package rwgps_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var params map[string]string
+		a.NoError(json.NewDecoder(r.Body).Decode(&params))
+		a.Equal("client", params["apikey"])
+		a.Equal("access", params["auth_token"])
+		http.ServeFile(w, r, "testdata/rwgps_users_1122.json")
+	}))
+	defer svr.Close()
+
+	provider := rwgps.Provider()
+	a.Equal("rwgps", provider.Name)
+	a.True(provider.Supports(activity.CapabilityRoutes))
+	a.False(provider.Supports(activity.CapabilityExport))
+	client, err := provider.New(context.Background(), activity.ProviderConfig{
+		ClientID:    "client",
+		AccessToken: "access",
+		BaseURL:     svr.URL,
+	})
+	a.NoError(err)
+	user, err := client.(*rwgps.Client).Users.AuthenticatedUser(context.Background())
+	a.NoError(err)
+	a.Equal(rwgps.UserID(1122), user.ID)
+}
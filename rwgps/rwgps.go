@@ -1,6 +1,6 @@
 package rwgps
 
-//go:generate genwith --do --client --token --config --ratelimit --package rwgps
+//go:generate genwith --client --token --config --ratelimit --package rwgps
 
 import (
 	"bytes"
@@ -35,6 +35,10 @@ func (c *Client) Uploader() activity.Uploader {
 	return newUploader(c.Trips)
 }
 
+func (c *Client) Lister() activity.Lister {
+	return newLister(c.Trips)
+}
+
 func withServices() Option {
 	return func(c *Client) error {
 		c.Users = &UsersService{client: c}
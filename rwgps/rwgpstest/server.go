@@ -0,0 +1,252 @@
+// Package rwgpstest provides a stateful fake of the RideWithGPS API for testing
+//
+// The Server implements the endpoints used by the rwgps package so clients created with
+// rwgps.WithBaseURL(server.URL) can be tested without access to RideWithGPS.
+package rwgpstest
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+const (
+	// AuthToken is the default auth token accepted by the server
+	AuthToken = "rwgpstest-auth-token"
+	// APIKey is the default api key of the application
+	APIKey = "rwgpstest-api-key"
+)
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAuthToken sets the auth token required by the endpoints
+func WithAuthToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithAPIKey sets the api key required by the endpoints
+func WithAPIKey(apikey string) Option {
+	return func(s *Server) {
+		s.apikey = apikey
+	}
+}
+
+// WithUser sets the authenticated user
+func WithUser(user *rwgps.User) Option {
+	return func(s *Server) {
+		s.user = user
+	}
+}
+
+// WithProcessing sets the number of status requests before a queued task creates a trip
+func WithProcessing(polls int) Option {
+	return func(s *Server) {
+		s.polls = polls
+	}
+}
+
+// Server is a fake of the RideWithGPS API safe for concurrent use
+type Server struct {
+	*fakeserver.Server
+
+	token  string
+	apikey string
+	polls  int
+
+	mu     sync.Mutex
+	ids    fakeserver.IDs
+	user   *rwgps.User
+	trips  map[int64]*rwgps.Trip
+	routes map[int64]*rwgps.Trip
+	tasks  *fakeserver.Uploads[details]
+}
+
+// NewServer starts and returns a new Server, the caller should call Close when finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		token:  AuthToken,
+		apikey: APIKey,
+		polls:  1,
+		user:   &rwgps.User{ID: 1, Name: "rwgpstest"},
+		trips:  make(map[int64]*rwgps.Trip),
+		routes: make(map[int64]*rwgps.Trip),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.tasks = fakeserver.NewUploads[details](s.polls)
+	s.Server = fakeserver.Start(s.handler())
+	return s
+}
+
+// NewClient returns a rwgps.Client for the server authenticated with the server's credentials
+func (s *Server) NewClient(opts ...rwgps.Option) (*rwgps.Client, error) {
+	opts = append([]rwgps.Option{
+		rwgps.WithBaseURL(s.URL),
+		rwgps.WithClientCredentials(s.apikey, ""),
+		rwgps.WithTokenCredentials(s.token, "", time.Time{}),
+	}, opts...)
+	return rwgps.NewClient(opts...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/current.json", s.authorize(s.getUser))
+	mux.HandleFunc("GET /users/{id}/trips.json", s.authorize(s.listTrips(rwgps.TypeTrip)))
+	mux.HandleFunc("GET /users/{id}/routes.json", s.authorize(s.listTrips(rwgps.TypeRoute)))
+	mux.HandleFunc("GET /trips/{file}", s.authorize(s.getTrip(rwgps.TypeTrip)))
+	mux.HandleFunc("GET /routes/{file}", s.authorize(s.getTrip(rwgps.TypeRoute)))
+	mux.HandleFunc("GET /queued_tasks/status.json", s.authorize(s.getStatus))
+	mux.HandleFunc("POST /trips.json", s.createUpload)
+	return mux
+}
+
+// params are the parameters of a request sent in the JSON body
+type params map[string]string
+
+// authorize requires the api key and auth token in the JSON body of the request
+func (s *Server) authorize(h func(http.ResponseWriter, *http.Request, params)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		p := make(params)
+		if err := json.NewDecoder(r.Body).Decode(&p); err != nil {
+			fault(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+		if !s.valid(p["apikey"], p["auth_token"]) {
+			fault(w, http.StatusUnauthorized, "invalid credentials")
+			return
+		}
+		h(w, r, p)
+	}
+}
+
+func (s *Server) valid(apikey, token string) bool {
+	return apikey == s.apikey && token == s.token
+}
+
+// AddTrip adds a trip of the authenticated user returning its id
+//
+// An id is assigned if the trip does not have one.
+func (s *Server) AddTrip(trip *rwgps.Trip) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(s.trips, trip)
+}
+
+// AddRoute adds a route of the authenticated user returning its id
+//
+// An id is assigned if the route does not have one.
+func (s *Server) AddRoute(route *rwgps.Trip) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.add(s.routes, route)
+}
+
+// add a copy of the trip, the lock must be held
+func (s *Server) add(trips map[int64]*rwgps.Trip, trip *rwgps.Trip) int64 {
+	x := *trip
+	if x.ID == 0 {
+		x.ID = s.ids.Next()
+	}
+	if x.UserID == 0 {
+		x.UserID = s.user.ID
+	}
+	trips[x.ID] = &x
+	return x.ID
+}
+
+// Trip returns a copy of the trip or nil if it does not exist
+func (s *Server) Trip(tripID int64) *rwgps.Trip {
+	return s.lookup(rwgps.TypeTrip, tripID)
+}
+
+// Route returns a copy of the route or nil if it does not exist
+func (s *Server) Route(routeID int64) *rwgps.Trip {
+	return s.lookup(rwgps.TypeRoute, routeID)
+}
+
+func (s *Server) lookup(kind rwgps.Type, id int64) *rwgps.Trip {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	trips := s.trips
+	if kind == rwgps.TypeRoute {
+		trips = s.routes
+	}
+	trip, ok := trips[id]
+	if !ok {
+		return nil
+	}
+	x := *trip
+	return &x
+}
+
+func (s *Server) getUser(w http.ResponseWriter, _ *http.Request, _ params) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := *s.user
+	x.AuthToken = s.token
+	fakeserver.Encode(w, http.StatusOK, map[string]any{"user": &x})
+}
+
+// listTrips returns the user's trips or routes newest first using the offset and limit
+func (s *Server) listTrips(kind rwgps.Type) func(http.ResponseWriter, *http.Request, params) {
+	return func(w http.ResponseWriter, r *http.Request, p params) {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if r.PathValue("id") != strconv.FormatInt(int64(s.user.ID), 10) {
+			fault(w, http.StatusNotFound, "user not found")
+			return
+		}
+		source := s.trips
+		if kind == rwgps.TypeRoute {
+			source = s.routes
+		}
+		trips := make([]*rwgps.Trip, 0, len(source))
+		for _, trip := range source {
+			trips = append(trips, trip)
+		}
+		slices.SortFunc(trips, func(a, b *rwgps.Trip) int {
+			return cmp.Or(b.DepartedAt.Compare(a.DepartedAt), cmp.Compare(b.ID, a.ID))
+		})
+		offset, _ := strconv.Atoi(p["offset"])
+		limit, err := strconv.Atoi(p["limit"])
+		if err != nil || limit <= 0 {
+			limit = len(trips)
+		}
+		results := fakeserver.Page(trips, offset, limit)
+		fakeserver.Encode(w, http.StatusOK, map[string]any{"results": results, "results_count": len(trips)})
+	}
+}
+
+// getTrip returns the trip or route wrapped in an envelope of its type
+func (s *Server) getTrip(kind rwgps.Type) func(http.ResponseWriter, *http.Request, params) {
+	return func(w http.ResponseWriter, r *http.Request, _ params) {
+		name, ok := strings.CutSuffix(r.PathValue("file"), ".json")
+		id, err := strconv.ParseInt(name, 10, 64)
+		if !ok || err != nil {
+			fault(w, http.StatusNotFound, "not found")
+			return
+		}
+		trip := s.lookup(kind, id)
+		if trip == nil {
+			fault(w, http.StatusNotFound, kind.String()+" not found")
+			return
+		}
+		fakeserver.Encode(w, http.StatusOK, map[string]any{"type": kind.String(), kind.String(): trip})
+	}
+}
+
+func fault(w http.ResponseWriter, status int, message string) {
+	fakeserver.Encode(w, status, &rwgps.Fault{Code: status, Message: message})
+}
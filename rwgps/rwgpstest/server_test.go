@@ -0,0 +1,85 @@
+package rwgpstest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+	"github.com/bzimmer/activity/rwgps/rwgpstest"
+)
+
+func newServer(t *testing.T, opts ...rwgpstest.Option) (*rwgpstest.Server, *rwgps.Client) {
+	t.Helper()
+	svr := rwgpstest.NewServer(opts...)
+	t.Cleanup(svr.Close)
+	client, err := svr.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svr, client
+}
+
+func TestUser(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, rwgpstest.WithUser(&rwgps.User{ID: 1122, Name: "Some One"}))
+	user, err := client.Users.AuthenticatedUser(context.Background())
+	a.NoError(err)
+	a.Equal(rwgps.UserID(1122), user.ID)
+	a.Equal("Some One", user.Name)
+
+	client, err = svr.NewClient(rwgps.WithTokenCredentials("other", "", time.Time{}))
+	a.NoError(err)
+	user, err = client.Users.AuthenticatedUser(context.Background())
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+	a.Nil(user)
+}
+
+func TestTrips(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	ctx := context.Background()
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		svr.AddTrip(&rwgps.Trip{Name: "ride", DepartedAt: start.AddDate(0, 0, i)})
+	}
+	id := svr.AddRoute(&rwgps.Trip{ID: 141014, Name: "Loop"})
+
+	trips, err := client.Trips.Trips(ctx, 1, activity.Pagination{Total: 4, Count: 3})
+	a.NoError(err)
+	a.Len(trips, 4)
+	// newest first
+	a.Equal(start.AddDate(0, 0, 4), trips[0].DepartedAt)
+	a.Equal(start.AddDate(0, 0, 1), trips[3].DepartedAt)
+
+	summaries, err := client.Lister().List(ctx, activity.Pagination{})
+	a.NoError(err)
+	a.Len(summaries, 5)
+
+	trip, err := client.Trips.Trip(ctx, trips[0].ID)
+	a.NoError(err)
+	a.Equal("trip", trip.Type)
+
+	routes, err := client.Trips.Routes(ctx, 1, activity.Pagination{})
+	a.NoError(err)
+	a.Len(routes, 1)
+	route, err := client.Trips.Route(ctx, id)
+	a.NoError(err)
+	a.Equal("Loop", route.Name)
+	a.Equal("route", route.Type)
+
+	trip, err = client.Trips.Trip(ctx, id)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(trip)
+	trips, err = client.Trips.Trips(ctx, 2, activity.Pagination{})
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(trips)
+}
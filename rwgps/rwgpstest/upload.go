@@ -0,0 +1,118 @@
+package rwgpstest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+// details of a queued upload used to create the trip
+type details struct {
+	name string
+	desc string
+}
+
+// createUpload queues an activity file for processing, the queued upload is reported as pending
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		fault(w, http.StatusBadRequest, "invalid upload")
+		return
+	}
+	if !s.valid(r.FormValue("apikey"), r.FormValue("auth_token")) {
+		fault(w, http.StatusUnauthorized, "invalid credentials")
+		return
+	}
+	fp, hdr, err := r.FormFile("file")
+	if err != nil {
+		fault(w, http.StatusBadRequest, "missing file")
+		return
+	}
+	defer fp.Close()
+	switch strings.ToLower(filepath.Ext(hdr.Filename)) {
+	case ".fit", ".gpx", ".tcx", ".kml", ".csv":
+	default:
+		fault(w, http.StatusBadRequest, "unsupported file type")
+		return
+	}
+	t, err := fakeserver.NewUpload(hdr.Filename, fp, details{
+		name: r.FormValue("trip[name]"),
+		desc: r.FormValue("trip[description]"),
+	})
+	if err != nil {
+		fault(w, http.StatusBadRequest, "invalid file")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.tasks.Add(s.ids.Next(), t)
+	fakeserver.Encode(w, http.StatusOK, &rwgps.Upload{TaskID: t.ID})
+}
+
+// getStatus returns the status of the queued task
+func (s *Server) getStatus(w http.ResponseWriter, _ *http.Request, p params) {
+	id, err := strconv.ParseInt(p["ids"], 10, 64)
+	if err != nil {
+		fault(w, http.StatusBadRequest, "invalid ids")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	t, ok := s.tasks.Status(id, s.exists, s.create)
+	if !ok {
+		fault(w, http.StatusNotFound, "task not found")
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, &rwgps.Upload{Success: 1, Tasks: []*rwgps.Task{s.task(t)}})
+}
+
+// exists returns true if the trip exists, the lock must be held
+func (s *Server) exists(tripID int64) bool {
+	_, ok := s.trips[tripID]
+	return ok
+}
+
+// create the trip of the task, the lock must be held
+func (s *Server) create(t *fakeserver.Upload[details]) (int64, error) {
+	trip := &rwgps.Trip{
+		ID:          s.ids.Next(),
+		Name:        cmp.Or(t.Details.name, strings.TrimSuffix(t.Filename, filepath.Ext(t.Filename))),
+		Description: t.Details.desc,
+		Type:        rwgps.TypeTrip.String(),
+		UserID:      s.user.ID,
+		CreatedAt:   t.Updated,
+		UpdatedAt:   t.Updated,
+		DepartedAt:  t.Updated,
+	}
+	s.trips[trip.ID] = trip
+	return trip.ID, nil
+}
+
+// task is the status of the upload as reported by RideWithGPS, the lock must be held
+func (s *Server) task(t *fakeserver.Upload[details]) *rwgps.Task {
+	res := &rwgps.Task{
+		ID:        int(t.ID),
+		CreatedAt: t.Created.Format(time.RFC3339),
+		UpdatedAt: t.Updated.Format(time.RFC3339),
+		UserID:    int(s.user.ID),
+	}
+	var dup *fakeserver.DuplicateError
+	switch {
+	case !t.Done():
+		res.Message = "queued"
+	case errors.As(t.Err, &dup):
+		res.Status, res.Message = -1, fmt.Sprintf("duplicate of trip %d", dup.ID)
+	case t.Err != nil:
+		res.Status, res.Message = -1, t.Err.Error()
+	default:
+		res.Status, res.Message = 1, fmt.Sprintf("created trip %d", t.ResourceID)
+	}
+	return res
+}
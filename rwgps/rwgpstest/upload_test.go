@@ -0,0 +1,61 @@
+package rwgpstest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps/rwgpstest"
+)
+
+func file(contents string) *activity.File {
+	return &activity.File{Reader: strings.NewReader(contents), Name: "ride.gpx", Format: activity.FormatGPX}
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, rwgpstest.WithProcessing(1))
+	ctx := context.Background()
+
+	upload, err := client.Trips.Upload(ctx, file("<gpx/>"))
+	a.NoError(err)
+	a.Equal(activity.StatusQueued, upload.Stage())
+	id := upload.TaskID
+	upload, err = client.Trips.Status(ctx, id)
+	a.NoError(err)
+	a.Equal(activity.StatusProcessing, upload.Stage())
+	upload, err = client.Trips.Status(ctx, id)
+	a.NoError(err)
+	a.Equal(activity.StatusReady, upload.Stage())
+	a.Equal("created trip 1002", upload.Tasks[0].Message)
+	a.Equal("ride", svr.Trip(1002).Name)
+
+	// the processing errors are reported in the task messages
+	process := func(contents string) error {
+		upload, err = client.Trips.Upload(ctx, file(contents))
+		a.NoError(err)
+		task := upload.TaskID
+		for range 2 {
+			upload, err = client.Trips.Status(ctx, task)
+			a.NoError(err)
+		}
+		return upload.Err()
+	}
+	a.ErrorContains(process(""), "the file is empty")
+	a.ErrorContains(process("<gpx/>"), "duplicate of trip 1002")
+
+	upload, err = client.Trips.Status(ctx, id+100)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(upload)
+
+	upload, err = client.Trips.Upload(ctx, &activity.File{
+		Reader: strings.NewReader("data"), Name: "ride.txt", Format: activity.FormatGPX})
+	a.True(errors.Is(err, activity.ErrInvalidFile))
+	a.Nil(upload)
+}
@@ -0,0 +1,49 @@
+package rwgps
+
+import (
+	"github.com/martinlindhe/unit"
+
+	"github.com/bzimmer/activity"
+)
+
+const provider = "rwgps"
+
+var _ activity.Summarizer = (*Trip)(nil)
+
+// Summarize returns a provider-neutral summary of the trip
+//
+// RWGPS does not classify trips by sport so all trips are assumed to be cycling
+func (t *Trip) Summarize() *activity.Summary {
+	s := &activity.Summary{
+		ID:            t.ID,
+		Provider:      provider,
+		Name:          t.Name,
+		Sport:         activity.SportCycling,
+		StartTime:     t.DepartedAt,
+		ElapsedTime:   unit.Duration(t.Duration) * unit.Second,
+		Distance:      t.Distance,
+		ElevationGain: t.ElevationGain,
+	}
+	if t.Metrics != nil {
+		s.MovingTime = unit.Duration(t.Metrics.MovingTime) * unit.Second
+		if t.Metrics.Watts != nil {
+			s.AveragePower = unit.Power(t.Metrics.Watts.Avg) * unit.Watt
+			s.MaxPower = unit.Power(t.Metrics.Watts.Max) * unit.Watt
+		}
+	}
+	return s
+}
+
+var _ activity.Locator = (*Trip)(nil)
+
+// Endpoints returns the first and last coordinates of the trip
+func (t *Trip) Endpoints() (*activity.LatLng, *activity.LatLng) {
+	var start, end *activity.LatLng
+	if t.FirstLat != 0 || t.FirstLng != 0 {
+		start = &activity.LatLng{Lat: t.FirstLat, Lng: t.FirstLng}
+	}
+	if t.LastLat != 0 || t.LastLng != 0 {
+		end = &activity.LatLng{Lat: t.LastLat, Lng: t.LastLng}
+	}
+	return start, end
+}
@@ -0,0 +1,67 @@
+package rwgps_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/trips/94.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rwgps_trip_94.json")
+		})
+	})
+	defer svr.Close()
+	trip, err := client.Trips.Trip(context.TODO(), 94)
+	a.NoError(err)
+
+	s := trip.Summarize()
+	a.NotNil(s)
+	a.Equal(int64(94), s.ID)
+	a.Equal("rwgps", s.Provider)
+	a.Equal(activity.SportCycling, s.Sport)
+	a.Equal(trip.DepartedAt, s.StartTime)
+	a.InDelta(42990.7, s.Distance.Meters(), 0.01)
+	a.InDelta(754.317, s.ElevationGain.Meters(), 0.01)
+	a.InDelta(6475, s.MovingTime.Seconds(), 0.01)
+
+	s = (&rwgps.Trip{ID: 1}).Summarize()
+	a.Zero(s.MovingTime)
+	a.Zero(s.AveragePower)
+}
+
+func TestEndpoints(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/trips/94.json", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/rwgps_trip_94.json")
+		})
+	})
+	defer svr.Close()
+	trip, err := client.Trips.Trip(context.TODO(), 94)
+	a.NoError(err)
+
+	start, end := trip.Endpoints()
+	a.Equal(&activity.LatLng{Lat: 45.384904, Lng: -122.758382}, start)
+	a.Equal(&activity.LatLng{Lat: 45.354349, Lng: -122.769675}, end)
+
+	// the track points are sampled for the fingerprint
+	fp := activity.NewFingerprint(trip)
+	a.Equal(start, fp.Start)
+	a.NotEmpty(fp.Track)
+
+	start, end = (&rwgps.Trip{ID: 1}).Endpoints()
+	a.Nil(start)
+	a.Nil(end)
+}
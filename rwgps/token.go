@@ -0,0 +1,21 @@
+package rwgps
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+// WithTokenStore uses the token in the store, if one exists
+//
+// RideWithGPS auth tokens do not expire so the token is never refreshed or saved.
+func WithTokenStore(ctx context.Context, store activity.TokenStore) Option {
+	return func(c *Client) error {
+		token, err := activity.StoredToken(ctx, store, c.token)
+		if err != nil {
+			return err
+		}
+		c.token = token
+		return nil
+	}
+}
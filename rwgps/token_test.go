@@ -0,0 +1,61 @@
+package rwgps_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/rwgps"
+)
+
+func TestWithTokenStore(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name  string
+		token *oauth2.Token
+		auth  string
+	}{
+		{name: "stored", token: &oauth2.Token{AccessToken: "storedToken"}, auth: "storedToken"},
+		{name: "not stored", auth: "barToken"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var params map[string]string
+				a.NoError(json.NewDecoder(r.Body).Decode(&params))
+				a.Equal(tt.auth, params["auth_token"])
+				http.ServeFile(w, r, "testdata/rwgps_users_1122.json")
+			}))
+			defer svr.Close()
+
+			ctx := context.Background()
+			store := activity.NewFileTokenStore(filepath.Join(t.TempDir(), "rwgps.json"))
+			if tt.token != nil {
+				a.NoError(store.SaveToken(ctx, tt.token))
+			}
+			client, err := rwgps.NewClient(
+				rwgps.WithBaseURL(svr.URL),
+				rwgps.WithTokenCredentials("barToken", "", time.Time{}),
+				rwgps.WithTokenStore(ctx, store),
+			)
+			a.NoError(err)
+			user, err := client.Users.AuthenticatedUser(ctx)
+			a.NoError(err)
+			a.Equal(rwgps.UserID(1122), user.ID)
+		})
+	}
+
+	_, err := rwgps.NewClient(rwgps.WithTokenStore(context.Background(), nil))
+	a.Error(err)
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"mime/multipart"
 	"net/http"
 	"strconv"
@@ -19,67 +20,49 @@ const pageSize = 100
 // TripsService provides access to Trips and Routes via the RWGPS API
 type TripsService service
 
-type tripsPaginator struct {
-	service TripsService
-	userID  UserID
-	trips   []*Trip
-	kind    string
-}
-
-func (p *tripsPaginator) PageSize() int {
-	return pageSize
+// Trips returns a slice of trips
+func (s *TripsService) Trips(ctx context.Context, userID UserID, spec activity.Pagination) ([]*Trip, error) {
+	return activity.Collect(s.TripsSeq(ctx, userID, spec))
 }
 
-func (p *tripsPaginator) Count() int {
-	return len(p.trips)
+// TripsSeq returns an iterator over the trips
+func (s *TripsService) TripsSeq(ctx context.Context, userID UserID, spec activity.Pagination) iter.Seq2[*Trip, error] {
+	return activity.Iter(ctx, pageSize, s.page(userID, "trips"), spec)
 }
 
-func (p *tripsPaginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
-	uri := fmt.Sprintf("users/%d/%s.json", p.userID, p.kind)
-	params := map[string]string{
-		// pagination uses the concept of page (based on strava), rwgps uses an offset by row
-		//  since pagination starts with page 1 (again, strava), subtract one from `start`
-		"offset": strconv.FormatInt(int64((spec.Start-1)*p.PageSize()), 10),
-		"limit":  strconv.FormatInt(int64(spec.Count), 10),
-	}
-	req, err := p.service.client.newAPIRequest(ctx, uri, params)
-	if err != nil {
-		return 0, err
-	}
-	type TripsResponse struct {
-		Results      []*Trip `json:"results"`
-		ResultsCount int     `json:"results_count"`
-	}
-	res := &TripsResponse{}
-	err = p.service.client.do(req, res)
-	if err != nil {
-		return 0, err
-	}
-	if spec.Total > 0 && len(p.trips)+len(res.Results) > spec.Total {
-		res.Results = res.Results[:spec.Total-len(p.trips)]
-	}
-	p.trips = append(p.trips, res.Results...)
-	return len(res.Results), nil
+// Routes returns a slice of routes
+func (s *TripsService) Routes(ctx context.Context, userID UserID, spec activity.Pagination) ([]*Trip, error) {
+	return activity.Collect(s.RoutesSeq(ctx, userID, spec))
 }
 
-// Trips returns a slice of trips
-func (s *TripsService) Trips(ctx context.Context, userID UserID, spec activity.Pagination) ([]*Trip, error) {
-	p := &tripsPaginator{service: *s, userID: userID, kind: "trips", trips: make([]*Trip, 0)}
-	err := activity.Paginate(ctx, p, spec)
-	if err != nil {
-		return nil, err
-	}
-	return p.trips, nil
+// RoutesSeq returns an iterator over the routes
+func (s *TripsService) RoutesSeq(ctx context.Context, userID UserID, spec activity.Pagination) iter.Seq2[*Trip, error] {
+	return activity.Iter(ctx, pageSize, s.page(userID, "routes"), spec)
 }
 
-// Routes returns a slice of routes
-func (s *TripsService) Routes(ctx context.Context, userID UserID, spec activity.Pagination) ([]*Trip, error) {
-	p := &tripsPaginator{service: *s, userID: userID, kind: "routes", trips: make([]*Trip, 0)}
-	err := activity.Paginate(ctx, p, spec)
-	if err != nil {
-		return nil, err
+// page returns a function querying a page of trips or routes
+func (s *TripsService) page(userID UserID, kind string) activity.Page[*Trip] {
+	return func(ctx context.Context, spec activity.Pagination) ([]*Trip, error) {
+		uri := fmt.Sprintf("users/%d/%s.json", userID, kind)
+		params := map[string]string{
+			// rwgps uses an offset by row rather than a page
+			"offset": strconv.FormatInt(int64(spec.Offset), 10),
+			"limit":  strconv.FormatInt(int64(spec.Count), 10),
+		}
+		req, err := s.client.newAPIRequest(ctx, uri, params)
+		if err != nil {
+			return nil, err
+		}
+		type TripsResponse struct {
+			Results      []*Trip `json:"results"`
+			ResultsCount int     `json:"results_count"`
+		}
+		res := &TripsResponse{}
+		if err = s.client.do(req, res); err != nil {
+			return nil, err
+		}
+		return res.Results, nil
 	}
-	return p.trips, nil
 }
 
 // Trip returns a trip for the `tripID`
@@ -161,8 +144,7 @@ func (s *TripsService) Upload(ctx context.Context, file *activity.File) (*Upload
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
 	res := &Upload{}
-	err = s.client.do(req, res)
-	if err != nil {
+	if err = activity.Do(s.client.client, req, res, activity.UploadFault(newStatusError)); err != nil {
 		return nil, err
 	}
 	return res, nil
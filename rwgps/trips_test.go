@@ -219,3 +219,53 @@ func TestStatus(t *testing.T) {
 		})
 	}
 }
+
+func TestTripsSeq(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var offsets []string
+	client, svr := newClient(func(mux *http.ServeMux) {
+		mux.HandleFunc("/users/88272/trips.json", func(w http.ResponseWriter, r *http.Request) {
+			var params map[string]string
+			a.NoError(json.NewDecoder(r.Body).Decode(&params))
+			offsets = append(offsets, params["offset"])
+			limit, err := strconv.Atoi(params["limit"])
+			a.NoError(err)
+			trips := make([]*rwgps.Trip, limit)
+			for i := range trips {
+				trips[i] = &rwgps.Trip{ID: int64(i)}
+			}
+			a.NoError(json.NewEncoder(w).Encode(struct {
+				Results []*rwgps.Trip `json:"results"`
+			}{
+				Results: trips,
+			}))
+		})
+	})
+	defer svr.Close()
+
+	var n int
+	for trip, err := range client.Trips.TripsSeq(context.TODO(), rwgps.UserID(88272), activity.Pagination{Total: 150}) {
+		a.NoError(err)
+		a.NotNil(trip)
+		n++
+	}
+	a.Equal(150, n)
+	a.Equal([]string{"0", "100"}, offsets)
+
+	// the offset follows the count rather than the page size
+	offsets = nil
+	trips, err := client.Trips.Trips(
+		context.TODO(), rwgps.UserID(88272), activity.Pagination{Start: 2, Count: 10, Total: 30})
+	a.NoError(err)
+	a.Len(trips, 30)
+	a.Equal([]string{"10", "20", "30"}, offsets)
+
+	offsets = nil
+	for _, err := range client.Trips.TripsSeq(context.TODO(), rwgps.UserID(88272), activity.Pagination{}) {
+		a.NoError(err)
+		break
+	}
+	a.Len(offsets, 1)
+}
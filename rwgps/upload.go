@@ -6,6 +6,9 @@ import (
 	"github.com/bzimmer/activity"
 )
 
+var _ activity.Outcome = (*Upload)(nil)
+var _ activity.Stager = (*Upload)(nil)
+
 type uploader struct {
 	s *TripsService
 }
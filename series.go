@@ -0,0 +1,176 @@
+package activity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+// Series is a provider-neutral time series of activity data
+//
+// Each channel is indexed by sample and, if not nil, has the same length as Time.
+// Floating point samples missing from an otherwise available channel are NaN and are
+// encoded in JSON as null.
+type Series struct {
+	// StartTime is the time of the first sample
+	StartTime time.Time `json:"start_time"`
+	// Time is the offset of each sample from StartTime
+	Time        []unit.Duration    `json:"time" units:"s"`
+	Latitude    []float64          `json:"latitude,omitempty"`
+	Longitude   []float64          `json:"longitude,omitempty"`
+	Elevation   []unit.Length      `json:"elevation,omitempty" units:"m"`
+	Distance    []unit.Length      `json:"distance,omitempty" units:"m"`
+	Speed       []unit.Speed       `json:"speed,omitempty" units:"mps"`
+	HeartRate   []float64          `json:"heartrate,omitempty" units:"bpm"`
+	Cadence     []float64          `json:"cadence,omitempty" units:"rpm"`
+	Power       []unit.Power       `json:"power,omitempty" units:"W"`
+	Temperature []unit.Temperature `json:"temperature,omitempty" units:"K"`
+	Grade       []float64          `json:"grade,omitempty" units:"%"`
+	Moving      []bool             `json:"moving,omitempty"`
+}
+
+// Len returns the number of samples in the series
+func (s *Series) Len() int {
+	return len(s.Time)
+}
+
+// Timestamp returns the absolute time of the sample at index i
+func (s *Series) Timestamp(i int) time.Time {
+	return s.StartTime.Add(time.Duration(s.Time[i].Seconds() * float64(time.Second)))
+}
+
+// HasLatLng returns true if the sample at index i has a valid coordinate
+func (s *Series) HasLatLng(i int) bool {
+	if s.Latitude == nil || s.Longitude == nil {
+		return false
+	}
+	return !math.IsNaN(s.Latitude[i]) && !math.IsNaN(s.Longitude[i])
+}
+
+// Validate the series is well-formed, that is, all available channels are the same length as Time
+func (s *Series) Validate() error {
+	n := s.Len()
+	if n == 0 {
+		return errors.New("no time samples")
+	}
+	for name, m := range map[string]int{
+		"latitude":    len(s.Latitude),
+		"longitude":   len(s.Longitude),
+		"elevation":   len(s.Elevation),
+		"distance":    len(s.Distance),
+		"speed":       len(s.Speed),
+		"heartrate":   len(s.HeartRate),
+		"cadence":     len(s.Cadence),
+		"power":       len(s.Power),
+		"temperature": len(s.Temperature),
+		"grade":       len(s.Grade),
+		"moving":      len(s.Moving),
+	} {
+		if m != 0 && m != n {
+			return fmt.Errorf("invalid length for %s: expected %d, got %d", name, n, m)
+		}
+	}
+	if (s.Latitude == nil) != (s.Longitude == nil) {
+		return errors.New("both latitude and longitude are required")
+	}
+	return nil
+}
+
+// samples is a channel of a series encoded in JSON with missing samples as null
+type samples[T ~float64] []T
+
+func (s samples[T]) MarshalJSON() ([]byte, error) {
+	if s == nil {
+		return []byte("null"), nil
+	}
+	xs := make([]*float64, len(s))
+	for i := range s {
+		if x := float64(s[i]); !math.IsNaN(x) {
+			xs[i] = &x
+		}
+	}
+	return json.Marshal(xs)
+}
+
+func (s *samples[T]) UnmarshalJSON(b []byte) error {
+	var xs []*float64
+	if err := json.Unmarshal(b, &xs); err != nil {
+		return err
+	}
+	if xs == nil {
+		*s = nil
+		return nil
+	}
+	*s = make(samples[T], len(xs))
+	for i, x := range xs {
+		(*s)[i] = T(math.NaN())
+		if x != nil {
+			(*s)[i] = T(*x)
+		}
+	}
+	return nil
+}
+
+// seriesJSON is the JSON encoding of a Series
+type seriesJSON struct {
+	StartTime   time.Time                 `json:"start_time"`
+	Time        samples[unit.Duration]    `json:"time"`
+	Latitude    samples[float64]          `json:"latitude,omitempty"`
+	Longitude   samples[float64]          `json:"longitude,omitempty"`
+	Elevation   samples[unit.Length]      `json:"elevation,omitempty"`
+	Distance    samples[unit.Length]      `json:"distance,omitempty"`
+	Speed       samples[unit.Speed]       `json:"speed,omitempty"`
+	HeartRate   samples[float64]          `json:"heartrate,omitempty"`
+	Cadence     samples[float64]          `json:"cadence,omitempty"`
+	Power       samples[unit.Power]       `json:"power,omitempty"`
+	Temperature samples[unit.Temperature] `json:"temperature,omitempty"`
+	Grade       samples[float64]          `json:"grade,omitempty"`
+	Moving      []bool                    `json:"moving,omitempty"`
+}
+
+// MarshalJSON encodes the series with missing samples as null
+func (s Series) MarshalJSON() ([]byte, error) {
+	return json.Marshal(seriesJSON{
+		StartTime:   s.StartTime,
+		Time:        s.Time,
+		Latitude:    s.Latitude,
+		Longitude:   s.Longitude,
+		Elevation:   s.Elevation,
+		Distance:    s.Distance,
+		Speed:       s.Speed,
+		HeartRate:   s.HeartRate,
+		Cadence:     s.Cadence,
+		Power:       s.Power,
+		Temperature: s.Temperature,
+		Grade:       s.Grade,
+		Moving:      s.Moving,
+	})
+}
+
+// UnmarshalJSON decodes the series with null samples as missing
+func (s *Series) UnmarshalJSON(b []byte) error {
+	var x seriesJSON
+	if err := json.Unmarshal(b, &x); err != nil {
+		return err
+	}
+	*s = Series{
+		StartTime:   x.StartTime,
+		Time:        x.Time,
+		Latitude:    x.Latitude,
+		Longitude:   x.Longitude,
+		Elevation:   x.Elevation,
+		Distance:    x.Distance,
+		Speed:       x.Speed,
+		HeartRate:   x.HeartRate,
+		Cadence:     x.Cadence,
+		Power:       x.Power,
+		Temperature: x.Temperature,
+		Grade:       x.Grade,
+		Moving:      x.Moving,
+	}
+	return nil
+}
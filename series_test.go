@@ -0,0 +1,110 @@
+package activity_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestSeries(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name   string
+		err    string
+		series *activity.Series
+	}{
+		{
+			name:   "no time",
+			err:    "no time samples",
+			series: &activity.Series{},
+		},
+		{
+			name: "valid",
+			series: &activity.Series{
+				StartTime: start,
+				Time:      []unit.Duration{0, 1, 2},
+				Latitude:  []float64{math.NaN(), 47.1, 47.2},
+				Longitude: []float64{math.NaN(), -122.1, -122.2},
+				Power:     []unit.Power{100, 200, 300},
+			},
+		},
+		{
+			name: "invalid length",
+			err:  "invalid length for power",
+			series: &activity.Series{
+				StartTime: start,
+				Time:      []unit.Duration{0, 1, 2},
+				Power:     []unit.Power{100, 200},
+			},
+		},
+		{
+			name: "latitude without longitude",
+			err:  "both latitude and longitude are required",
+			series: &activity.Series{
+				StartTime: start,
+				Time:      []unit.Duration{0, 1, 2},
+				Latitude:  []float64{47.0, 47.1, 47.2},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			err := tt.series.Validate()
+			if tt.err != "" {
+				a.Error(err)
+				a.Contains(err.Error(), tt.err)
+				return
+			}
+			a.NoError(err)
+			a.Equal(3, tt.series.Len())
+			a.Equal(start.Add(2*time.Second), tt.series.Timestamp(2))
+			a.False(tt.series.HasLatLng(0))
+			a.True(tt.series.HasLatLng(1))
+		})
+	}
+}
+
+func TestSeriesJSON(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	series := &activity.Series{
+		StartTime: start,
+		Time:      []unit.Duration{0, 1, 2},
+		Latitude:  []float64{math.NaN(), 47.1, 47.2},
+		Longitude: []float64{math.NaN(), -122.1, -122.2},
+		HeartRate: []float64{120, math.NaN(), 140},
+		Power:     []unit.Power{100, 200, unit.Power(math.NaN())},
+	}
+	data, err := json.Marshal(series)
+	a.NoError(err)
+	a.JSONEq(`{
+		"start_time": "2021-03-07T08:00:00Z",
+		"time": [0, 1, 2],
+		"latitude": [null, 47.1, 47.2],
+		"longitude": [null, -122.1, -122.2],
+		"heartrate": [120, null, 140],
+		"power": [100, 200, null]
+	}`, string(data))
+
+	var decoded activity.Series
+	a.NoError(json.Unmarshal(data, &decoded))
+	a.Equal(start, decoded.StartTime)
+	a.Equal(series.Time, decoded.Time)
+	a.Nil(decoded.Cadence)
+	a.True(math.IsNaN(decoded.HeartRate[1]))
+	a.True(math.IsNaN(float64(decoded.Power[2])))
+	a.Equal(47.1, decoded.Latitude[1])
+	a.False(decoded.HasLatLng(0))
+}
@@ -7,13 +7,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"maps"
 	"mime/multipart"
 	"net/http"
 	"net/url"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
 
+	"github.com/twpayne/go-gpx"
 	"golang.org/x/sync/errgroup"
 
 	"github.com/bzimmer/activity"
@@ -46,57 +50,6 @@ func WithDateRange(before, after time.Time) APIOption {
 	}
 }
 
-type channelPaginator struct {
-	count      int
-	options    []APIOption
-	service    ActivityService
-	activities chan *ActivityResult
-}
-
-func (p *channelPaginator) PageSize() int {
-	return PageSize
-}
-
-func (p *channelPaginator) Count() int {
-	return p.count
-}
-
-func (p *channelPaginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
-	v := make(url.Values)
-	v.Set("page", fmt.Sprintf("%d", spec.Start))
-	v.Set("per_page", fmt.Sprintf("%d", spec.Count))
-	for _, opt := range p.options {
-		if opt == nil {
-			continue
-		}
-		if err := opt(v); err != nil {
-			return 0, err
-		}
-	}
-	uri := fmt.Sprintf("athlete/activities?%s", v.Encode())
-	req, err := p.service.client.newAPIRequest(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return 0, err
-	}
-	var acts []*Activity
-	err = p.service.client.do(req, &acts)
-	if err != nil {
-		return 0, err
-	}
-	for _, act := range acts {
-		select {
-		case <-ctx.Done():
-			return 0, ctx.Err()
-		case p.activities <- &ActivityResult{Activity: act}:
-			p.count++
-		}
-		if p.count == spec.Total {
-			break
-		}
-	}
-	return len(acts), nil
-}
-
 // Streams returns the activity's data streams
 func (s *ActivityService) Streams(ctx context.Context, activityID int64, streams ...string) (*Streams, error) {
 	if err := s.validateStreams(streams); err != nil {
@@ -155,20 +108,45 @@ func (s *ActivityService) Activities(
 	acts := make(chan *ActivityResult, PageSize)
 	go func() {
 		defer close(acts)
-		p := &channelPaginator{service: *s, activities: acts, options: opts}
-		err := activity.Paginate(ctx, p, spec)
-		if err != nil {
+		for act, err := range s.ActivitiesSeq(ctx, spec, opts...) {
 			select {
 			case <-ctx.Done():
 				return
-			case acts <- &ActivityResult{Err: err}:
-				return
+			case acts <- &ActivityResult{Activity: act, Err: err}:
 			}
 		}
 	}()
 	return acts
 }
 
+// ActivitiesSeq returns an iterator over the activities for an athlete
+func (s *ActivityService) ActivitiesSeq(
+	ctx context.Context, spec activity.Pagination, opts ...APIOption) iter.Seq2[*Activity, error] {
+	return activity.Iter(ctx, PageSize, func(ctx context.Context, spec activity.Pagination) ([]*Activity, error) {
+		v := make(url.Values)
+		v.Set("page", fmt.Sprintf("%d", spec.Start))
+		v.Set("per_page", fmt.Sprintf("%d", spec.Count))
+		for _, opt := range opts {
+			if opt == nil {
+				continue
+			}
+			if err := opt(v); err != nil {
+				return nil, err
+			}
+		}
+		uri := fmt.Sprintf("athlete/activities?%s", v.Encode())
+		req, err := s.client.newAPIRequest(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		var acts []*Activity
+		if err = s.client.do(req, &acts); err != nil {
+			return nil, err
+		}
+		return acts, nil
+	}, spec)
+}
+
 // ActivitiesIter executes the iter function over the results of the channel
 func ActivitiesIter(res <-chan *ActivityResult, iter ActivityIterFunc) error {
 	for ar := range res {
@@ -220,7 +198,7 @@ func (s *ActivityService) Upload(ctx context.Context, file *activity.File) (*Upl
 	req.Header.Set("Content-Type", w.FormDataContentType())
 
 	res := &Upload{}
-	if err = s.client.do(req, res); err != nil {
+	if err = activity.Do(s.client.client, req, res, activity.UploadFault(newStatusError)); err != nil {
 		return nil, err
 	}
 	return res, nil
@@ -293,24 +271,58 @@ func (s *ActivityService) Photos(ctx context.Context, activityID int64, size int
 
 // Export exports an activity in the GPX format
 func (s *ActivityService) Export(ctx context.Context, activityID int64) (*activity.Export, error) {
-	act, err := s.Activity(ctx, activityID, "latlng", "time", "altitude")
-	if err != nil {
-		return nil, err
+	return s.ExportFormat(ctx, activityID, activity.FormatGPX)
+}
+
+// ExportFormat exports an activity in the GPX, TCX, or FIT format
+//
+// Strava does not provide the original file so the export is encoded from the activity streams.
+func (s *ActivityService) ExportFormat(
+	ctx context.Context, activityID int64, format activity.Format) (*activity.Export, error) {
+	var act *Activity
+	var err error
+	var buf bytes.Buffer
+	switch format {
+	case activity.FormatGPX:
+		act, err = s.Activity(ctx, activityID, "latlng", "time", "altitude")
+		if err != nil {
+			return nil, err
+		}
+		var x *gpx.GPX
+		x, err = act.GPX()
+		if err != nil {
+			return nil, err
+		}
+		err = x.Write(&buf)
+	case activity.FormatFIT, activity.FormatTCX:
+		act, err = s.Activity(ctx, activityID, slices.Sorted(maps.Keys(streamsets()))...)
+		if err != nil {
+			return nil, err
+		}
+		var x *activity.Series
+		x, err = act.Series()
+		if err != nil {
+			return nil, err
+		}
+		if format == activity.FormatFIT {
+			err = activity.EncodeFIT(&buf, act.Summarize(), x)
+			break
+		}
+		err = activity.EncodeTCX(&buf, act.Summarize(), x)
+	case activity.FormatOriginal:
+		fallthrough
+	default:
+		return nil, fmt.Errorf("unsupported export format '%s'", format)
 	}
-	x, err := act.GPX()
 	if err != nil {
 		return nil, err
 	}
-	var buf bytes.Buffer
-	if err = x.Write(&buf); err != nil {
-		return nil, err
-	}
 	name := strings.Join(fileNameRE.FindAllString(act.Name, -1), "_")
 	exp := &activity.Export{
 		File: &activity.File{
 			Reader: &buf,
 			Name:   name,
-			Format: activity.FormatGPX,
+			Format: format,
 		},
 		ID: activityID,
 	}
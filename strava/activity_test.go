@@ -468,6 +468,68 @@ func TestExporter(t *testing.T) {
 	}
 }
 
+func TestExportFormat(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	for _, tt := range []struct {
+		name   string
+		format activity.Format
+		err    string
+	}{
+		{
+			name:   "gpx",
+			format: activity.FormatGPX,
+		},
+		{
+			name:   "fit",
+			format: activity.FormatFIT,
+		},
+		{
+			name:   "tcx",
+			format: activity.FormatTCX,
+		},
+		{
+			name:   "original",
+			format: activity.FormatOriginal,
+			err:    "unsupported export format 'original'",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, svr := newClientMust(func(mux *http.ServeMux) {
+				mux.HandleFunc("/activities/6099369285", func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, "testdata/activity.json")
+				})
+				mux.HandleFunc("/activities/6099369285/streams/", func(w http.ResponseWriter, r *http.Request) {
+					http.ServeFile(w, r, "testdata/streams_export.json")
+				})
+			})
+			defer svr.Close()
+			export, err := client.Activity.ExportFormat(context.Background(), 6099369285, tt.format)
+			if tt.err != "" {
+				a.Error(err)
+				a.Nil(export)
+				a.Contains(err.Error(), tt.err)
+				return
+			}
+			a.NoError(err)
+			a.NotNil(export)
+			a.Equal(tt.format, export.Format)
+			if tt.format == activity.FormatFIT {
+				fit, ferr := export.FIT()
+				a.NoError(ferr)
+				a.NotEmpty(fit.Records)
+				a.Len(fit.Sessions, 1)
+			}
+			if tt.format == activity.FormatTCX {
+				tcx, terr := export.TCX()
+				a.NoError(terr)
+				a.Len(tcx.Activities, 1)
+			}
+		})
+	}
+}
+
 func TestWithDateRange(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -4,6 +4,8 @@ import (
 	"context"
 
 	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
 )
 
 // AuthService is the API for auth endpoints
@@ -15,3 +17,18 @@ func (s *AuthService) Refresh(ctx context.Context) (*oauth2.Token, error) {
 	t = oauth2.ReuseTokenSource(s.client.token, t)
 	return t.Token()
 }
+
+// NewAuthorizer returns an Authorizer for acquiring a token with the scopes
+//
+// More information on scopes can be found at https://developers.strava.com/docs/authentication/
+func NewAuthorizer(
+	clientID, clientSecret string, scopes []string, opts ...activity.AuthorizerOption) *activity.Authorizer {
+	config := oauth2.Config{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Endpoint:     Endpoint(),
+		Scopes:       scopes,
+	}
+	opts = append([]activity.AuthorizerOption{activity.WithAuthorizerScopeDelimiter(",")}, opts...)
+	return activity.NewAuthorizer(config, opts...)
+}
@@ -2,11 +2,16 @@ package strava_test
 
 import (
 	"context"
+	"errors"
 	"net/http"
+	"net/url"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
 	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
 )
 
 func TestRefresh(t *testing.T) {
@@ -42,3 +47,22 @@ func TestRefresh(t *testing.T) {
 		})
 	}
 }
+
+func TestNewAuthorizer(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	stop := errors.New("stop")
+	authorizer := strava.NewAuthorizer("client", "secret", []string{"read", "activity:read_all"},
+		activity.WithAuthorizerOpen(func(_ context.Context, uri string) error {
+			u, err := url.Parse(uri)
+			a.NoError(err)
+			a.Equal("www.strava.com", u.Host)
+			a.Equal("client", u.Query().Get("client_id"))
+			a.Equal("read,activity:read_all", u.Query().Get("scope"))
+			return stop
+		}))
+	token, err := authorizer.Authorize(context.Background())
+	a.Nil(token)
+	a.ErrorIs(err, stop)
+}
@@ -0,0 +1,373 @@
+package strava
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	dispatchWorkers       = 4
+	dispatchQueueSize     = 100
+	dispatchRetries       = 3
+	dispatchRetryInterval = time.Second
+)
+
+// ErrQueueFull is returned when a webhook message is received while the queue of the Dispatcher is full
+var ErrQueueFull = errors.New("webhook queue full")
+
+// ErrDispatcherClosed is returned when a webhook message is received after the Dispatcher is shut down
+var ErrDispatcherClosed = errors.New("webhook dispatcher closed")
+
+// AspectType is the kind of change of a webhook event
+type AspectType string
+
+const (
+	AspectCreate AspectType = "create"
+	AspectUpdate AspectType = "update"
+	AspectDelete AspectType = "delete"
+)
+
+// the object types of webhook events
+const (
+	objectActivity = "activity"
+	objectAthlete  = "athlete"
+)
+
+// ActivityUpdates are the changed fields of an updated activity, a nil field is unchanged
+type ActivityUpdates struct {
+	Title   *string
+	Type    *string
+	Private *bool
+}
+
+// ActivityEvent is the creation, update, or deletion of an activity
+type ActivityEvent struct {
+	ActivityID     int64
+	AthleteID      int
+	SubscriptionID int64
+	Aspect         AspectType
+	Time           time.Time
+	Updates        ActivityUpdates
+}
+
+// AthleteEvent is a change of an athlete's authorization of the application
+type AthleteEvent struct {
+	AthleteID      int
+	SubscriptionID int64
+	Aspect         AspectType
+	Time           time.Time
+	// Authorized is false if the athlete revoked access to the application, nil if unchanged
+	Authorized *bool
+}
+
+// Deauthorized is true if the athlete revoked access to the application
+func (e *AthleteEvent) Deauthorized() bool {
+	return e.Authorized != nil && !*e.Authorized
+}
+
+// ActivityEvent decodes the message as an activity event
+func (m *WebhookMessage) ActivityEvent() (*ActivityEvent, error) {
+	if m.ObjectType != objectActivity {
+		return nil, fmt.Errorf("object type '%s' is not an activity", m.ObjectType)
+	}
+	aspect := AspectType(m.AspectType)
+	switch aspect {
+	case AspectCreate, AspectUpdate, AspectDelete:
+	default:
+		return nil, fmt.Errorf("unknown aspect type '%s'", m.AspectType)
+	}
+	event := &ActivityEvent{
+		ActivityID:     m.ObjectID,
+		AthleteID:      m.OwnerID,
+		SubscriptionID: m.SubscriptionID,
+		Aspect:         aspect,
+		Time:           time.Unix(int64(m.EventTime), 0).UTC(),
+	}
+	for key, value := range m.Updates {
+		switch key {
+		case "title":
+			event.Updates.Title = &value
+		case "type":
+			event.Updates.Type = &value
+		case "private":
+			private, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid private update '%s'", value)
+			}
+			event.Updates.Private = &private
+		}
+	}
+	return event, nil
+}
+
+// AthleteEvent decodes the message as an athlete event
+func (m *WebhookMessage) AthleteEvent() (*AthleteEvent, error) {
+	if m.ObjectType != objectAthlete {
+		return nil, fmt.Errorf("object type '%s' is not an athlete", m.ObjectType)
+	}
+	event := &AthleteEvent{
+		AthleteID:      int(m.ObjectID),
+		SubscriptionID: m.SubscriptionID,
+		Aspect:         AspectType(m.AspectType),
+		Time:           time.Unix(int64(m.EventTime), 0).UTC(),
+	}
+	if value, ok := m.Updates["authorized"]; ok {
+		authorized, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid authorized update '%s'", value)
+		}
+		event.Authorized = &authorized
+	}
+	return event, nil
+}
+
+// ActivityHandler handles an activity event
+type ActivityHandler func(ctx context.Context, event *ActivityEvent) error
+
+// AthleteHandler handles an athlete event
+type AthleteHandler func(ctx context.Context, event *AthleteEvent) error
+
+// A DispatcherOption allows configuring a Dispatcher
+type DispatcherOption func(d *Dispatcher)
+
+// WithDispatchWorkers controls the number of events handled concurrently
+func WithDispatchWorkers(workers int) DispatcherOption {
+	return func(d *Dispatcher) {
+		if workers > 0 {
+			d.workers = workers
+		}
+	}
+}
+
+// WithDispatchQueueSize controls the number of events queued before messages are rejected
+func WithDispatchQueueSize(size int) DispatcherOption {
+	return func(d *Dispatcher) {
+		if size >= 0 {
+			d.size = size
+		}
+	}
+}
+
+// WithDispatchRetries controls the number of times a failed handler is retried
+//
+// The duration between attempts starts at the interval and doubles with each retry.
+func WithDispatchRetries(retries int, interval time.Duration) DispatcherOption {
+	return func(d *Dispatcher) {
+		if retries >= 0 {
+			d.retries = retries
+		}
+		if interval > 0 {
+			d.interval = interval
+		}
+	}
+}
+
+// WithDispatchVerifyToken sets the token Strava must send when requesting a subscription
+func WithDispatchVerifyToken(token string) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.verify = token
+	}
+}
+
+// WithDispatchError is called with a message which could not be decoded or whose handler
+// failed after all retries
+//
+// The function is called concurrently by the workers so it must be safe for concurrent use.
+func WithDispatchError(f func(msg *WebhookMessage, err error)) DispatcherOption {
+	return func(d *Dispatcher) {
+		if f != nil {
+			d.failure = f
+		}
+	}
+}
+
+// WithActivityCreateHandler handles the creation of activities
+func WithActivityCreateHandler(h ActivityHandler) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.activity[AspectCreate] = h
+	}
+}
+
+// WithActivityUpdateHandler handles updates of activities
+func WithActivityUpdateHandler(h ActivityHandler) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.activity[AspectUpdate] = h
+	}
+}
+
+// WithActivityDeleteHandler handles the deletion of activities
+func WithActivityDeleteHandler(h ActivityHandler) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.activity[AspectDelete] = h
+	}
+}
+
+// WithDeauthorizeHandler handles athletes revoking access to the application
+func WithDeauthorizeHandler(h AthleteHandler) DispatcherOption {
+	return func(d *Dispatcher) {
+		d.deauthorize = h
+	}
+}
+
+// Dispatcher is a WebhookSubscriber which decodes webhook messages into typed events and
+// routes them to handlers by a pool of workers
+//
+// Messages are queued and acknowledged immediately so slow handlers do not cause Strava to
+// time out and resend the message. Messages without a handler are acknowledged and discarded.
+type Dispatcher struct {
+	workers     int
+	size        int
+	retries     int
+	interval    time.Duration
+	verify      string
+	activity    map[AspectType]ActivityHandler
+	deauthorize AthleteHandler
+	failure     func(*WebhookMessage, error)
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	queue  chan *WebhookMessage
+	mu     sync.RWMutex
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewDispatcher returns a Dispatcher with its workers started
+func NewDispatcher(opts ...DispatcherOption) *Dispatcher {
+	d := &Dispatcher{
+		workers:  dispatchWorkers,
+		size:     dispatchQueueSize,
+		retries:  dispatchRetries,
+		interval: dispatchRetryInterval,
+		activity: make(map[AspectType]ActivityHandler),
+		failure:  func(*WebhookMessage, error) {},
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	d.ctx, d.cancel = context.WithCancel(context.Background())
+	d.queue = make(chan *WebhookMessage, d.size)
+	for range d.workers {
+		d.wg.Go(func() {
+			for msg := range d.queue {
+				d.dispatch(msg)
+			}
+		})
+	}
+	return d
+}
+
+// SubscriptionRequest verifies the token if one was configured
+func (d *Dispatcher) SubscriptionRequest(_, verify string) error {
+	if d.verify != "" && verify != d.verify {
+		return errors.New("invalid verify token")
+	}
+	return nil
+}
+
+// MessageReceived queues the message for dispatch without waiting for it to be handled
+//
+// An error is returned if the queue is full or the dispatcher is shut down so Strava will
+// resend the message.
+func (d *Dispatcher) MessageReceived(msg *WebhookMessage) error {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.closed {
+		return ErrDispatcherClosed
+	}
+	select {
+	case d.queue <- msg:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting messages and waits for the queued messages to be handled
+//
+// If the context ends first the context of running handlers is canceled and the context's error is
+// returned without waiting, the workers report the remaining messages as failed in the background.
+func (d *Dispatcher) Shutdown(ctx context.Context) error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		close(d.queue)
+	}
+	d.mu.Unlock()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		d.wg.Wait()
+	}()
+	select {
+	case <-done:
+		d.cancel()
+		return nil
+	case <-ctx.Done():
+		d.cancel()
+		return ctx.Err()
+	}
+}
+
+// dispatch the message to its handler retrying failures
+func (d *Dispatcher) dispatch(msg *WebhookMessage) {
+	handle, err := d.route(msg)
+	if err != nil {
+		d.failure(msg, err)
+		return
+	}
+	if handle == nil {
+		return
+	}
+	interval := d.interval
+	for attempt := 0; ; attempt++ {
+		if cerr := d.ctx.Err(); cerr != nil {
+			err = errors.Join(err, cerr)
+			break
+		}
+		if err = handle(d.ctx); err == nil || attempt == d.retries {
+			break
+		}
+		timer := time.NewTimer(interval)
+		select {
+		case <-d.ctx.Done():
+		case <-timer.C:
+		}
+		timer.Stop()
+		interval *= 2
+	}
+	if err != nil {
+		d.failure(msg, err)
+	}
+}
+
+// route returns the handler of the message or nil if no handler is configured
+func (d *Dispatcher) route(msg *WebhookMessage) (func(context.Context) error, error) {
+	switch msg.ObjectType {
+	case objectActivity:
+		event, err := msg.ActivityEvent()
+		if err != nil {
+			return nil, err
+		}
+		h := d.activity[event.Aspect]
+		if h == nil {
+			return nil, nil //nolint:nilnil // a message without a handler is not an error
+		}
+		return func(ctx context.Context) error { return h(ctx, event) }, nil
+	case objectAthlete:
+		event, err := msg.AthleteEvent()
+		if err != nil {
+			return nil, err
+		}
+		if d.deauthorize == nil || !event.Deauthorized() {
+			return nil, nil //nolint:nilnil // a message without a handler is not an error
+		}
+		return func(ctx context.Context) error { return d.deauthorize(ctx, event) }, nil
+	default:
+		return nil, fmt.Errorf("unknown object type '%s'", msg.ObjectType)
+	}
+}
@@ -0,0 +1,213 @@
+package strava_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity/strava"
+	"github.com/bzimmer/activity/strava/stravatest"
+)
+
+func TestWebhookMessageEvents(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	msg := &strava.WebhookMessage{
+		ObjectType:     "activity",
+		ObjectID:       1360128428,
+		AspectType:     "update",
+		OwnerID:        134815,
+		SubscriptionID: 120475,
+		EventTime:      1516126040,
+		Updates:        map[string]string{"title": "Messy", "type": "Ride", "private": "true"},
+	}
+	act, err := msg.ActivityEvent()
+	a.NoError(err)
+	a.Equal(int64(1360128428), act.ActivityID)
+	a.Equal(134815, act.AthleteID)
+	a.Equal(strava.AspectUpdate, act.Aspect)
+	a.Equal(time.Unix(1516126040, 0).UTC(), act.Time)
+	a.Equal("Messy", *act.Updates.Title)
+	a.Equal("Ride", *act.Updates.Type)
+	a.True(*act.Updates.Private)
+
+	msg.Updates = map[string]string{"private": "maybe"}
+	act, err = msg.ActivityEvent()
+	a.Error(err)
+	a.Nil(act)
+
+	msg.AspectType = "archive"
+	_, err = msg.ActivityEvent()
+	a.Error(err)
+
+	_, err = msg.AthleteEvent()
+	a.Error(err)
+
+	msg = &strava.WebhookMessage{
+		ObjectType: "athlete",
+		ObjectID:   134815,
+		AspectType: "update",
+		Updates:    map[string]string{"authorized": "false"},
+	}
+	ath, err := msg.AthleteEvent()
+	a.NoError(err)
+	a.Equal(134815, ath.AthleteID)
+	a.True(ath.Deauthorized())
+	msg.Updates = nil
+	ath, err = msg.AthleteEvent()
+	a.NoError(err)
+	a.Nil(ath.Authorized)
+	a.False(ath.Deauthorized())
+}
+
+type events struct {
+	mu         sync.Mutex
+	activities []*strava.ActivityEvent
+	athletes   []*strava.AthleteEvent
+	failures   []error
+}
+
+func (e *events) activity(_ context.Context, event *strava.ActivityEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.activities = append(e.activities, event)
+	return nil
+}
+
+func (e *events) athlete(_ context.Context, event *strava.AthleteEvent) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.athletes = append(e.athletes, event)
+	return nil
+}
+
+func (e *events) failure(_ *strava.WebhookMessage, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.failures = append(e.failures, err)
+}
+
+func TestDispatcher(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := stravatest.NewServer()
+	defer svr.Close()
+	client, err := svr.NewClient()
+	a.NoError(err)
+
+	ev := &events{}
+	// updates are blocked until released to show messages are acknowledged before being handled
+	release := make(chan struct{})
+	dispatcher := strava.NewDispatcher(
+		strava.WithDispatchVerifyToken("verify"),
+		strava.WithDispatchError(ev.failure),
+		strava.WithActivityCreateHandler(ev.activity),
+		strava.WithActivityUpdateHandler(func(ctx context.Context, event *strava.ActivityEvent) error {
+			<-release
+			return ev.activity(ctx, event)
+		}),
+		strava.WithDeauthorizeHandler(ev.athlete),
+	)
+	callback := httptest.NewServer(strava.NewWebhookHandler(dispatcher))
+	defer callback.Close()
+
+	ctx := context.Background()
+	_, err = client.Webhook.Subscribe(ctx, callback.URL, "other")
+	a.Error(err)
+	_, err = client.Webhook.Subscribe(ctx, callback.URL, "verify")
+	a.NoError(err)
+
+	for _, msg := range []*strava.WebhookMessage{
+		{ObjectType: "activity", ObjectID: 10, AspectType: "update", Updates: map[string]string{"title": "Messy"}},
+		{ObjectType: "activity", ObjectID: 11, AspectType: "create"},
+		// no handler for deletes
+		{ObjectType: "activity", ObjectID: 11, AspectType: "delete"},
+		{ObjectType: "athlete", ObjectID: 1, AspectType: "update", Updates: map[string]string{"authorized": "false"}},
+		{ObjectType: "club", ObjectID: 1, AspectType: "create"},
+	} {
+		a.NoError(svr.Push(ctx, msg))
+	}
+	close(release)
+	a.NoError(dispatcher.Shutdown(ctx))
+
+	a.Len(ev.activities, 2)
+	a.Len(ev.athletes, 1)
+	a.Equal(1, ev.athletes[0].AthleteID)
+	a.Len(ev.failures, 1)
+	a.ErrorContains(ev.failures[0], "unknown object type")
+
+	err = svr.Push(ctx, &strava.WebhookMessage{ObjectType: "activity", ObjectID: 12, AspectType: "create"})
+	a.Error(err)
+	a.True(errors.Is(dispatcher.MessageReceived(&strava.WebhookMessage{}), strava.ErrDispatcherClosed))
+}
+
+func TestDispatcherRetries(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var mu sync.Mutex
+	attempts := make(map[int64]int)
+	ev := &events{}
+	dispatcher := strava.NewDispatcher(
+		strava.WithDispatchRetries(2, time.Millisecond),
+		strava.WithDispatchError(ev.failure),
+		strava.WithActivityCreateHandler(func(_ context.Context, event *strava.ActivityEvent) error {
+			mu.Lock()
+			defer mu.Unlock()
+			attempts[event.ActivityID]++
+			// the first activity succeeds on the last retry while the second always fails
+			if event.ActivityID == 1 && attempts[event.ActivityID] == 3 {
+				return nil
+			}
+			return errors.New("unavailable")
+		}),
+	)
+	for id := range int64(2) {
+		a.NoError(dispatcher.MessageReceived(
+			&strava.WebhookMessage{ObjectType: "activity", ObjectID: id + 1, AspectType: "create"}))
+	}
+	a.NoError(dispatcher.Shutdown(context.Background()))
+	a.Equal(map[int64]int{1: 3, 2: 3}, attempts)
+	a.Len(ev.failures, 1)
+	a.ErrorContains(ev.failures[0], "unavailable")
+}
+
+func TestDispatcherBackpressure(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ev := &events{}
+	started := make(chan struct{})
+	dispatcher := strava.NewDispatcher(
+		strava.WithDispatchWorkers(1),
+		strava.WithDispatchQueueSize(0),
+		strava.WithDispatchError(ev.failure),
+		strava.WithActivityCreateHandler(func(ctx context.Context, _ *strava.ActivityEvent) error {
+			close(started)
+			<-ctx.Done()
+			return ctx.Err()
+		}),
+	)
+	msg := &strava.WebhookMessage{ObjectType: "activity", ObjectID: 1, AspectType: "create"}
+	// an unbuffered queue accepts a message only when the worker is waiting
+	a.Eventually(func() bool { return dispatcher.MessageReceived(msg) == nil }, time.Second, time.Millisecond)
+	<-started
+	a.True(errors.Is(dispatcher.MessageReceived(msg), strava.ErrQueueFull))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	a.True(errors.Is(dispatcher.Shutdown(ctx), context.DeadlineExceeded))
+	// the canceled handler is reported after shutdown returns
+	a.Eventually(func() bool {
+		ev.mu.Lock()
+		defer ev.mu.Unlock()
+		return len(ev.failures) == 1 && errors.Is(ev.failures[0], context.Canceled)
+	}, time.Second, time.Millisecond)
+}
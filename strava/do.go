@@ -0,0 +1,61 @@
+package strava
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bzimmer/activity"
+)
+
+// do executes the http request and populates v with the result
+//
+// The Fault of a failed request is classified using the error taxonomy of the activity package.
+func (c *Client) do(req *http.Request, v any) error {
+	return activity.Do(c.client, req, v, newStatusError)
+}
+
+// newStatusError classifies the Fault of the failed response including the details specific to Strava
+func newStatusError(res *http.Response) error {
+	fault := &Fault{Code: res.StatusCode, Message: http.StatusText(res.StatusCode)}
+	err := activity.NewResponseError(res, fault)
+	if res.StatusCode == http.StatusUnauthorized && fault.missingScope() {
+		return &activity.Error{Kind: activity.ErrMissingScope, StatusCode: res.StatusCode, Err: fault}
+	}
+	var rle *activity.RateLimitError
+	if errors.As(err, &rle) && rle.Reset.IsZero() {
+		rle.Reset = rateLimitReset(res.Header, time.Now())
+	}
+	return err
+}
+
+// missingScope returns true if the fault is caused by a missing permission
+//
+// Strava reports missing permissions as unauthorized with an error such as:
+//
+//	{"resource": "AccessToken", "field": "activity:read_permission", "code": "missing"}
+func (f *Fault) missingScope() bool {
+	for _, e := range f.Errors {
+		if e.Code == "missing" && strings.HasSuffix(e.Field, "_permission") {
+			return true
+		}
+	}
+	return false
+}
+
+// rateLimitReset returns the time at which the exceeded rate limit resets
+//
+// If the headers do not report an exceeded limit the short term limit is assumed.
+func rateLimitReset(header http.Header, now time.Time) time.Time {
+	var reset time.Time
+	for _, limit := range RateLimits(header, now) {
+		if limit.Exceeded() && limit.Reset.After(reset) {
+			reset = limit.Reset
+		}
+	}
+	if reset.IsZero() {
+		reset = now.UTC().Truncate(rateLimitWindow).Add(rateLimitWindow)
+	}
+	return reset
+}
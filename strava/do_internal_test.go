@@ -0,0 +1,28 @@
+package strava
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitReset(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	now := time.Date(2026, time.March, 1, 12, 7, 30, 0, time.UTC)
+	header := make(http.Header)
+	a.Equal(time.Date(2026, time.March, 1, 12, 15, 0, 0, time.UTC), rateLimitReset(header, now))
+
+	header.Set("X-RateLimit-Limit", "200,2000")
+	header.Set("X-RateLimit-Usage", "201,1500")
+	a.Equal(time.Date(2026, time.March, 1, 12, 15, 0, 0, time.UTC), rateLimitReset(header, now))
+
+	header.Set("X-RateLimit-Usage", "150,2001")
+	a.Equal(time.Date(2026, time.March, 2, 0, 0, 0, 0, time.UTC), rateLimitReset(header, now))
+
+	header.Set("X-RateLimit-Usage", "invalid")
+	a.Equal(time.Date(2026, time.March, 1, 12, 15, 0, 0, time.UTC), rateLimitReset(header, now))
+}
@@ -0,0 +1,117 @@
+package strava_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestDoErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name   string
+		status int
+		body   string
+		kind   error
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   `{"message": "Record Not Found", "errors": [{"resource": "Athlete", "field": "id", "code": "invalid"}]}`,
+			kind:   activity.ErrNotFound,
+		},
+		{
+			name:   "unauthorized",
+			status: http.StatusUnauthorized,
+			body:   `{"message": "Authorization Error"}`,
+			kind:   activity.ErrUnauthorized,
+		},
+		{
+			name:   "missing scope",
+			status: http.StatusUnauthorized,
+			body: `{"message": "Authorization Error", "errors": ` +
+				`[{"resource": "AccessToken", "field": "activity:read_permission", "code": "missing"}]}`,
+			kind: activity.ErrMissingScope,
+		},
+		{
+			name:   "server",
+			status: http.StatusInternalServerError,
+			body:   `not json`,
+			kind:   activity.ErrServer,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			client, svr := newClientMust(func(mux *http.ServeMux) {
+				mux.HandleFunc("/athlete", func(w http.ResponseWriter, _ *http.Request) {
+					w.WriteHeader(tt.status)
+					_, err := w.Write([]byte(tt.body))
+					a.NoError(err)
+				})
+			})
+			defer svr.Close()
+			ath, err := client.Athlete.Athlete(context.TODO())
+			a.Nil(ath)
+			a.ErrorIs(err, tt.kind)
+			var fault *strava.Fault
+			a.ErrorAs(err, &fault)
+			a.Equal(tt.status, fault.Code)
+			a.NotEmpty(fault.Message)
+		})
+	}
+}
+
+func TestDoRateLimited(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/athlete", func(w http.ResponseWriter, _ *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "200,2000")
+			w.Header().Set("X-RateLimit-Usage", "150,2000")
+			w.WriteHeader(http.StatusTooManyRequests)
+			_, err := w.Write([]byte(`{"message": "Rate Limit Exceeded"}`))
+			a.NoError(err)
+		})
+	})
+	defer svr.Close()
+	_, err := client.Athlete.Athlete(context.TODO())
+	a.ErrorIs(err, activity.ErrRateLimited)
+	var rle *activity.RateLimitError
+	a.ErrorAs(err, &rle)
+	// the daily limit is exceeded so the limit resets at midnight
+	a.Zero(rle.Reset.Hour())
+	a.Zero(rle.Reset.Minute())
+	a.EqualError(err, "Rate Limit Exceeded")
+}
+
+func TestUploadInvalidFile(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/uploads", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusBadRequest)
+			_, err := w.Write([]byte(`{"message": "Bad Request", ` +
+				`"errors": [{"resource": "Upload", "field": "data", "code": "empty"}]}`))
+			a.NoError(err)
+		})
+	})
+	defer svr.Close()
+	file := &activity.File{Reader: http.NoBody, Name: "ride.fit", Format: activity.FormatFIT}
+	upload, err := client.Activity.Upload(context.TODO(), file)
+	a.Nil(upload)
+	a.ErrorIs(err, activity.ErrInvalidFile)
+	a.EqualError(err, "Bad Request")
+
+	a.ErrorIs((&strava.Upload{Error: "Improperly formatted data."}).Err(), activity.ErrInvalidFile)
+	a.ErrorIs((&strava.Upload{Error: "ride.fit duplicate of activity 123"}).Err(), activity.ErrDuplicate)
+}
@@ -3,8 +3,10 @@ package strava
 import (
 	"errors"
 	"fmt"
+	"math"
 	"time"
 
+	"github.com/martinlindhe/unit"
 	"github.com/twpayne/go-geom"
 	"github.com/twpayne/go-gpx"
 	"github.com/twpayne/go-polyline"
@@ -14,6 +16,8 @@ import (
 
 var _ activity.GPXEncoder = (*Route)(nil)
 var _ activity.GPXEncoder = (*Activity)(nil)
+var _ activity.SeriesEncoder = (*Activity)(nil)
+var _ activity.CourseEncoder = (*Route)(nil)
 
 func polylineToLineString(polylines ...string) (*geom.LineString, error) {
 	const n = 2
@@ -87,6 +91,32 @@ func (r *Route) GPX() (*gpx.GPX, error) {
 	return x, nil
 }
 
+// Course representation of a route
+func (r *Route) Course() (*activity.Course, error) {
+	if r.Map == nil {
+		return nil, errors.New("no map available for course encoding")
+	}
+	ls, err := r.Map.LineString()
+	if err != nil {
+		return nil, err
+	}
+	n := ls.NumCoords()
+	c := &activity.Course{
+		Name:      r.Name,
+		Sport:     activity.SportCycling,
+		Latitude:  make([]float64, n),
+		Longitude: make([]float64, n),
+	}
+	if r.Type == routeTypeRun {
+		c.Sport = activity.SportRunning
+	}
+	for i := range n {
+		coord := ls.Coord(i)
+		c.Latitude[i], c.Longitude[i] = coord.Y(), coord.X()
+	}
+	return c, nil
+}
+
 func (a *Activity) toGPXFromStreams() (*gpx.GPX, error) {
 	if a.Streams == nil {
 		return nil, errors.New("no streams available for gpx encoding")
@@ -126,3 +156,70 @@ func (a *Activity) toGPXFromStreams() (*gpx.GPX, error) {
 	}
 	return x, nil
 }
+
+// Series representation of an activity
+func (a *Activity) Series() (*activity.Series, error) {
+	if a.Streams == nil {
+		return nil, errors.New("no streams available for series encoding")
+	}
+	return a.Streams.Series(a.StartDate)
+}
+
+// Series representation of the streams with the time stream offset from `start`
+func (s *Streams) Series(start time.Time) (*activity.Series, error) {
+	if s.Time == nil {
+		return nil, errors.New("time stream is required for series encoding")
+	}
+	n := len(s.Time.Data)
+	x := &activity.Series{StartTime: start, Time: make([]unit.Duration, n)}
+	for i, t := range s.Time.Data {
+		x.Time[i] = unit.Duration(t) * unit.Second
+	}
+	if s.LatLng != nil {
+		x.Latitude = make([]float64, len(s.LatLng.Data))
+		x.Longitude = make([]float64, len(s.LatLng.Data))
+		for i, c := range s.LatLng.Data {
+			x.Latitude[i], x.Longitude[i] = math.NaN(), math.NaN()
+			if len(c) == 2 {
+				x.Latitude[i], x.Longitude[i] = c[0], c[1]
+			}
+		}
+	}
+	if s.Elevation != nil {
+		x.Elevation = s.Elevation.Data
+	}
+	if s.Distance != nil {
+		x.Distance = s.Distance.Data
+	}
+	if s.Velocity != nil {
+		x.Speed = s.Velocity.Data
+	}
+	if s.HeartRate != nil {
+		x.HeartRate = s.HeartRate.Data
+	}
+	if s.Cadence != nil {
+		x.Cadence = s.Cadence.Data
+	}
+	if s.Watts != nil {
+		x.Power = make([]unit.Power, len(s.Watts.Data))
+		for i, w := range s.Watts.Data {
+			x.Power[i] = unit.Power(w) * unit.Watt
+		}
+	}
+	if s.Temperature != nil {
+		x.Temperature = make([]unit.Temperature, len(s.Temperature.Data))
+		for i, t := range s.Temperature.Data {
+			x.Temperature[i] = unit.FromCelsius(t)
+		}
+	}
+	if s.Grade != nil {
+		x.Grade = s.Grade.Data
+	}
+	if s.Moving != nil {
+		x.Moving = s.Moving.Data
+	}
+	if err := x.Validate(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
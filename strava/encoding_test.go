@@ -7,6 +7,8 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
 )
 
 func TestGPXRoute(t *testing.T) {
@@ -150,3 +152,80 @@ func TestGPXActivity(t *testing.T) {
 		})
 	}
 }
+
+func TestSeriesActivity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/activities/66282823", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/activity_with_polyline.json")
+		})
+		mux.HandleFunc("/activities/66282823/streams/latlng,altitude,time,distance",
+			func(w http.ResponseWriter, r *http.Request) {
+				http.ServeFile(w, r, "testdata/streams.json")
+			})
+	})
+	defer svr.Close()
+
+	act, err := client.Activity.Activity(context.Background(), 66282823)
+	a.NoError(err)
+	series, err := act.Series()
+	a.Error(err)
+	a.Nil(series)
+
+	act, err = client.Activity.Activity(context.Background(), 66282823, "latlng", "altitude", "time", "distance")
+	a.NoError(err)
+	series, err = act.Series()
+	a.NoError(err)
+	a.NotNil(series)
+	a.Equal(1405, series.Len())
+	a.Equal(act.StartDate, series.StartTime)
+	a.Equal(act.Streams.LatLng.Data[10][0], series.Latitude[10])
+	a.Equal(act.Streams.LatLng.Data[10][1], series.Longitude[10])
+	a.Equal(act.Streams.Elevation.Data[10], series.Elevation[10])
+	a.Equal(act.Streams.Distance.Data[10], series.Distance[10])
+	a.InDelta(act.Streams.Time.Data[10], series.Time[10].Seconds(), 0.001)
+	a.Nil(series.Power)
+
+	act.Streams.Time = nil
+	series, err = act.Series()
+	a.Error(err)
+	a.Nil(series)
+}
+
+func TestCourseRoute(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/routes/26587226", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/route.json")
+		})
+	})
+	defer svr.Close()
+	rte, err := client.Route.Route(context.Background(), 26587226)
+	a.NoError(err)
+
+	course, err := rte.Course()
+	a.NoError(err)
+	a.NoError(course.Validate())
+	a.Equal(rte.Name, course.Name)
+	a.Equal(activity.SportCycling, course.Sport)
+	a.Equal(2076, course.Len())
+
+	gpx, err := rte.GPX()
+	a.NoError(err)
+	a.Equal(gpx.Rte[0].RtePt[10].Lat, course.Latitude[10])
+	a.Equal(gpx.Rte[0].RtePt[10].Lon, course.Longitude[10])
+
+	rte.Type = 2
+	course, err = rte.Course()
+	a.NoError(err)
+	a.Equal(activity.SportRunning, course.Sport)
+
+	rte.Map = nil
+	course, err = rte.Course()
+	a.Error(err)
+	a.Nil(course)
+}
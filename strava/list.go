@@ -0,0 +1,27 @@
+package strava
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+type lister struct {
+	s *ActivityService
+}
+
+func newLister(s *ActivityService) activity.Lister {
+	return &lister{s: s}
+}
+
+// List returns summaries of the authenticated athlete's activities
+func (l *lister) List(ctx context.Context, spec activity.Pagination) ([]*activity.Summary, error) {
+	var summaries []*activity.Summary
+	for act, err := range l.s.ActivitiesSeq(ctx, spec) {
+		if err != nil {
+			return nil, err
+		}
+		summaries = append(summaries, act.Summarize())
+	}
+	return summaries, nil
+}
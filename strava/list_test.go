@@ -0,0 +1,56 @@
+package strava_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.Handle("/athlete/activities", &ManyHandler{
+			Total:    3,
+			Filename: "testdata/activity.json",
+		})
+	})
+	defer svr.Close()
+
+	summaries, err := client.Lister().List(context.Background(), activity.Pagination{Total: 5})
+	a.NoError(err)
+	a.Len(summaries, 3)
+	for _, s := range summaries {
+		a.Equal("strava", s.Provider)
+		a.NotZero(s.ID)
+	}
+
+	client, svr = newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/athlete/activities", func(w http.ResponseWriter, _ *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		})
+	})
+	defer svr.Close()
+	summaries, err = client.Lister().List(context.Background(), activity.Pagination{Total: 5})
+	a.Error(err)
+	a.Nil(summaries)
+}
+
+func TestUploadOutcome(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var upload activity.Outcome = &strava.Upload{ActivityID: 54321}
+	a.Equal(int64(54321), upload.ActivityIdentifier())
+	a.NoError(upload.Err())
+
+	upload = &strava.Upload{Error: "duplicate of activity 1122"}
+	a.Zero(upload.ActivityIdentifier())
+	a.EqualError(upload.Err(), "duplicate of activity 1122")
+}
@@ -1,6 +1,9 @@
 package strava
 
 import (
+	"errors"
+	"regexp"
+	"strconv"
 	"time"
 
 	"github.com/martinlindhe/unit"
@@ -436,6 +439,9 @@ type Activity struct {
 	Streams                  *Streams               `json:"streams,omitempty"`
 }
 
+// routeTypeRun is the route type for runs, all other route types are rides
+const routeTypeRun = 2
+
 // Route is a planned activity
 type Route struct {
 	Private             bool          `json:"private"`
@@ -507,6 +513,26 @@ func (u *Upload) Done() bool {
 	return u.ActivityID > 0 || u.Error != ""
 }
 
+// ActivityIdentifier is the id of the created activity, 0 until processing completes
+func (u *Upload) ActivityIdentifier() int64 {
+	return u.ActivityID
+}
+
+// duplicateRE matches the id of the existing activity in a duplicate upload error
+var duplicateRE = regexp.MustCompile(`duplicate of\D*(\d+)`)
+
+// Err is the processing error of the upload, a DuplicateError if the activity already exists
+func (u *Upload) Err() error {
+	if u.Error == "" {
+		return nil
+	}
+	if m := duplicateRE.FindStringSubmatch(u.Error); m != nil {
+		id, _ := strconv.ParseInt(m[1], 10, 64)
+		return &activity.DuplicateError{ActivityID: id, Message: u.Error}
+	}
+	return &activity.Error{Kind: activity.ErrInvalidFile, Err: errors.New(u.Error)}
+}
+
 // UploadResult is the result of polling for upload status
 type UploadResult struct {
 	Upload *Upload `json:"upload"`
@@ -0,0 +1,33 @@
+package strava
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+// Provider returns the registration of Strava for an activity.Registry
+func Provider() *activity.Provider {
+	return &activity.Provider{
+		Name: "strava",
+		Capabilities: []activity.Capability{
+			activity.CapabilityUpload, activity.CapabilityExport, activity.CapabilityList,
+			activity.CapabilityRoutes, activity.CapabilityWebhooks,
+		},
+		New: func(ctx context.Context, config activity.ProviderConfig) (any, error) {
+			opts := []Option{
+				WithClientCredentials(config.ClientID, config.ClientSecret),
+				WithTokenCredentials(config.AccessToken, config.RefreshToken, config.Expiry),
+			}
+			if config.BaseURL != "" {
+				opts = append(opts, WithBaseURL(config.BaseURL))
+			}
+			if config.TokenFile != "" {
+				opts = append(opts, WithTokenStore(ctx, activity.NewFileTokenStore(config.TokenFile)))
+			}
+			// auto refresh must follow the token credentials and store
+			opts = append(opts, WithAutoRefresh(ctx))
+			return NewClient(opts...)
+		},
+	}
+}
@@ -0,0 +1,39 @@
+package strava_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer access", r.Header.Get("Authorization"))
+		http.ServeFile(w, r, "testdata/athlete.json")
+	}))
+	defer svr.Close()
+
+	provider := strava.Provider()
+	a.Equal("strava", provider.Name)
+	a.True(provider.Supports(activity.CapabilityWebhooks))
+	client, err := provider.New(context.Background(), activity.ProviderConfig{
+		ClientID:    "client",
+		AccessToken: "access",
+		Expiry:      time.Now().Add(time.Hour),
+		BaseURL:     svr.URL,
+	})
+	a.NoError(err)
+	ath, err := client.(*strava.Client).Athlete.Athlete(context.Background())
+	a.NoError(err)
+	a.NotNil(ath)
+}
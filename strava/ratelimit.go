@@ -0,0 +1,55 @@
+package strava
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzimmer/activity"
+)
+
+// rateLimitWindow is the duration of the short term rate limit
+const rateLimitWindow = 15 * time.Minute
+
+// RateLimits returns the short term and daily rate limits reported in the headers of a response
+//
+// The short term limit resets at each quarter hour and the daily limit at midnight UTC.
+// More information can be found at https://developers.strava.com/docs/rate-limits/
+//
+// Use it as the Parser of an activity.RetryTransport to enforce the limits.
+func RateLimits(header http.Header, now time.Time) []activity.RateLimit {
+	limit := rateLimitValues(header.Get("X-RateLimit-Limit"))
+	usage := rateLimitValues(header.Get("X-RateLimit-Usage"))
+	if len(limit) != 2 || len(usage) != 2 {
+		return nil
+	}
+	now = now.UTC()
+	return []activity.RateLimit{
+		{
+			Window: rateLimitWindow,
+			Limit:  limit[0],
+			Usage:  usage[0],
+			Reset:  now.Truncate(rateLimitWindow).Add(rateLimitWindow),
+		},
+		{
+			Window: 24 * time.Hour,
+			Limit:  limit[1],
+			Usage:  usage[1],
+			Reset:  time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC),
+		},
+	}
+}
+
+// rateLimitValues parses the comma separated short term and daily values of a rate limit header
+func rateLimitValues(value string) []int {
+	var values []int
+	for x := range strings.SplitSeq(value, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(x))
+		if err != nil {
+			return nil
+		}
+		values = append(values, n)
+	}
+	return values
+}
@@ -0,0 +1,71 @@
+package strava_test
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestRateLimits(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	now := time.Date(2021, time.March, 7, 13, 22, 5, 0, time.UTC)
+	header := make(http.Header)
+	a.Nil(strava.RateLimits(header, now))
+
+	header.Set("X-RateLimit-Limit", "200,2000")
+	header.Set("X-RateLimit-Usage", "x,10")
+	a.Nil(strava.RateLimits(header, now))
+
+	header.Set("X-RateLimit-Usage", "20, 150")
+	limits := strava.RateLimits(header, now)
+	a.Len(limits, 2)
+	a.Equal(15*time.Minute, limits[0].Window)
+	a.Equal(200, limits[0].Limit)
+	a.Equal(20, limits[0].Usage)
+	a.Equal(time.Date(2021, time.March, 7, 13, 30, 0, 0, time.UTC), limits[0].Reset)
+	a.Equal(24*time.Hour, limits[1].Window)
+	a.Equal(2000, limits[1].Limit)
+	a.Equal(150, limits[1].Usage)
+	a.Equal(time.Date(2021, time.March, 8, 0, 0, 0, 0, time.UTC), limits[1].Reset)
+}
+
+func TestRateLimitsRetryTransport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	transport := &activity.RetryTransport{Parser: strava.RateLimits, Backoff: time.Millisecond}
+	var n atomic.Int32
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/athlete", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-RateLimit-Limit", "200,2000")
+			if n.Add(1) == 1 {
+				w.Header().Set("X-RateLimit-Usage", "199,300")
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusTooManyRequests)
+				return
+			}
+			w.Header().Set("X-RateLimit-Usage", "12,300")
+			http.ServeFile(w, r, "testdata/athlete.json")
+		})
+	}, strava.WithTransport(transport))
+	defer svr.Close()
+
+	ath, err := client.Athlete.Athlete(context.TODO())
+	a.NoError(err)
+	a.NotNil(ath)
+	a.Equal(int32(2), n.Load())
+
+	usage := transport.Usage()
+	a.Len(usage, 2)
+	a.Equal(12, usage[0].Usage)
+	a.Equal(300, usage[1].Usage)
+}
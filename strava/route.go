@@ -3,6 +3,7 @@ package strava
 import (
 	"context"
 	"fmt"
+	"iter"
 	"net/http"
 
 	"github.com/bzimmer/activity"
@@ -11,46 +12,26 @@ import (
 // RouteService is the API for route endpoints
 type RouteService service
 
-type routePaginator struct {
-	athleteID int
-	routes    []*Route
-	service   RouteService
-}
-
-func (p *routePaginator) PageSize() int {
-	return PageSize
-}
-
-func (p *routePaginator) Count() int {
-	return len(p.routes)
-}
-
-func (p *routePaginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
-	uri := fmt.Sprintf("athletes/%d/routes?page=%d&per_page=%d", p.athleteID, spec.Start, spec.Count)
-	req, err := p.service.client.newAPIRequest(ctx, http.MethodGet, uri, nil)
-	if err != nil {
-		return 0, err
-	}
-	var rts []*Route
-	err = p.service.client.do(req, &rts)
-	if err != nil {
-		return 0, err
-	}
-	if spec.Total > 0 && len(p.routes)+len(rts) > spec.Total {
-		rts = rts[:spec.Total-len(p.routes)]
-	}
-	p.routes = append(p.routes, rts...)
-	return len(rts), nil
-}
-
 // Routes returns a page of routes for an athlete
 func (s *RouteService) Routes(ctx context.Context, athleteID int, spec activity.Pagination) ([]*Route, error) {
-	p := &routePaginator{service: *s, athleteID: athleteID, routes: make([]*Route, 0)}
-	err := activity.Paginate(ctx, p, spec)
-	if err != nil {
-		return nil, err
-	}
-	return p.routes, nil
+	return activity.Collect(s.RoutesSeq(ctx, athleteID, spec))
+}
+
+// RoutesSeq returns an iterator over the routes for an athlete
+func (s *RouteService) RoutesSeq(
+	ctx context.Context, athleteID int, spec activity.Pagination) iter.Seq2[*Route, error] {
+	return activity.Iter(ctx, PageSize, func(ctx context.Context, spec activity.Pagination) ([]*Route, error) {
+		uri := fmt.Sprintf("athletes/%d/routes?page=%d&per_page=%d", athleteID, spec.Start, spec.Count)
+		req, err := s.client.newAPIRequest(ctx, http.MethodGet, uri, nil)
+		if err != nil {
+			return nil, err
+		}
+		var rts []*Route
+		if err = s.client.do(req, &rts); err != nil {
+			return nil, err
+		}
+		return rts, nil
+	}, spec)
 }
 
 // Route returns a route
@@ -2,10 +2,16 @@ package strava_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
+	"os"
+	"strconv"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"golang.org/x/time/rate"
 
 	"github.com/bzimmer/activity"
 	"github.com/bzimmer/activity/strava"
@@ -114,3 +120,61 @@ func TestRoutes(t *testing.T) {
 		})
 	}
 }
+
+func TestRoutesSeq(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var requests int
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		handler := &ManyHandler{Filename: "testdata/route.json"}
+		mux.HandleFunc("/athletes/26587226/routes", func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			handler.ServeHTTP(w, r)
+		})
+	})
+	defer svr.Close()
+
+	var n int
+	for route, err := range client.Route.RoutesSeq(context.TODO(), 26587226, activity.Pagination{Count: 10}) {
+		a.NoError(err)
+		a.NotNil(route)
+		n++
+		if n == 25 {
+			break
+		}
+	}
+	a.Equal(25, n)
+	a.Equal(3, requests)
+}
+
+func TestRoutesSeqPrefetch(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data, err := os.ReadFile("testdata/route.json")
+	a.NoError(err)
+	var requests atomic.Int32
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/athletes/26587226/routes", func(w http.ResponseWriter, r *http.Request) {
+			requests.Add(1)
+			routes := make([]json.RawMessage, 0)
+			if r.URL.Query().Get("page") != "5" {
+				n, aerr := strconv.Atoi(r.URL.Query().Get("per_page"))
+				a.NoError(aerr)
+				for range n {
+					routes = append(routes, data)
+				}
+			}
+			// prefetched requests are canceled once iteration ends so the write may fail
+			_ = json.NewEncoder(w).Encode(routes)
+		})
+	}, strava.WithRateLimiter(rate.NewLimiter(rate.Every(time.Millisecond), 1)))
+	defer svr.Close()
+
+	// prefetched requests pass through the rate limiter of the client
+	routes, err := client.Route.Routes(context.TODO(), 26587226, activity.Pagination{Count: 10, Prefetch: 3})
+	a.NoError(err)
+	a.Len(routes, 40)
+	a.LessOrEqual(requests.Load(), int32(8))
+}
@@ -1,6 +1,6 @@
 package strava
 
-//go:generate genwith --do --client --endpoint-func --config --token --ratelimit --package strava
+//go:generate genwith --client --endpoint-func --config --ratelimit --package strava
 
 import (
 	"bytes"
@@ -40,6 +40,7 @@ type Client struct {
 	token   *oauth2.Token
 	config  oauth2.Config
 	baseURL string
+	hooks   activity.RefreshHooks
 
 	Auth     *AuthService
 	Route    *RouteService
@@ -53,6 +54,11 @@ func (c *Client) Uploader() activity.Uploader {
 	return newUploader(c.Activity)
 }
 
+// Lister returns a Lister for this client
+func (c *Client) Lister() activity.Lister {
+	return newLister(c.Activity)
+}
+
 // Exporter returns an Exporter for this client
 func (c *Client) Exporter() activity.Exporter {
 	return c.Activity
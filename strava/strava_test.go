@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
 	"strconv"
 	"testing"
 	"time"
@@ -13,6 +14,7 @@ import (
 	"golang.org/x/oauth2"
 	"golang.org/x/time/rate"
 
+	"github.com/bzimmer/activity"
 	"github.com/bzimmer/activity/strava"
 )
 
@@ -166,3 +168,34 @@ func TestOptions(t *testing.T) {
 		})
 	}
 }
+
+func TestRecorder(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	cassette := filepath.Join(t.TempDir(), "athlete.json")
+	rec, err := activity.NewRecorder(cassette, activity.RecorderRecord)
+	a.NoError(err)
+	client, svr := newClientMust(func(mux *http.ServeMux) {
+		mux.HandleFunc("/athlete", func(w http.ResponseWriter, r *http.Request) {
+			http.ServeFile(w, r, "testdata/athlete.json")
+		})
+	}, strava.WithTransport(rec), strava.WithTokenCredentials("0123456789abcdef", "", time.Time{}))
+	ath, err := client.Athlete.Athlete(context.TODO())
+	a.NoError(err)
+	a.Equal(1122, ath.ID)
+	svr.Close()
+
+	data, err := os.ReadFile(cassette)
+	a.NoError(err)
+	a.NotContains(string(data), "0123456789abcdef")
+
+	rep, err := activity.NewRecorder(cassette, activity.RecorderReplay)
+	a.NoError(err)
+	client, err = strava.NewClient(
+		strava.WithBaseURL(svr.URL), strava.WithTransport(rep), strava.WithTokenCredentials("other", "", time.Time{}))
+	a.NoError(err)
+	ath, err = client.Athlete.Athlete(context.TODO())
+	a.NoError(err)
+	a.Equal(1122, ath.ID)
+}
@@ -0,0 +1,166 @@
+package stravatest
+
+import (
+	"cmp"
+	"encoding/json"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/strava"
+)
+
+// AddActivity adds an activity of the authenticated athlete returning its id
+//
+// An id is assigned if the activity does not have one. The streams of the activity, if any, are
+// returned by the streams endpoint.
+func (s *Server) AddActivity(act *strava.Activity) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := *act
+	if x.ID == 0 {
+		x.ID = s.ids.Next()
+	}
+	if x.Athlete == nil {
+		x.Athlete = &strava.Athlete{ID: s.athlete.ID, ResourceState: 1}
+	}
+	if x.Streams != nil {
+		s.streams[x.ID] = x.Streams
+		x.Streams = nil
+	}
+	s.activities[x.ID] = &x
+	return x.ID
+}
+
+// Activity returns a copy of the activity or nil if it does not exist
+func (s *Server) Activity(activityID int64) *strava.Activity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	act, ok := s.activities[activityID]
+	if !ok {
+		return nil
+	}
+	x := *act
+	return &x
+}
+
+// listActivities returns the athlete's activities newest first
+func (s *Server) listActivities(w http.ResponseWriter, r *http.Request) {
+	var before, after time.Time
+	q := r.URL.Query()
+	if v := q.Get("before"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			fault(w, http.StatusBadRequest, "Bad Request", "Activity", "before", "invalid")
+			return
+		}
+		before = time.Unix(n, 0)
+	}
+	if v := q.Get("after"); v != "" {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			fault(w, http.StatusBadRequest, "Bad Request", "Activity", "after", "invalid")
+			return
+		}
+		after = time.Unix(n, 0)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acts := make([]*strava.Activity, 0, len(s.activities))
+	for _, act := range s.activities {
+		if !before.IsZero() && !act.StartDate.Before(before) {
+			continue
+		}
+		if !after.IsZero() && !act.StartDate.After(after) {
+			continue
+		}
+		x := *act
+		x.ResourceState = 2
+		acts = append(acts, &x)
+	}
+	slices.SortFunc(acts, func(a, b *strava.Activity) int {
+		return cmp.Or(b.StartDate.Compare(a.StartDate), cmp.Compare(b.ID, a.ID))
+	})
+	fakeserver.Encode(w, http.StatusOK, paginate(acts, r))
+}
+
+func (s *Server) getActivity(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathID(r)
+	act := s.Activity(id)
+	if act == nil {
+		notFound(w, "Activity")
+		return
+	}
+	act.ResourceState = 3
+	fakeserver.Encode(w, http.StatusOK, act)
+}
+
+// updateActivity applies the updatable fields of the activity
+func (s *Server) updateActivity(w http.ResponseWriter, r *http.Request) {
+	var update strava.UpdatableActivity
+	if err := json.NewDecoder(r.Body).Decode(&update); err != nil {
+		fault(w, http.StatusBadRequest, "Bad Request", "Activity", "body", "invalid")
+		return
+	}
+	id, _ := pathID(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	act, ok := s.activities[id]
+	if !ok {
+		notFound(w, "Activity")
+		return
+	}
+	set(&act.Commute, update.Commute)
+	set(&act.Trainer, update.Trainer)
+	set(&act.Hidden, update.Hidden)
+	set(&act.Description, update.Description)
+	set(&act.Name, update.Name)
+	set(&act.GearID, update.GearID)
+	if update.SportType != nil {
+		act.SportType, act.Type = *update.SportType, *update.SportType
+	}
+	x := *act
+	x.ResourceState = 3
+	fakeserver.Encode(w, http.StatusOK, &x)
+}
+
+func set[T any](dst *T, src *T) {
+	if src != nil {
+		*dst = *src
+	}
+}
+
+// getStreams returns the requested streams keyed by type, unavailable streams are omitted
+func (s *Server) getStreams(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathID(r)
+	s.mu.Lock()
+	_, ok := s.activities[id]
+	sts := s.streams[id]
+	s.mu.Unlock()
+	if !ok {
+		notFound(w, "Activity")
+		return
+	}
+	keys := slices.DeleteFunc(strings.Split(r.PathValue("keys"), ","), func(key string) bool {
+		return key == "activity_id"
+	})
+	res, err := fakeserver.Fields(sts, keys)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, res)
+}
+
+// getPhotos returns no photos for an existing activity
+func (s *Server) getPhotos(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathID(r)
+	if s.Activity(id) == nil {
+		notFound(w, "Activity")
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, []*strava.Photo{})
+}
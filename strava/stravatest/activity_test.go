@@ -0,0 +1,95 @@
+package stravatest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestActivities(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	for i := range 5 {
+		svr.AddActivity(&strava.Activity{Name: "ride", StartDate: start.AddDate(0, 0, i)})
+	}
+
+	var acts []*strava.Activity
+	for act, err := range client.Activity.ActivitiesSeq(context.Background(), activity.Pagination{Count: 2}) {
+		a.NoError(err)
+		acts = append(acts, act)
+	}
+	a.Len(acts, 5)
+	// newest first
+	a.Equal(start.AddDate(0, 0, 4), acts[0].StartDate)
+	a.Equal(start, acts[4].StartDate)
+
+	acts = acts[:0]
+	for act, err := range client.Activity.ActivitiesSeq(context.Background(), activity.Pagination{},
+		strava.WithDateRange(start.AddDate(0, 0, 3), start)) {
+		a.NoError(err)
+		acts = append(acts, act)
+	}
+	a.Len(acts, 2)
+	a.Equal(start.AddDate(0, 0, 2), acts[0].StartDate)
+}
+
+func TestActivity(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	id := svr.AddActivity(&strava.Activity{
+		Name:      "Morning Ride",
+		Type:      "Ride",
+		StartDate: start,
+		Streams: &strava.Streams{
+			LatLng:    &strava.CoordinateStream{Data: []strava.Coordinates{{47.6, -122.3}, {47.7, -122.3}}},
+			Time:      &strava.Stream{Data: []float64{0, 60}},
+			Elevation: &strava.LengthStream{Data: []unit.Length{10, 20}},
+		},
+	})
+
+	act, err := client.Activity.Activity(context.Background(), id, "latlng", "time", "heartrate")
+	a.NoError(err)
+	a.Equal("Morning Ride", act.Name)
+	a.NotNil(act.Streams.LatLng)
+	a.NotNil(act.Streams.Time)
+	a.Nil(act.Streams.Elevation)
+	a.Nil(act.Streams.HeartRate)
+
+	exp, err := client.Activity.Export(context.Background(), id)
+	a.NoError(err)
+	a.Equal(activity.FormatGPX, exp.Format)
+
+	photos, err := client.Activity.Photos(context.Background(), id, 0)
+	a.NoError(err)
+	a.Empty(photos)
+
+	name, commute := "Commute", true
+	act, err = client.Activity.Update(context.Background(),
+		&strava.UpdatableActivity{ID: id, Name: &name, Commute: &commute})
+	a.NoError(err)
+	a.Equal("Commute", act.Name)
+	a.True(act.Commute)
+	a.Equal("Ride", act.Type)
+	a.Equal("Commute", svr.Activity(id).Name)
+
+	act, err = client.Activity.Activity(context.Background(), id+1)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(act)
+	act, err = client.Activity.Update(context.Background(), &strava.UpdatableActivity{ID: id + 1, Name: &name})
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(act)
+	a.Nil(svr.Activity(id + 1))
+}
@@ -0,0 +1,57 @@
+package stravatest
+
+import (
+	"cmp"
+	"net/http"
+	"slices"
+	"strconv"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/strava"
+)
+
+// AddRoute adds a route of the authenticated athlete returning its id
+//
+// An id is assigned if the route does not have one.
+func (s *Server) AddRoute(rte *strava.Route) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := *rte
+	if x.ID == 0 {
+		x.ID = s.ids.Next()
+	}
+	x.IDString = strconv.FormatInt(x.ID, 10)
+	if x.Athlete == nil {
+		x.Athlete = &strava.Athlete{ID: s.athlete.ID, ResourceState: 1}
+	}
+	s.routes[x.ID] = &x
+	return x.ID
+}
+
+// listRoutes returns the athlete's routes ordered by id
+func (s *Server) listRoutes(w http.ResponseWriter, r *http.Request) {
+	if !s.self(r) {
+		notFound(w, "Athlete")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rts := make([]*strava.Route, 0, len(s.routes))
+	for _, rte := range s.routes {
+		rts = append(rts, rte)
+	}
+	slices.SortFunc(rts, func(a, b *strava.Route) int { return cmp.Compare(a.ID, b.ID) })
+	fakeserver.Encode(w, http.StatusOK, paginate(rts, r))
+}
+
+func (s *Server) getRoute(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathID(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rte, ok := s.routes[id]
+	if !ok {
+		notFound(w, "Route")
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, rte)
+}
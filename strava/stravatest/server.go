@@ -0,0 +1,260 @@
+// Package stravatest provides a stateful fake of the Strava API for testing
+//
+// The Server implements the endpoints used by the strava package so clients created with
+// strava.WithBaseURL(server.URL) can be tested without access to Strava.
+package stravatest
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/strava"
+)
+
+const (
+	// AccessToken is the default access token accepted by the server
+	AccessToken = "stravatest-access-token"
+	// ClientID is the default client id of the application
+	ClientID = "stravatest-client-id"
+	// ClientSecret is the default client secret of the application
+	ClientSecret = "stravatest-client-secret" //nolint:gosec // not a secret
+)
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAccessToken sets the access token required by the API endpoints
+func WithAccessToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithClientCredentials sets the client credentials required by the webhook endpoints
+func WithClientCredentials(clientID, clientSecret string) Option {
+	return func(s *Server) {
+		s.clientID = clientID
+		s.clientSecret = clientSecret
+	}
+}
+
+// WithAthlete sets the authenticated athlete
+func WithAthlete(athlete *strava.Athlete) Option {
+	return func(s *Server) {
+		s.athlete = athlete
+	}
+}
+
+// WithProcessing sets the number of status requests before an upload becomes an activity
+func WithProcessing(polls int) Option {
+	return func(s *Server) {
+		s.polls = polls
+	}
+}
+
+// WithHTTPClient sets the client used to call webhook subscribers
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Server) {
+		s.client = client
+	}
+}
+
+// Server is a fake of the Strava API safe for concurrent use
+type Server struct {
+	*fakeserver.Server
+
+	client       *http.Client
+	token        string
+	clientID     string
+	clientSecret string
+	polls        int
+
+	mu            sync.Mutex
+	ids           fakeserver.IDs
+	athlete       *strava.Athlete
+	activities    map[int64]*strava.Activity
+	streams       map[int64]*strava.Streams
+	routes        map[int64]*strava.Route
+	uploads       *fakeserver.Uploads[details]
+	subscriptions map[int64]*strava.WebhookSubscription
+}
+
+// NewServer starts and returns a new Server, the caller should call Close when finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		client:        &http.Client{Timeout: 10 * time.Second},
+		token:         AccessToken,
+		clientID:      ClientID,
+		clientSecret:  ClientSecret,
+		polls:         1,
+		athlete:       &strava.Athlete{ID: 1, Username: "stravatest", Firstname: "Strava", Lastname: "Test"},
+		activities:    make(map[int64]*strava.Activity),
+		streams:       make(map[int64]*strava.Streams),
+		routes:        make(map[int64]*strava.Route),
+		subscriptions: make(map[int64]*strava.WebhookSubscription),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.athlete.ResourceState = 3
+	s.uploads = fakeserver.NewUploads[details](s.polls)
+	s.Server = fakeserver.Start(s.handler())
+	return s
+}
+
+// NewClient returns a strava.Client for the server authenticated with the server's credentials
+func (s *Server) NewClient(opts ...strava.Option) (*strava.Client, error) {
+	opts = append([]strava.Option{
+		strava.WithBaseURL(s.URL),
+		strava.WithTokenCredentials(s.token, "", time.Time{}),
+		strava.WithClientCredentials(s.clientID, s.clientSecret),
+	}, opts...)
+	return strava.NewClient(opts...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /athlete", s.authorize(s.getAthlete))
+	mux.HandleFunc("GET /athletes/{id}/stats", s.authorize(s.getStats))
+	mux.HandleFunc("GET /athletes/{id}/routes", s.authorize(s.listRoutes))
+	mux.HandleFunc("GET /athlete/activities", s.authorize(s.listActivities))
+	mux.HandleFunc("GET /activities/{id}", s.authorize(s.getActivity))
+	mux.HandleFunc("PUT /activities/{id}", s.authorize(s.updateActivity))
+	mux.HandleFunc("GET /activities/{id}/streams/{keys}", s.authorize(s.getStreams))
+	mux.HandleFunc("GET /activities/{id}/photos", s.authorize(s.getPhotos))
+	mux.HandleFunc("GET /routes/{id}", s.authorize(s.getRoute))
+	mux.HandleFunc("POST /uploads", s.authorize(s.createUpload))
+	mux.HandleFunc("GET /uploads/{id}", s.authorize(s.getUpload))
+	mux.HandleFunc("GET /push_subscriptions", s.application(s.listSubscriptions))
+	mux.HandleFunc("POST /push_subscriptions", s.application(s.createSubscription))
+	mux.HandleFunc("DELETE /push_subscriptions/{id}", s.application(s.deleteSubscription))
+	return mux
+}
+
+// authorize requires the bearer token of the athlete
+func (s *Server) authorize(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+s.token {
+			fault(w, http.StatusUnauthorized, "Authorization Error", "Athlete", "access_token", "invalid")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// application requires the client credentials of the application
+//
+// The credentials are sent in the query or the form encoded body, including for DELETE requests.
+func (s *Server) application(h func(http.ResponseWriter, *http.Request, url.Values)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		data, err := io.ReadAll(r.Body)
+		if err != nil {
+			fault(w, http.StatusBadRequest, "Bad Request", "Application", "body", "invalid")
+			return
+		}
+		form, err := url.ParseQuery(string(data))
+		if err != nil {
+			fault(w, http.StatusBadRequest, "Bad Request", "Application", "body", "invalid")
+			return
+		}
+		for key, values := range r.URL.Query() {
+			form[key] = append(form[key], values...)
+		}
+		if form.Get("client_id") != s.clientID || form.Get("client_secret") != s.clientSecret {
+			fault(w, http.StatusUnauthorized, "Authorization Error", "Application", "client_id", "invalid")
+			return
+		}
+		h(w, r, form)
+	}
+}
+
+func (s *Server) getAthlete(w http.ResponseWriter, _ *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fakeserver.Encode(w, http.StatusOK, s.athlete)
+}
+
+// getStats returns the all time totals of the athlete's activities
+func (s *Server) getStats(w http.ResponseWriter, r *http.Request) {
+	if !s.self(r) {
+		notFound(w, "Athlete")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	stats := &strava.Stats{
+		AllRideTotals: &strava.Totals{},
+		AllRunTotals:  &strava.Totals{},
+		AllSwimTotals: &strava.Totals{},
+	}
+	for _, act := range s.activities {
+		var totals *strava.Totals
+		switch act.Type {
+		case "Ride", "VirtualRide", "EBikeRide":
+			totals = stats.AllRideTotals
+			stats.BiggestRideDistance = max(stats.BiggestRideDistance, act.Distance)
+		case "Run", "VirtualRun", "TrailRun":
+			totals = stats.AllRunTotals
+		case "Swim":
+			totals = stats.AllSwimTotals
+		default:
+			continue
+		}
+		totals.Count++
+		totals.Distance += act.Distance
+		totals.MovingTime += act.MovingTime
+		totals.ElapsedTime += act.ElapsedTime
+		totals.ElevationGain += act.ElevationGain
+	}
+	fakeserver.Encode(w, http.StatusOK, stats)
+}
+
+// self returns true if the athlete id of the path is the authenticated athlete
+func (s *Server) self(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return r.PathValue("id") == strconv.Itoa(s.athlete.ID)
+}
+
+// pagination returns the offset and count of the requested page
+func pagination(r *http.Request) (int, int) {
+	q := r.URL.Query()
+	page, err := strconv.Atoi(q.Get("page"))
+	if err != nil || page < 1 {
+		page = 1
+	}
+	count, err := strconv.Atoi(q.Get("per_page"))
+	if err != nil || count < 1 {
+		count = 30
+	}
+	count = min(count, 200)
+	return (page - 1) * count, count
+}
+
+// paginate returns the page of items
+func paginate[T any](items []T, r *http.Request) []T {
+	offset, count := pagination(r)
+	return fakeserver.Page(items, offset, count)
+}
+
+// pathID returns the id in the path or false if the id is invalid
+func pathID(r *http.Request) (int64, bool) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	return id, err == nil
+}
+
+func fault(w http.ResponseWriter, status int, message, resource, field, code string) {
+	fakeserver.Encode(w, status, &strava.Fault{
+		Message: message,
+		Errors:  []*strava.Error{{Resource: resource, Field: field, Code: code}},
+	})
+}
+
+func notFound(w http.ResponseWriter, resource string) {
+	fault(w, http.StatusNotFound, "Record Not Found", resource, "id", "invalid")
+}
@@ -0,0 +1,97 @@
+package stravatest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+	"github.com/bzimmer/activity/strava/stravatest"
+)
+
+func newServer(t *testing.T, opts ...stravatest.Option) (*stravatest.Server, *strava.Client) {
+	t.Helper()
+	svr := stravatest.NewServer(opts...)
+	t.Cleanup(svr.Close)
+	client, err := svr.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svr, client
+}
+
+func TestAthlete(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, stravatest.WithAthlete(&strava.Athlete{ID: 77, Firstname: "Eddy"}))
+	ath, err := client.Athlete.Athlete(context.Background())
+	a.NoError(err)
+	a.Equal(77, ath.ID)
+	a.Equal("Eddy", ath.Firstname)
+
+	svr.AddActivity(&strava.Activity{Type: "Ride", Distance: 40000, MovingTime: 3600})
+	svr.AddActivity(&strava.Activity{Type: "Ride", Distance: 60000, MovingTime: 7200})
+	svr.AddActivity(&strava.Activity{Type: "Run", Distance: 10000})
+	stats, err := client.Athlete.Stats(context.Background(), 77)
+	a.NoError(err)
+	a.Equal(2, stats.AllRideTotals.Count)
+	a.InDelta(100000, stats.AllRideTotals.Distance.Meters(), 0.001)
+	a.InDelta(10800, stats.AllRideTotals.MovingTime.Seconds(), 0.001)
+	a.InDelta(60000, stats.BiggestRideDistance.Meters(), 0.001)
+	a.Equal(1, stats.AllRunTotals.Count)
+
+	stats, err = client.Athlete.Stats(context.Background(), 78)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(stats)
+}
+
+func TestAuthorization(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, _ := newServer(t, stravatest.WithAccessToken("secret"))
+	client, err := svr.NewClient(strava.WithTokenCredentials("other", "", time.Time{}))
+	a.NoError(err)
+	ath, err := client.Athlete.Athlete(context.Background())
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+	a.Nil(ath)
+
+	client, err = svr.NewClient(strava.WithClientCredentials(stravatest.ClientID, "other"))
+	a.NoError(err)
+	subs, err := client.Webhook.List(context.Background())
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+	a.Nil(subs)
+}
+
+func TestRoute(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	for range 5 {
+		svr.AddRoute(&strava.Route{Name: "route"})
+	}
+	id := svr.AddRoute(&strava.Route{ID: 99999, Name: "Loop"})
+
+	rte, err := client.Route.Route(context.Background(), id)
+	a.NoError(err)
+	a.Equal("Loop", rte.Name)
+	a.Equal("99999", rte.IDString)
+
+	rts, err := client.Route.Routes(context.Background(), 1, activity.Pagination{Total: 4, Count: 3})
+	a.NoError(err)
+	a.Len(rts, 4)
+	rts, err = client.Route.Routes(context.Background(), 1, activity.Pagination{})
+	a.NoError(err)
+	a.Len(rts, 6)
+	a.Equal(int64(99999), rts[5].ID)
+
+	rte, err = client.Route.Route(context.Background(), 1)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(rte)
+}
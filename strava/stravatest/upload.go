@@ -0,0 +1,137 @@
+package stravatest
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/strava"
+)
+
+// the status messages of an upload reported by Strava
+const (
+	statusProcessing = "Your activity is still being processed."
+	statusReady      = "Your activity is ready."
+	statusError      = "There was an error processing your activity."
+)
+
+// details of an upload used to create the activity
+type details struct {
+	externalID string
+	name       string
+	desc       string
+	trainer    bool
+	commute    bool
+}
+
+// createUpload accepts an activity file for processing
+//
+// An upload with the external id of an existing activity also fails as a duplicate.
+func (s *Server) createUpload(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseMultipartForm(32 << 20); err != nil {
+		fault(w, http.StatusBadRequest, "Bad Request", "Upload", "file", "invalid")
+		return
+	}
+	dataType := r.FormValue("data_type")
+	if !slices.Contains([]string{"fit", "fit.gz", "tcx", "tcx.gz", "gpx", "gpx.gz"}, dataType) {
+		fault(w, http.StatusBadRequest, "Bad Request", "Upload", "data_type", "invalid")
+		return
+	}
+	fp, hdr, err := r.FormFile("file")
+	if err != nil {
+		fault(w, http.StatusBadRequest, "Bad Request", "Upload", "file", "missing")
+		return
+	}
+	defer fp.Close()
+	u, err := fakeserver.NewUpload(hdr.Filename, fp, details{
+		externalID: r.FormValue("external_id"),
+		name:       r.FormValue("name"),
+		desc:       r.FormValue("description"),
+		trainer:    r.FormValue("trainer") == "1",
+		commute:    r.FormValue("commute") == "1",
+	})
+	if err != nil {
+		fault(w, http.StatusBadRequest, "Bad Request", "Upload", "file", "invalid")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.uploads.Add(s.ids.Next(), u)
+	fakeserver.Encode(w, http.StatusCreated, status(u))
+}
+
+// getUpload returns the status of the upload
+func (s *Server) getUpload(w http.ResponseWriter, r *http.Request) {
+	id, _ := pathID(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	u, ok := s.uploads.Status(id, s.exists, s.create)
+	if !ok {
+		notFound(w, "Upload")
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, status(u))
+}
+
+// exists returns true if the activity exists, the lock must be held
+func (s *Server) exists(activityID int64) bool {
+	_, ok := s.activities[activityID]
+	return ok
+}
+
+// create the activity of the upload, the lock must be held
+func (s *Server) create(u *fakeserver.Upload[details]) (int64, error) {
+	if u.Details.externalID != "" {
+		for id, act := range s.activities {
+			if act.ExternalID == u.Details.externalID {
+				return 0, &fakeserver.DuplicateError{ID: id}
+			}
+		}
+	}
+	act := &strava.Activity{
+		ID:            s.ids.Next(),
+		ResourceState: 3,
+		ExternalID:    cmp.Or(u.Details.externalID, u.Filename),
+		UploadID:      u.ID,
+		Athlete:       &strava.Athlete{ID: s.athlete.ID, ResourceState: 1},
+		Name:          cmp.Or(u.Details.name, "Uploaded Activity"),
+		Description:   u.Details.desc,
+		Trainer:       u.Details.trainer,
+		Commute:       u.Details.commute,
+		Type:          "Ride",
+		SportType:     "Ride",
+		StartDate:     time.Now().UTC().Truncate(time.Second),
+	}
+	act.StartDateLocal = act.StartDate
+	s.activities[act.ID] = act
+	return act.ID, nil
+}
+
+// status of the upload as reported by Strava
+func status(u *fakeserver.Upload[details]) *strava.Upload {
+	res := &strava.Upload{
+		ID:         u.ID,
+		IDString:   strconv.FormatInt(u.ID, 10),
+		ExternalID: cmp.Or(u.Details.externalID, u.Filename),
+		ActivityID: u.ResourceID,
+	}
+	var dup *fakeserver.DuplicateError
+	switch {
+	case !u.Done():
+		res.Status = statusProcessing
+	case errors.As(u.Err, &dup):
+		res.Status, res.Error = statusError, fmt.Sprintf("%s duplicate of activity %d", u.Filename, dup.ID)
+	case errors.Is(u.Err, fakeserver.ErrEmpty):
+		res.Status, res.Error = statusError, "The file is empty."
+	case u.Err != nil:
+		res.Status, res.Error = statusError, u.Err.Error()
+	default:
+		res.Status = statusReady
+	}
+	return res
+}
@@ -0,0 +1,35 @@
+package stravatest
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+)
+
+func TestUploadStatus(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	u := &fakeserver.Upload[details]{ID: 1, Filename: "ride.fit"}
+	a.Equal(statusProcessing, status(u).Status)
+
+	u.Err = fakeserver.ErrEmpty
+	a.Equal(statusError, status(u).Status)
+	a.Equal("The file is empty.", status(u).Error)
+
+	u.Err = &fakeserver.DuplicateError{ID: 2}
+	a.Equal("ride.fit duplicate of activity 2", status(u).Error)
+
+	u.Err = errors.New("unrecognized file type")
+	a.Equal(statusError, status(u).Status)
+	a.Equal("unrecognized file type", status(u).Error)
+
+	u.Err, u.ResourceID = nil, 2
+	res := status(u)
+	a.Equal(statusReady, res.Status)
+	a.Empty(res.Error)
+	a.Equal(int64(2), res.ActivityID)
+}
@@ -0,0 +1,63 @@
+package stravatest_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava/stravatest"
+)
+
+func file(contents string) *activity.File {
+	return &activity.File{Reader: strings.NewReader(contents), Name: "ride.gpx", Format: activity.FormatGPX}
+}
+
+func TestUpload(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t, stravatest.WithProcessing(1))
+	ctx := context.Background()
+
+	upload, err := client.Activity.Upload(ctx, file("<gpx/>"))
+	a.NoError(err)
+	a.Equal("ride.gpx", upload.ExternalID)
+	upload, err = client.Activity.Status(ctx, upload.ID)
+	a.NoError(err)
+	a.Equal("Your activity is still being processed.", upload.Status)
+	upload, err = client.Activity.Status(ctx, upload.ID)
+	a.NoError(err)
+	a.True(upload.Done())
+	a.NoError(upload.Err())
+	act := svr.Activity(upload.ActivityID)
+	a.NotNil(act)
+	a.Equal(upload.ID, act.UploadID)
+
+	// the processing errors are reported in the format parsed by the client
+	process := func(contents string) error {
+		upload, err = client.Activity.Upload(ctx, file(contents))
+		a.NoError(err)
+		for range 2 {
+			upload, err = client.Activity.Status(ctx, upload.ID)
+			a.NoError(err)
+		}
+		return upload.Err()
+	}
+	a.True(errors.Is(process(""), activity.ErrInvalidFile))
+	var dup *activity.DuplicateError
+	a.ErrorAs(process("<gpx/>"), &dup)
+	a.Equal(act.ID, dup.ActivityID)
+
+	upload, err = client.Activity.Status(ctx, upload.ID+100)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(upload)
+
+	upload, err = client.Activity.Upload(ctx, &activity.File{
+		Reader: strings.NewReader("data"), Name: "ride.txt", Format: activity.Format(99)})
+	a.True(errors.Is(err, activity.ErrInvalidFile))
+	a.Nil(upload)
+}
@@ -0,0 +1,147 @@
+package stravatest
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/strava"
+)
+
+// Subscriptions returns the push subscriptions
+func (s *Server) Subscriptions() []*strava.WebhookSubscription {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	subs := make([]*strava.WebhookSubscription, 0, len(s.subscriptions))
+	for _, sub := range s.subscriptions {
+		x := *sub
+		subs = append(subs, &x)
+	}
+	return subs
+}
+
+// Push sends the event to the callback of the push subscription
+//
+// The subscription id, owner, and event time are populated if not set on the message.
+func (s *Server) Push(ctx context.Context, msg *strava.WebhookMessage) error {
+	subs := s.Subscriptions()
+	if len(subs) == 0 {
+		return errors.New("no push subscription")
+	}
+	sub := subs[0]
+	x := *msg
+	if x.SubscriptionID == 0 {
+		x.SubscriptionID = sub.ID
+	}
+	if x.OwnerID == 0 {
+		s.mu.Lock()
+		x.OwnerID = s.athlete.ID
+		s.mu.Unlock()
+	}
+	if x.EventTime == 0 {
+		x.EventTime = int(time.Now().Unix())
+	}
+	data, err := json.Marshal(&x)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, sub.CallbackURL, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("push to %s failed: %s", sub.CallbackURL, res.Status)
+	}
+	return nil
+}
+
+func (s *Server) listSubscriptions(w http.ResponseWriter, _ *http.Request, _ url.Values) {
+	fakeserver.Encode(w, http.StatusOK, s.Subscriptions())
+}
+
+// createSubscription creates the push subscription after validating the callback
+//
+// Like Strava, only one subscription is allowed for an application and the callback must
+// echo the challenge of the validation request.
+func (s *Server) createSubscription(w http.ResponseWriter, r *http.Request, form url.Values) {
+	callback := form.Get("callback_url")
+	s.mu.Lock()
+	exists := len(s.subscriptions) > 0
+	s.mu.Unlock()
+	if exists {
+		fault(w, http.StatusBadRequest, "Bad Request", "PushSubscription", "", "already exists")
+		return
+	}
+	if err := s.validate(r.Context(), callback, form.Get("verify_token")); err != nil {
+		fault(w, http.StatusBadRequest, "Bad Request", "PushSubscription", "callback url", err.Error())
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now().UTC().Truncate(time.Second)
+	sub := &strava.WebhookSubscription{
+		ID:            s.ids.Next(),
+		ResourceState: 2,
+		CallbackURL:   callback,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+	}
+	s.subscriptions[sub.ID] = sub
+	fakeserver.Encode(w, http.StatusCreated, &strava.WebhookAcknowledgement{ID: sub.ID})
+}
+
+// validate sends the subscription validation request to the callback
+func (s *Server) validate(ctx context.Context, callback, verify string) error {
+	u, err := url.Parse(callback)
+	if err != nil || u.Host == "" {
+		return errors.New("invalid")
+	}
+	challenge := rand.Text()
+	q := u.Query()
+	q.Set("hub.mode", "subscribe")
+	q.Set("hub.challenge", challenge)
+	q.Set("hub.verify_token", verify)
+	u.RawQuery = q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return err
+	}
+	res, err := s.client.Do(req)
+	if err != nil {
+		return errors.New("GET to callback URL failed")
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK {
+		return errors.New("GET to callback URL does not return 200")
+	}
+	var ack map[string]string
+	if err = json.NewDecoder(res.Body).Decode(&ack); err != nil || ack["hub.challenge"] != challenge {
+		return errors.New("callback URL did not echo the challenge")
+	}
+	return nil
+}
+
+func (s *Server) deleteSubscription(w http.ResponseWriter, r *http.Request, _ url.Values) {
+	id, _ := pathID(r)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.subscriptions[id]; !ok {
+		notFound(w, "PushSubscription")
+		return
+	}
+	delete(s.subscriptions, id)
+	w.WriteHeader(http.StatusNoContent)
+}
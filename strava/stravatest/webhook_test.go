@@ -0,0 +1,74 @@
+package stravatest_test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+type subscriber struct {
+	verify   string
+	mu       sync.Mutex
+	messages []*strava.WebhookMessage
+}
+
+func (s *subscriber) SubscriptionRequest(_, verify string) error {
+	if verify != s.verify {
+		return errors.New("invalid verify token")
+	}
+	return nil
+}
+
+func (s *subscriber) MessageReceived(msg *strava.WebhookMessage) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func TestWebhook(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	ctx := context.Background()
+	sub := &subscriber{verify: "verify"}
+	callback := httptest.NewServer(strava.NewWebhookHandler(sub))
+	defer callback.Close()
+
+	a.Error(svr.Push(ctx, &strava.WebhookMessage{ObjectType: "activity"}))
+
+	ack, err := client.Webhook.Subscribe(ctx, callback.URL, "other")
+	a.Error(err)
+	a.Nil(ack)
+	ack, err = client.Webhook.Subscribe(ctx, callback.URL, "verify")
+	a.NoError(err)
+	a.NotZero(ack.ID)
+
+	// only one subscription is allowed
+	_, err = client.Webhook.Subscribe(ctx, callback.URL, "verify")
+	a.Error(err)
+
+	subs, err := client.Webhook.List(ctx)
+	a.NoError(err)
+	a.Len(subs, 1)
+	a.Equal(callback.URL, subs[0].CallbackURL)
+
+	a.NoError(svr.Push(ctx, &strava.WebhookMessage{ObjectType: "activity", ObjectID: 10, AspectType: "create"}))
+	a.Len(sub.messages, 1)
+	a.Equal(ack.ID, sub.messages[0].SubscriptionID)
+	a.Equal(1, sub.messages[0].OwnerID)
+	a.NotZero(sub.messages[0].EventTime)
+
+	a.NoError(client.Webhook.Unsubscribe(ctx, ack.ID))
+	a.Empty(svr.Subscriptions())
+	err = client.Webhook.Unsubscribe(ctx, ack.ID)
+	a.True(errors.Is(err, activity.ErrNotFound))
+}
@@ -0,0 +1,49 @@
+package strava
+
+import (
+	"strings"
+
+	"github.com/martinlindhe/unit"
+
+	"github.com/bzimmer/activity"
+)
+
+const provider = "strava"
+
+var _ activity.Summarizer = (*Activity)(nil)
+
+// Summarize returns a provider-neutral summary of the activity
+func (a *Activity) Summarize() *activity.Summary {
+	sport := a.SportType
+	if sport == "" {
+		sport = a.Type
+	}
+	return &activity.Summary{
+		ID:            a.ID,
+		Provider:      provider,
+		Name:          a.Name,
+		Sport:         activity.ToSport(sport),
+		Trainer:       a.Trainer || strings.HasPrefix(sport, "Virtual"),
+		StartTime:     a.StartDate,
+		ElapsedTime:   a.ElapsedTime,
+		MovingTime:    a.MovingTime,
+		Distance:      a.Distance,
+		ElevationGain: a.ElevationGain,
+		AveragePower:  unit.Power(a.AverageWatts) * unit.Watt,
+		MaxPower:      unit.Power(a.MaxWatts) * unit.Watt,
+	}
+}
+
+var _ activity.Locator = (*Activity)(nil)
+
+// Endpoints returns the start and end coordinates of the activity
+func (a *Activity) Endpoints() (*activity.LatLng, *activity.LatLng) {
+	return latlng(a.StartLatlng), latlng(a.EndLatlng)
+}
+
+func latlng(c Coordinates) *activity.LatLng {
+	if len(c) != 2 {
+		return nil
+	}
+	return &activity.LatLng{Lat: c[0], Lng: c[1]}
+}
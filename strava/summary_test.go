@@ -0,0 +1,58 @@
+package strava_test
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data, err := os.ReadFile("testdata/activity.json")
+	a.NoError(err)
+	var act strava.Activity
+	a.NoError(json.Unmarshal(data, &act))
+
+	s := act.Summarize()
+	a.NotNil(s)
+	a.Equal(int64(154504250376823), s.ID)
+	a.Equal("strava", s.Provider)
+	a.Equal("Happy Friday", s.Name)
+	a.Equal(activity.SportCycling, s.Sport)
+	a.True(s.Trainer)
+	a.Equal(act.StartDate, s.StartTime)
+	a.InDelta(24931.4, s.Distance.Meters(), 0.01)
+	a.InDelta(4500, s.MovingTime.Seconds(), 0.01)
+	a.InDelta(175.3, s.AveragePower.Watts(), 0.01)
+	a.InDelta(406, s.MaxPower.Watts(), 0.01)
+
+	act = strava.Activity{Type: "Run", SportType: "VirtualRun"}
+	s = act.Summarize()
+	a.Equal(activity.SportRunning, s.Sport)
+	a.True(s.Trainer)
+}
+
+func TestEndpoints(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	act := &strava.Activity{StartLatlng: strava.Coordinates{47.6, -122.3}, EndLatlng: strava.Coordinates{47.7, -122.4}}
+	start, end := act.Endpoints()
+	a.Equal(&activity.LatLng{Lat: 47.6, Lng: -122.3}, start)
+	a.Equal(&activity.LatLng{Lat: 47.7, Lng: -122.4}, end)
+
+	data, err := os.ReadFile("testdata/activity.json")
+	a.NoError(err)
+	act = &strava.Activity{}
+	a.NoError(json.Unmarshal(data, act))
+	start, end = act.Endpoints()
+	a.Nil(start)
+	a.Nil(end)
+}
@@ -0,0 +1,59 @@
+package strava
+
+import (
+	"context"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+)
+
+// WithToken sets the underlying oauth2.Token.
+func WithToken(token *oauth2.Token) Option {
+	return func(c *Client) error {
+		c.token = token
+		return nil
+	}
+}
+
+// WithTokenCredentials provides the tokens for an authenticated user.
+func WithTokenCredentials(accessToken, refreshToken string, expiry time.Time) Option {
+	return func(c *Client) error {
+		c.token.AccessToken = accessToken
+		c.token.RefreshToken = refreshToken
+		c.token.Expiry = expiry
+		return nil
+	}
+}
+
+// WithAutoRefresh refreshes access tokens automatically.
+// The order of this option matters because it is dependent on the client's
+// config and token. Use this option after With*Credentials and WithTokenStore.
+// Each refreshed token is passed to the hooks of WithRefreshHook and WithTokenStore.
+func WithAutoRefresh(ctx context.Context) Option {
+	return func(c *Client) error {
+		src := activity.RefreshTokenSource(ctx, c.token, c.config.TokenSource(ctx, c.token), c.hooks.Refreshed)
+		c.client = oauth2.NewClient(ctx, src)
+		return nil
+	}
+}
+
+// WithRefreshHook calls the hook with each token refreshed by WithAutoRefresh
+func WithRefreshHook(hook activity.RefreshHook) Option {
+	return func(c *Client) error {
+		return c.hooks.Add(hook)
+	}
+}
+
+// WithTokenStore uses the token in the store, if one exists, and saves each token refreshed by WithAutoRefresh
+func WithTokenStore(ctx context.Context, store activity.TokenStore) Option {
+	return func(c *Client) error {
+		token, err := activity.StoredToken(ctx, store, c.token)
+		if err != nil {
+			return err
+		}
+		c.token = token
+		return c.hooks.Add(store.SaveToken)
+	}
+}
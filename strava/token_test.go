@@ -0,0 +1,72 @@
+package strava_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/strava"
+)
+
+func TestWithTokenStore(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/oauth/token", func(w http.ResponseWriter, r *http.Request) {
+		a.NoError(r.ParseForm())
+		a.Equal("refresh_token", r.Form.Get("grant_type"))
+		a.Equal("stored", r.Form.Get("refresh_token"))
+		w.Header().Set("Content-Type", "application/json")
+		http.ServeFile(w, r, "testdata/refresh.json")
+	})
+	mux.HandleFunc("/athlete", func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer andthisbetheaccesstoken", r.Header.Get("Authorization"))
+		http.ServeFile(w, r, "testdata/athlete.json")
+	})
+	svr := httptest.NewServer(mux)
+	defer svr.Close()
+
+	ctx := context.Background()
+	store := activity.NewFileTokenStore(filepath.Join(t.TempDir(), "strava.json"))
+	a.NoError(store.SaveToken(ctx, &oauth2.Token{
+		AccessToken: "expired", RefreshToken: "stored", Expiry: time.Now().Add(-time.Hour)}))
+
+	var refreshed int
+	client, err := strava.NewClient(
+		strava.WithBaseURL(svr.URL),
+		strava.WithConfig(oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: svr.URL + "/oauth/token"}}),
+		strava.WithTokenStore(ctx, store),
+		strava.WithRefreshHook(func(_ context.Context, token *oauth2.Token) error {
+			refreshed++
+			a.Equal("andthisbetheaccesstoken", token.AccessToken)
+			return nil
+		}),
+		strava.WithAutoRefresh(ctx),
+	)
+	a.NoError(err)
+
+	for range 2 {
+		ath, aerr := client.Athlete.Athlete(ctx)
+		a.NoError(aerr)
+		a.NotNil(ath)
+	}
+	a.Equal(1, refreshed)
+
+	token, err := store.Token(ctx)
+	a.NoError(err)
+	a.Equal("andthisbetheaccesstoken", token.AccessToken)
+	a.Equal("andthisbetherefreshtoken", token.RefreshToken)
+
+	_, err = strava.NewClient(strava.WithTokenStore(ctx, nil))
+	a.Error(err)
+	_, err = strava.NewClient(strava.WithRefreshHook(nil))
+	a.Error(err)
+}
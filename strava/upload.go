@@ -15,6 +15,8 @@ import (
 //   status of your upload. Strava recommends polling no more than once a second. The mean processing
 //   time is around 8 seconds.
 
+var _ activity.Outcome = (*Upload)(nil)
+
 type uploader struct {
 	s *ActivityService
 }
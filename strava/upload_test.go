@@ -19,3 +19,21 @@ func TestUploadDone(t *testing.T) {
 	a.True((&strava.Upload{Error: "error"}).Done())
 	a.True((&strava.Upload{ActivityID: 1234567890}).Done())
 }
+
+func TestUploadErr(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.NoError((&strava.Upload{ActivityID: 1234567890}).Err())
+	a.EqualError((&strava.Upload{Error: "error"}).Err(), "error")
+
+	var dup *activity.DuplicateError
+	err := (&strava.Upload{Error: "ride.fit duplicate of <a href='/activities/8827132'>Morning Ride</a>"}).Err()
+	a.ErrorAs(err, &dup)
+	a.Equal(int64(8827132), dup.ActivityID)
+	a.Equal("ride.fit duplicate of <a href='/activities/8827132'>Morning Ride</a>", err.Error())
+
+	err = (&strava.Upload{Error: "ride.fit duplicate of activity 123"}).Err()
+	a.ErrorAs(err, &dup)
+	a.Equal(int64(123), dup.ActivityID)
+}
@@ -0,0 +1,101 @@
+package activity
+
+//go:generate stringer -type=Sport -linecomment -output=summary_string.go
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+// Sport is the provider-neutral classification of an activity
+type Sport int
+
+const (
+	// Other is any sport not otherwise classified
+	SportOther Sport = iota // other
+	// Cycling including virtual, mountain, gravel, and e-bike rides
+	SportCycling // cycling
+	// Running including virtual and trail runs
+	SportRunning // running
+	// Walking
+	SportWalking // walking
+	// Hiking
+	SportHiking // hiking
+	// Swimming
+	SportSwimming // swimming
+)
+
+// MarshalJSON converts a Sport enum to a string representation
+func (s Sport) MarshalJSON() ([]byte, error) {
+	return fmt.Appendf(nil, `"%s"`, s.String()), nil
+}
+
+// UnmarshalJSON converts a string representation to a Sport enum
+func (s *Sport) UnmarshalJSON(b []byte) error {
+	var sport string
+	if err := json.Unmarshal(b, &sport); err != nil {
+		return err
+	}
+	*s = ToSport(sport)
+	return nil
+}
+
+// ToSport converts a provider's sport or activity type to a Sport
+// If no mapping exists the Sport Other is returned
+func ToSport(sport string) Sport {
+	sport = strings.ToLower(sport)
+	switch sport {
+	case "cycling", "biking", "ride", "virtualride", "ebikeride", "mountainbikeride",
+		"emountainbikeride", "gravelride", "handcycle", "velomobile":
+		return SportCycling
+	case "running", "run", "virtualrun", "trailrun":
+		return SportRunning
+	case "walking", "walk":
+		return SportWalking
+	case "hiking", "hike":
+		return SportHiking
+	case "swimming", "swim":
+		return SportSwimming
+	default:
+		return SportOther
+	}
+}
+
+// Summary is a provider-neutral summary of an activity
+type Summary struct {
+	// ID is the provider's identifier for the activity
+	ID int64 `json:"id"`
+	// Provider is the name of the service the activity originated from
+	Provider      string        `json:"provider"`
+	Name          string        `json:"name"`
+	Sport         Sport         `json:"sport"`
+	Trainer       bool          `json:"trainer"`
+	StartTime     time.Time     `json:"start_time"`
+	ElapsedTime   unit.Duration `json:"elapsed_time" units:"s"`
+	MovingTime    unit.Duration `json:"moving_time" units:"s"`
+	Distance      unit.Length   `json:"distance" units:"m"`
+	ElevationGain unit.Length   `json:"elevation_gain" units:"m"`
+	AveragePower  unit.Power    `json:"average_power" units:"W"`
+	MaxPower      unit.Power    `json:"max_power" units:"W"`
+}
+
+// AverageSpeed is the distance over the moving time, or elapsed time if no moving time is available
+func (s *Summary) AverageSpeed() unit.Speed {
+	d := s.MovingTime
+	if d <= 0 {
+		d = s.ElapsedTime
+	}
+	if d <= 0 {
+		return 0
+	}
+	return unit.Speed(s.Distance.Meters()/d.Seconds()) * unit.MetersPerSecond
+}
+
+// EndTime is the start time plus the elapsed time
+func (s *Summary) EndTime() time.Time {
+	return s.StartTime.Add(time.Duration(s.ElapsedTime.Seconds() * float64(time.Second)))
+}
@@ -0,0 +1,84 @@
+package activity_test
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestSport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+	a.Equal("cycling", activity.SportCycling.String())
+
+	a.Equal(activity.SportCycling, activity.ToSport("VirtualRide"))
+	a.Equal(activity.SportCycling, activity.ToSport("CYCLING"))
+	a.Equal(activity.SportRunning, activity.ToSport("Run"))
+	a.Equal(activity.SportWalking, activity.ToSport("walk"))
+	a.Equal(activity.SportHiking, activity.ToSport("Hike"))
+	a.Equal(activity.SportSwimming, activity.ToSport("Swim"))
+	a.Equal(activity.SportOther, activity.ToSport("Yoga"))
+	a.Equal(activity.SportOther, activity.ToSport(""))
+
+	v, err := json.Marshal(activity.SportRunning)
+	a.NoError(err)
+	a.JSONEq(`"running"`, string(v))
+
+	var sport activity.Sport
+	a.NoError(json.Unmarshal(v, &sport))
+	a.Equal(activity.SportRunning, sport)
+	a.Error(json.Unmarshal([]byte("2"), &sport))
+}
+
+func TestSummary(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		summary *activity.Summary
+		speed   float64
+		end     time.Time
+	}{
+		{
+			name:    "empty",
+			summary: &activity.Summary{},
+		},
+		{
+			name: "moving time",
+			summary: &activity.Summary{
+				StartTime:   start,
+				Distance:    36 * unit.Kilometer,
+				ElapsedTime: 2 * unit.Hour,
+				MovingTime:  1 * unit.Hour,
+			},
+			speed: 10,
+			end:   start.Add(2 * time.Hour),
+		},
+		{
+			name: "elapsed time",
+			summary: &activity.Summary{
+				StartTime:   start,
+				Distance:    36 * unit.Kilometer,
+				ElapsedTime: 2 * unit.Hour,
+			},
+			speed: 5,
+			end:   start.Add(2 * time.Hour),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			a.InDelta(tt.speed, tt.summary.AverageSpeed().MetersPerSecond(), 0.001)
+			if !tt.end.IsZero() {
+				a.Equal(tt.end, tt.summary.EndTime())
+			}
+		})
+	}
+}
@@ -0,0 +1,220 @@
+package activity
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+// SyncTarget is a provider to which activities are uploaded
+type SyncTarget struct {
+	// Name of the target provider
+	Name     string
+	Uploader Uploader
+}
+
+// SyncResult is the result of syncing a source activity to a target
+type SyncResult struct {
+	// Summary of the source activity
+	Summary *Summary `json:"summary"`
+	// Target is the name of the target provider
+	Target string `json:"target"`
+	// Mapping is the mapping of the source activity to the target activity
+	Mapping *Mapping `json:"mapping,omitempty"`
+	// Skipped is true if the activity was previously synced to the target
+	Skipped bool `json:"skipped"`
+	// Err is non-nil if exporting, uploading, or processing the activity failed
+	Err error `json:"-"`
+}
+
+// A SyncOption allows configuring a Syncer
+type SyncOption func(s *Syncer)
+
+// WithSyncTarget adds a target to which activities are uploaded
+func WithSyncTarget(name string, uploader Uploader) SyncOption {
+	return func(s *Syncer) {
+		s.targets = append(s.targets, &SyncTarget{Name: name, Uploader: uploader})
+	}
+}
+
+// WithSyncPoller controls the creation of the Poller used to check the status of uploads
+func WithSyncPoller(poller func(Uploader) Poller) SyncOption {
+	return func(s *Syncer) {
+		if poller != nil {
+			s.poller = poller
+		}
+	}
+}
+
+// WithSyncFilter includes only the activities for which the filter returns true
+func WithSyncFilter(filter func(*Summary) bool) SyncOption {
+	return func(s *Syncer) {
+		if filter != nil {
+			s.filter = filter
+		}
+	}
+}
+
+// Syncer copies activities from a source provider to one or more target providers
+//
+// The mapping of each source activity to the target activity is recorded after a successful
+// upload and activities with an existing mapping are skipped so syncing is idempotent.
+type Syncer struct {
+	source   string
+	exporter Exporter
+	lister   Lister
+	mappings Mappings
+	targets  []*SyncTarget
+	poller   func(Uploader) Poller
+	filter   func(*Summary) bool
+}
+
+// NewSyncer returns a Syncer for the named source provider
+func NewSyncer(source string, exporter Exporter, lister Lister, mappings Mappings, opts ...SyncOption) *Syncer {
+	s := &Syncer{
+		source:   source,
+		exporter: exporter,
+		lister:   lister,
+		mappings: mappings,
+		poller:   func(u Uploader) Poller { return NewPoller(u) },
+		filter:   func(*Summary) bool { return true },
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Sync uploads the source activities listed using the pagination specification to all targets
+// missing the activity
+//
+// An error is returned only if listing fails or the context is canceled, failures syncing an
+// individual activity are reported in the results.
+func (s *Syncer) Sync(ctx context.Context, spec Pagination) ([]*SyncResult, error) {
+	if len(s.targets) == 0 {
+		return nil, errors.New("no sync targets")
+	}
+	summaries, err := s.lister.List(ctx, spec)
+	if err != nil {
+		return nil, err
+	}
+	var results []*SyncResult
+	for _, summary := range summaries {
+		if !s.filter(summary) {
+			continue
+		}
+		var res []*SyncResult
+		res, err = s.SyncActivity(ctx, summary)
+		results = append(results, res...)
+		if err != nil {
+			return results, err
+		}
+	}
+	return results, nil
+}
+
+// SyncActivity uploads the source activity to all targets missing the activity
+//
+// The activity is exported once and only if at least one target is missing the activity.
+func (s *Syncer) SyncActivity(ctx context.Context, summary *Summary) ([]*SyncResult, error) {
+	var missing []*SyncTarget
+	results := make([]*SyncResult, 0, len(s.targets))
+	for _, target := range s.targets {
+		mapping, err := s.mappings.Mapping(ctx, s.source, summary.ID, target.Name)
+		if err != nil {
+			return nil, err
+		}
+		if mapping != nil {
+			results = append(results, &SyncResult{Summary: summary, Target: target.Name, Mapping: mapping, Skipped: true})
+			continue
+		}
+		missing = append(missing, target)
+	}
+	if len(missing) == 0 {
+		return results, nil
+	}
+	export, data, xerr := s.export(ctx, summary.ID)
+	for _, target := range missing {
+		if err := ctx.Err(); err != nil {
+			return results, err
+		}
+		res := &SyncResult{Summary: summary, Target: target.Name, Err: xerr}
+		if xerr == nil {
+			file := &File{
+				Reader:   bytes.NewReader(data),
+				Filename: export.Filename,
+				Name:     export.Name,
+				Format:   export.Format,
+			}
+			res.Mapping, res.Err = s.upload(ctx, summary, target, file)
+		}
+		results = append(results, res)
+	}
+	return results, nil
+}
+
+// export the activity buffering the contents so it can be uploaded to multiple targets
+func (s *Syncer) export(ctx context.Context, activityID int64) (*Export, []byte, error) {
+	export, err := s.exporter.Export(ctx, activityID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export %d: %w", activityID, err)
+	}
+	defer export.Close()
+	data, err := io.ReadAll(export)
+	if err != nil {
+		return nil, nil, fmt.Errorf("export %d: %w", activityID, err)
+	}
+	return export, data, nil
+}
+
+// upload the file to the target and poll until processing completes
+func (s *Syncer) upload(ctx context.Context, summary *Summary, target *SyncTarget, file *File) (*Mapping, error) {
+	upload, err := target.Uploader.Upload(ctx, file)
+	if err != nil {
+		return nil, fmt.Errorf("upload %d to %s: %w", summary.ID, target.Name, err)
+	}
+	if !upload.Done() {
+		upload, err = s.poll(ctx, target, upload.Identifier())
+		if err != nil {
+			return nil, fmt.Errorf("upload %d to %s: %w", summary.ID, target.Name, err)
+		}
+	}
+	// a duplicate is mapped to the existing activity on the target
+	targetID, _, err := outcome(upload)
+	if err != nil {
+		return nil, fmt.Errorf("upload %d to %s: %w", summary.ID, target.Name, err)
+	}
+	mapping := &Mapping{
+		Source:   s.source,
+		SourceID: summary.ID,
+		Target:   target.Name,
+		TargetID: targetID,
+		UploadID: upload.Identifier(),
+		Created:  time.Now().UTC(),
+	}
+	if err = s.mappings.Add(ctx, mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+// poll the status of the upload returning the last status
+func (s *Syncer) poll(ctx context.Context, target *SyncTarget, id UploadID) (Upload, error) {
+	var upload Upload
+	for poll := range s.poller(target.Uploader).Poll(ctx, id) {
+		if poll.Err != nil {
+			return nil, poll.Err
+		}
+		upload = poll.Upload
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if upload == nil || !upload.Done() {
+		return nil, fmt.Errorf("upload %d incomplete", id)
+	}
+	return upload, nil
+}
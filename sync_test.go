@@ -0,0 +1,230 @@
+package activity_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+type syncLister struct {
+	err       error
+	summaries []*activity.Summary
+}
+
+func (l *syncLister) List(_ context.Context, _ activity.Pagination) ([]*activity.Summary, error) {
+	return l.summaries, l.err
+}
+
+type syncExporter struct {
+	mu      sync.Mutex
+	err     error
+	exports int
+}
+
+func (e *syncExporter) Export(_ context.Context, activityID int64) (*activity.Export, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.exports++
+	if e.err != nil {
+		return nil, e.err
+	}
+	return &activity.Export{
+		File: &activity.File{
+			Reader: io.NopCloser(bytes.NewBufferString("activity")),
+			Name:   "activity.fit",
+			Format: activity.FormatFIT,
+		},
+		ID: activityID,
+	}, nil
+}
+
+type syncUpload struct {
+	id       activity.UploadID
+	done     bool
+	err      error
+	activity int64
+}
+
+func (u *syncUpload) Identifier() activity.UploadID { return u.id }
+func (u *syncUpload) Done() bool                    { return u.done }
+func (u *syncUpload) ActivityIdentifier() int64     { return u.activity }
+func (u *syncUpload) Err() error                    { return u.err }
+
+type syncUploader struct {
+	mu      sync.Mutex
+	err     error
+	failed  error
+	uploads []string
+}
+
+func (u *syncUploader) Upload(_ context.Context, file *activity.File) (activity.Upload, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if u.err != nil {
+		return nil, u.err
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return nil, err
+	}
+	u.uploads = append(u.uploads, string(data))
+	return &syncUpload{id: activity.UploadID(len(u.uploads))}, nil
+}
+
+func (u *syncUploader) Status(_ context.Context, id activity.UploadID) (activity.Upload, error) {
+	return &syncUpload{id: id, done: true, err: u.failed, activity: int64(id) * 100}, nil
+}
+
+func newSyncPoller(u activity.Uploader) activity.Poller {
+	return activity.NewPoller(u, activity.WithInterval(time.Millisecond))
+}
+
+func TestSync(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	lister := &syncLister{summaries: []*activity.Summary{{ID: 1}, {ID: 2}, {ID: 3}}}
+	exporter := &syncExporter{}
+	strava, rwgps := &syncUploader{}, &syncUploader{}
+	mappings := activity.NewMemoryMappings()
+	a.NoError(mappings.Add(ctx, &activity.Mapping{Source: "zwift", SourceID: 2, Target: "strava", TargetID: 22}))
+
+	syncer := activity.NewSyncer("zwift", exporter, lister, mappings,
+		activity.WithSyncTarget("strava", strava),
+		activity.WithSyncTarget("rwgps", rwgps),
+		activity.WithSyncPoller(newSyncPoller),
+		activity.WithSyncFilter(func(s *activity.Summary) bool { return s.ID != 3 }))
+
+	results, err := syncer.Sync(ctx, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 4)
+	// the activity is exported once for all targets
+	a.Equal(2, exporter.exports)
+	a.Equal([]string{"activity"}, strava.uploads)
+	a.Equal([]string{"activity", "activity"}, rwgps.uploads)
+
+	skipped := 0
+	for _, res := range results {
+		a.NoError(res.Err)
+		a.NotNil(res.Mapping)
+		if res.Skipped {
+			skipped++
+			a.Equal(int64(22), res.Mapping.TargetID)
+			continue
+		}
+		a.Equal("zwift", res.Mapping.Source)
+		a.Equal(res.Target, res.Mapping.Target)
+		a.Equal(int64(res.Mapping.UploadID)*100, res.Mapping.TargetID)
+	}
+	a.Equal(1, skipped)
+
+	// syncing again is idempotent
+	results, err = syncer.Sync(ctx, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 4)
+	a.Equal(2, exporter.exports)
+	for _, res := range results {
+		a.True(res.Skipped)
+	}
+}
+
+func TestSyncDuplicate(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	lister := &syncLister{summaries: []*activity.Summary{{ID: 1}}}
+	uploader := &syncUploader{failed: &activity.DuplicateError{ActivityID: 77, Message: "duplicate of 77"}}
+	mappings := activity.NewMemoryMappings()
+	syncer := activity.NewSyncer("zwift", &syncExporter{}, lister, mappings,
+		activity.WithSyncTarget("strava", uploader), activity.WithSyncPoller(newSyncPoller))
+
+	results, err := syncer.Sync(ctx, activity.Pagination{})
+	a.NoError(err)
+	a.Len(results, 1)
+	a.NoError(results[0].Err)
+	// the duplicate is mapped to the existing activity
+	a.Equal(int64(77), results[0].Mapping.TargetID)
+	mapping, err := mappings.Mapping(ctx, "zwift", 1, "strava")
+	a.NoError(err)
+	a.Equal(int64(77), mapping.TargetID)
+}
+
+func TestSyncErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.Background()
+	summaries := []*activity.Summary{{ID: 1}}
+
+	syncer := activity.NewSyncer("zwift", &syncExporter{}, &syncLister{}, activity.NewMemoryMappings())
+	results, err := syncer.Sync(ctx, activity.Pagination{})
+	a.Error(err)
+	a.Nil(results)
+
+	syncer = activity.NewSyncer("zwift", &syncExporter{}, &syncLister{err: errors.New("list")},
+		activity.NewMemoryMappings(), activity.WithSyncTarget("strava", &syncUploader{}))
+	results, err = syncer.Sync(ctx, activity.Pagination{})
+	a.EqualError(err, "list")
+	a.Nil(results)
+
+	tests := []struct {
+		name     string
+		exporter *syncExporter
+		uploader *syncUploader
+		err      string
+	}{
+		{
+			name:     "export",
+			exporter: &syncExporter{err: errors.New("not found")},
+			uploader: &syncUploader{},
+			err:      "export 1: not found",
+		},
+		{
+			name:     "upload",
+			exporter: &syncExporter{},
+			uploader: &syncUploader{err: errors.New("unauthorized")},
+			err:      "upload 1 to strava: unauthorized",
+		},
+		{
+			name:     "processing",
+			exporter: &syncExporter{},
+			uploader: &syncUploader{failed: errors.New("duplicate")},
+			err:      "upload 1 to strava: duplicate",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			mappings := activity.NewMemoryMappings()
+			s := activity.NewSyncer("zwift", tt.exporter, &syncLister{summaries: summaries}, mappings,
+				activity.WithSyncTarget("strava", tt.uploader), activity.WithSyncPoller(newSyncPoller))
+			res, serr := s.Sync(ctx, activity.Pagination{})
+			a.NoError(serr)
+			a.Len(res, 1)
+			a.EqualError(res[0].Err, tt.err)
+			a.Nil(res[0].Mapping)
+			// failures are not recorded so the activity is synced again on the next run
+			mapping, merr := mappings.Mapping(ctx, "zwift", 1, "strava")
+			a.NoError(merr)
+			a.Nil(mapping)
+		})
+	}
+
+	cctx, cancel := context.WithCancel(ctx)
+	cancel()
+	syncer = activity.NewSyncer("zwift", &syncExporter{}, &syncLister{summaries: summaries},
+		activity.NewMemoryMappings(), activity.WithSyncTarget("strava", &syncUploader{}))
+	results, err = syncer.Sync(cctx, activity.Pagination{})
+	a.ErrorIs(err, context.Canceled)
+	a.Empty(results)
+}
@@ -0,0 +1,534 @@
+package activity
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"math"
+	"time"
+
+	"github.com/martinlindhe/unit"
+)
+
+const (
+	tcxSportBiking  = "Biking"
+	tcxSportRunning = "Running"
+	tcxSportOther   = "Other"
+	// tcxCourseNameLength is the maximum length of a course name
+	tcxCourseNameLength = 15
+	// tcxCoursePointNameLength is the maximum length of a course point name
+	tcxCoursePointNameLength = 10
+)
+
+var errNoTrackpoints = errors.New("no trackpoints available")
+
+// TCXPosition is a coordinate in degrees
+type TCXPosition struct {
+	Latitude  float64 `xml:"LatitudeDegrees"`
+	Longitude float64 `xml:"LongitudeDegrees"`
+}
+
+// TCXValue is a single value element, used for heart rates in beats per minute
+type TCXValue struct {
+	Value uint8 `xml:"Value"`
+}
+
+// TCXTrackpointExtension is the ActivityExtension v2 trackpoint extension
+type TCXTrackpointExtension struct {
+	Speed *float64 `xml:"Speed,omitempty"`
+	Watts *uint16  `xml:"Watts,omitempty"`
+}
+
+// TCXLapExtension is the ActivityExtension v2 lap extension
+type TCXLapExtension struct {
+	AvgSpeed *float64 `xml:"AvgSpeed,omitempty"`
+	AvgWatts *uint16  `xml:"AvgWatts,omitempty"`
+	MaxWatts *uint16  `xml:"MaxWatts,omitempty"`
+}
+
+// TCXExtensions are the supported extensions for trackpoints and laps
+type TCXExtensions struct {
+	TPX *TCXTrackpointExtension `xml:"http://www.garmin.com/xmlschemas/ActivityExtension/v2 TPX,omitempty"`
+	LX  *TCXLapExtension        `xml:"http://www.garmin.com/xmlschemas/ActivityExtension/v2 LX,omitempty"`
+}
+
+// TCXTrackpoint is a single sample of activity data
+type TCXTrackpoint struct {
+	Time       time.Time      `xml:"Time"`
+	Position   *TCXPosition   `xml:"Position,omitempty"`
+	Altitude   *float64       `xml:"AltitudeMeters,omitempty"`
+	Distance   *float64       `xml:"DistanceMeters,omitempty"`
+	HeartRate  *TCXValue      `xml:"HeartRateBpm,omitempty"`
+	Cadence    *uint8         `xml:"Cadence,omitempty"`
+	Extensions *TCXExtensions `xml:"Extensions,omitempty"`
+}
+
+// TCXLap summarizes a lap of an activity
+type TCXLap struct {
+	StartTime        time.Time        `xml:"StartTime,attr"`
+	TotalTime        float64          `xml:"TotalTimeSeconds"`
+	Distance         float64          `xml:"DistanceMeters"`
+	MaximumSpeed     *float64         `xml:"MaximumSpeed,omitempty"`
+	Calories         float64          `xml:"Calories"`
+	AverageHeartRate *TCXValue        `xml:"AverageHeartRateBpm,omitempty"`
+	MaximumHeartRate *TCXValue        `xml:"MaximumHeartRateBpm,omitempty"`
+	Intensity        string           `xml:"Intensity"`
+	Cadence          *uint8           `xml:"Cadence,omitempty"`
+	TriggerMethod    string           `xml:"TriggerMethod"`
+	Track            []*TCXTrackpoint `xml:"Track>Trackpoint"`
+	Extensions       *TCXExtensions   `xml:"Extensions,omitempty"`
+}
+
+// TCXActivity is a completed activity
+type TCXActivity struct {
+	Sport string    `xml:"Sport,attr"`
+	ID    time.Time `xml:"Id"`
+	Laps  []*TCXLap `xml:"Lap"`
+}
+
+// TCXCourseLap summarizes a course
+type TCXCourseLap struct {
+	TotalTime     float64      `xml:"TotalTimeSeconds"`
+	Distance      float64      `xml:"DistanceMeters"`
+	BeginPosition *TCXPosition `xml:"BeginPosition,omitempty"`
+	EndPosition   *TCXPosition `xml:"EndPosition,omitempty"`
+	Intensity     string       `xml:"Intensity"`
+}
+
+// TCXCoursePoint is a point of interest along a course
+type TCXCoursePoint struct {
+	Name      string      `xml:"Name"`
+	Time      time.Time   `xml:"Time"`
+	Position  TCXPosition `xml:"Position"`
+	Altitude  *float64    `xml:"AltitudeMeters,omitempty"`
+	PointType string      `xml:"PointType"`
+}
+
+// TCXCourse is a planned route
+type TCXCourse struct {
+	Name         string            `xml:"Name"`
+	Laps         []*TCXCourseLap   `xml:"Lap"`
+	Track        []*TCXTrackpoint  `xml:"Track>Trackpoint"`
+	CoursePoints []*TCXCoursePoint `xml:"CoursePoint"`
+}
+
+// TCX is the content of a Training Center XML file
+type TCX struct {
+	XMLName    xml.Name       `xml:"http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2 TrainingCenterDatabase"`
+	Activities []*TCXActivity `xml:"Activities>Activity,omitempty"`
+	Courses    []*TCXCourse   `xml:"Courses>Course,omitempty"`
+}
+
+var _ Summarizer = (*TCX)(nil)
+var _ SeriesEncoder = (*TCX)(nil)
+var _ CourseEncoder = (*TCX)(nil)
+
+// DecodeTCX decodes a TCX file
+func DecodeTCX(r io.Reader) (*TCX, error) {
+	t := &TCX{}
+	if err := xml.NewDecoder(r).Decode(t); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// TCX decodes the file as a TCX file
+//
+// The content of the file is buffered so it can be read again after decoding.
+func (f *File) TCX() (*TCX, error) {
+	b, err := f.buffer()
+	if err != nil {
+		return nil, err
+	}
+	return DecodeTCX(bytes.NewReader(b))
+}
+
+// Write the TCX to the writer
+func (t *TCX) Write(w io.Writer) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(t); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// trackpoints returns all the trackpoints of the first activity
+func (t *TCX) trackpoints() []*TCXTrackpoint {
+	if len(t.Activities) == 0 {
+		return nil
+	}
+	var tps []*TCXTrackpoint
+	for _, lap := range t.Activities[0].Laps {
+		tps = append(tps, lap.Track...)
+	}
+	return tps
+}
+
+// Summarize returns a provider-neutral summary of the first activity
+func (t *TCX) Summarize() *Summary {
+	s := &Summary{}
+	if len(t.Activities) == 0 {
+		return s
+	}
+	act := t.Activities[0]
+	s.Sport = ToSport(act.Sport)
+	s.StartTime = act.ID
+	var work float64
+	for _, lap := range act.Laps {
+		s.MovingTime += unit.Duration(lap.TotalTime) * unit.Second
+		s.Distance += unit.Length(lap.Distance) * unit.Meter
+		if lap.Extensions == nil || lap.Extensions.LX == nil {
+			continue
+		}
+		if x := lap.Extensions.LX.AvgWatts; x != nil {
+			work += float64(*x) * lap.TotalTime
+		}
+		if x := lap.Extensions.LX.MaxWatts; x != nil {
+			s.MaxPower = max(s.MaxPower, unit.Power(*x)*unit.Watt)
+		}
+	}
+	if s.MovingTime > 0 {
+		s.AveragePower = unit.Power(work/s.MovingTime.Seconds()) * unit.Watt
+	}
+	tps := t.trackpoints()
+	if n := len(tps); n > 0 {
+		if s.StartTime.IsZero() {
+			s.StartTime = tps[0].Time
+		}
+		s.ElapsedTime = unit.Duration(tps[n-1].Time.Sub(s.StartTime).Seconds()) * unit.Second
+	}
+	var prev *float64
+	for _, tp := range tps {
+		if tp.Altitude == nil {
+			continue
+		}
+		if prev != nil && *tp.Altitude > *prev {
+			s.ElevationGain += unit.Length(*tp.Altitude-*prev) * unit.Meter
+		}
+		prev = tp.Altitude
+	}
+	return s
+}
+
+// Series returns the trackpoints of the first activity as a provider-neutral time series
+func (t *TCX) Series() (*Series, error) {
+	tps := t.trackpoints()
+	n := len(tps)
+	if n == 0 {
+		return nil, errNoTrackpoints
+	}
+	start := tps[0].Time
+	x := &Series{
+		StartTime: start,
+		Time:      make([]unit.Duration, n),
+		Latitude:  make([]float64, n),
+		Longitude: make([]float64, n),
+		Elevation: make([]unit.Length, n),
+		Distance:  make([]unit.Length, n),
+		Speed:     make([]unit.Speed, n),
+		HeartRate: make([]float64, n),
+		Cadence:   make([]float64, n),
+		Power:     make([]unit.Power, n),
+	}
+	var has struct{ latlng, ele, dst, spd, hr, cad, pwr bool }
+	for i, tp := range tps {
+		x.Time[i] = unit.Duration(tp.Time.Sub(start).Seconds()) * unit.Second
+		x.Latitude[i], x.Longitude[i] = math.NaN(), math.NaN()
+		if tp.Position != nil {
+			x.Latitude[i], x.Longitude[i] = tp.Position.Latitude, tp.Position.Longitude
+			has.latlng = true
+		}
+		x.Elevation[i] = unit.Length(tcxSample(tp.Altitude, &has.ele)) * unit.Meter
+		x.Distance[i] = unit.Length(tcxSample(tp.Distance, &has.dst)) * unit.Meter
+		x.Cadence[i] = tcxSample(tp.Cadence, &has.cad)
+		x.HeartRate[i] = math.NaN()
+		if tp.HeartRate != nil {
+			x.HeartRate[i] = tcxSample(&tp.HeartRate.Value, &has.hr)
+		}
+		x.Speed[i], x.Power[i] = unit.Speed(math.NaN()), unit.Power(math.NaN())
+		if tp.Extensions != nil && tp.Extensions.TPX != nil {
+			x.Speed[i] = unit.Speed(tcxSample(tp.Extensions.TPX.Speed, &has.spd)) * unit.MetersPerSecond
+			x.Power[i] = unit.Power(tcxSample(tp.Extensions.TPX.Watts, &has.pwr)) * unit.Watt
+		}
+	}
+	if !has.latlng {
+		x.Latitude, x.Longitude = nil, nil
+	}
+	if !has.ele {
+		x.Elevation = nil
+	}
+	if !has.dst {
+		x.Distance = nil
+	}
+	if !has.spd {
+		x.Speed = nil
+	}
+	if !has.hr {
+		x.HeartRate = nil
+	}
+	if !has.cad {
+		x.Cadence = nil
+	}
+	if !has.pwr {
+		x.Power = nil
+	}
+	return x, nil
+}
+
+// Course returns the first course
+//
+// Trackpoints without a position are skipped and course points are assigned to the
+// nearest coordinate of the course.
+func (t *TCX) Course() (*Course, error) {
+	if len(t.Courses) == 0 {
+		return nil, errors.New("no courses available")
+	}
+	crs := t.Courses[0]
+	c := &Course{Name: crs.Name, Sport: SportOther, Elevation: []unit.Length{}}
+	for _, tp := range crs.Track {
+		if tp.Position == nil {
+			continue
+		}
+		c.Latitude = append(c.Latitude, tp.Position.Latitude)
+		c.Longitude = append(c.Longitude, tp.Position.Longitude)
+		if c.Elevation != nil && tp.Altitude != nil {
+			c.Elevation = append(c.Elevation, unit.Length(*tp.Altitude)*unit.Meter)
+			continue
+		}
+		// elevation is only available if present for all positions
+		c.Elevation = nil
+	}
+	if c.Len() == 0 {
+		return nil, errNoTrackpoints
+	}
+	for _, p := range crs.CoursePoints {
+		c.Points = append(c.Points, &CoursePoint{
+			Name:  p.Name,
+			Type:  toCoursePointType(p.PointType),
+			Index: c.Nearest(p.Position.Latitude, p.Position.Longitude),
+		})
+	}
+	return c, nil
+}
+
+// tcxPointTypes returns the TCX names of the course point types in enum order
+func tcxPointTypes() []string {
+	return []string{
+		"Generic", "Summit", "Valley", "Water", "Food", "Danger", "Left", "Right", "Straight", "First Aid",
+		"4th Category", "3rd Category", "2nd Category", "1st Category", "Hors Category", "Sprint",
+	}
+}
+
+func toCoursePointType(name string) CoursePointType {
+	for i, x := range tcxPointTypes() {
+		if x == name {
+			return CoursePointType(i)
+		}
+	}
+	return ToCoursePointType(name)
+}
+
+func tcxPointType(c CoursePointType) string {
+	x := tcxPointTypes()
+	if c < 0 || int(c) >= len(x) {
+		return x[CoursePointGeneric]
+	}
+	return x[c]
+}
+
+func sportToTCX(sport Sport) string {
+	switch sport {
+	case SportCycling:
+		return tcxSportBiking
+	case SportRunning:
+		return tcxSportRunning
+	case SportOther, SportWalking, SportHiking, SportSwimming:
+		return tcxSportOther
+	default:
+		return tcxSportOther
+	}
+}
+
+// ptr returns a pointer to the value or nil if the value is NaN
+func ptr[T ~float64](v T) *float64 {
+	if math.IsNaN(float64(v)) {
+		return nil
+	}
+	f := float64(v)
+	return &f
+}
+
+// round returns a pointer to the value rounded and clamped to the unsigned
+// integer type or nil if the value is NaN
+func round[U uint8 | uint16, T ~float64](v T) *U {
+	f := float64(v)
+	if math.IsNaN(f) {
+		return nil
+	}
+	u := U(math.Max(0, math.Min(float64(^U(0)), math.Round(f))))
+	return &u
+}
+
+// tcxSample returns the sample value or NaN if the value is absent
+func tcxSample[T uint8 | uint16 | float64](v *T, ok *bool) float64 {
+	if v == nil {
+		return math.NaN()
+	}
+	*ok = true
+	return float64(*v)
+}
+
+// EncodeTCX encodes the series as a TCX activity with a single lap
+//
+// The summary is optional and, if provided, is used for the sport and the lap totals,
+// otherwise the totals are derived from the series.
+func EncodeTCX(w io.Writer, summary *Summary, series *Series) error {
+	if series == nil {
+		return errors.New("missing series")
+	}
+	if err := series.Validate(); err != nil {
+		return err
+	}
+	n := series.Len()
+	lap := &TCXLap{
+		StartTime:     series.StartTime,
+		TotalTime:     series.Time[n-1].Seconds(),
+		Intensity:     "Active",
+		TriggerMethod: "Manual",
+		Track:         make([]*TCXTrackpoint, n),
+	}
+	if series.Distance != nil {
+		lap.Distance = series.Distance[n-1].Meters()
+	}
+	sport := SportOther
+	if summary != nil {
+		sport = summary.Sport
+		// the totals of the summary are used only if known
+		if summary.MovingTime > 0 {
+			lap.TotalTime = summary.MovingTime.Seconds()
+		}
+		if summary.Distance > 0 {
+			lap.Distance = summary.Distance.Meters()
+		}
+		if summary.AveragePower > 0 || summary.MaxPower > 0 {
+			lap.Extensions = &TCXExtensions{LX: &TCXLapExtension{
+				AvgWatts: round[uint16](summary.AveragePower.Watts()),
+				MaxWatts: round[uint16](summary.MaxPower.Watts()),
+			}}
+		}
+	}
+	for i := range n {
+		lap.Track[i] = tcxTrackpoint(series, i)
+	}
+	t := &TCX{
+		Activities: []*TCXActivity{
+			{
+				Sport: sportToTCX(sport),
+				ID:    series.StartTime,
+				Laps:  []*TCXLap{lap},
+			},
+		},
+	}
+	return t.Write(w)
+}
+
+func tcxTrackpoint(series *Series, i int) *TCXTrackpoint {
+	tp := &TCXTrackpoint{Time: series.Timestamp(i)}
+	if series.HasLatLng(i) {
+		tp.Position = &TCXPosition{Latitude: series.Latitude[i], Longitude: series.Longitude[i]}
+	}
+	if series.Elevation != nil {
+		tp.Altitude = ptr(series.Elevation[i].Meters())
+	}
+	if series.Distance != nil {
+		tp.Distance = ptr(series.Distance[i].Meters())
+	}
+	if series.HeartRate != nil {
+		if hr := round[uint8](series.HeartRate[i]); hr != nil {
+			tp.HeartRate = &TCXValue{Value: *hr}
+		}
+	}
+	if series.Cadence != nil {
+		tp.Cadence = round[uint8](series.Cadence[i])
+	}
+	tpx := &TCXTrackpointExtension{}
+	if series.Speed != nil {
+		tpx.Speed = ptr(series.Speed[i].MetersPerSecond())
+	}
+	if series.Power != nil {
+		tpx.Watts = round[uint16](series.Power[i].Watts())
+	}
+	if tpx.Speed != nil || tpx.Watts != nil {
+		tp.Extensions = &TCXExtensions{TPX: tpx}
+	}
+	return tp
+}
+
+// truncate the string to at most n runes
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) > n {
+		return string(r[:n])
+	}
+	return s
+}
+
+// EncodeTCXCourse encodes the course as a TCX course
+//
+// Trackpoints and course points require timestamps so they are generated from the cumulative
+// distance at a nominal speed starting from the current time. Names are truncated to the
+// lengths allowed by the schema.
+func EncodeTCXCourse(w io.Writer, course *Course) error {
+	if course == nil {
+		return errors.New("missing course")
+	}
+	if err := course.Validate(); err != nil {
+		return err
+	}
+	n := course.Len()
+	distance := course.Distance()
+	timestamps := course.timestamps(time.Now().UTC().Truncate(time.Second))
+	altitude := func(i int) *float64 {
+		if course.Elevation == nil {
+			return nil
+		}
+		return ptr(course.Elevation[i].Meters())
+	}
+	crs := &TCXCourse{
+		Name: truncate(course.Name, tcxCourseNameLength),
+		Laps: []*TCXCourseLap{
+			{
+				TotalTime:     timestamps[n-1].Sub(timestamps[0]).Seconds(),
+				Distance:      distance[n-1].Meters(),
+				BeginPosition: &TCXPosition{Latitude: course.Latitude[0], Longitude: course.Longitude[0]},
+				EndPosition:   &TCXPosition{Latitude: course.Latitude[n-1], Longitude: course.Longitude[n-1]},
+				Intensity:     "Active",
+			},
+		},
+		Track: make([]*TCXTrackpoint, n),
+	}
+	for i := range n {
+		crs.Track[i] = &TCXTrackpoint{
+			Time:     timestamps[i],
+			Position: &TCXPosition{Latitude: course.Latitude[i], Longitude: course.Longitude[i]},
+			Altitude: altitude(i),
+			Distance: ptr(distance[i].Meters()),
+		}
+	}
+	for _, p := range course.Points {
+		crs.CoursePoints = append(crs.CoursePoints, &TCXCoursePoint{
+			Name:      truncate(p.Name, tcxCoursePointNameLength),
+			Time:      timestamps[p.Index],
+			Position:  TCXPosition{Latitude: course.Latitude[p.Index], Longitude: course.Longitude[p.Index]},
+			Altitude:  altitude(p.Index),
+			PointType: tcxPointType(p.Type),
+		})
+	}
+	return (&TCX{Courses: []*TCXCourse{crs}}).Write(w)
+}
@@ -0,0 +1,203 @@
+package activity_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/martinlindhe/unit"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestDecodeTCX(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	fp, err := os.Open("testdata/activity.tcx")
+	a.NoError(err)
+	defer fp.Close()
+	tcx, err := activity.DecodeTCX(fp)
+	a.NoError(err)
+	a.Len(tcx.Activities, 1)
+	a.Len(tcx.Activities[0].Laps, 1)
+	lap := tcx.Activities[0].Laps[0]
+	a.Len(lap.Track, 3)
+	a.NotNil(lap.Extensions.LX)
+	a.Equal(uint16(220), *lap.Extensions.LX.MaxWatts)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	s := tcx.Summarize()
+	a.Equal(activity.SportCycling, s.Sport)
+	a.Equal(start, s.StartTime)
+	a.InDelta(2, s.ElapsedTime.Seconds(), 0.001)
+	a.InDelta(2, s.MovingTime.Seconds(), 0.001)
+	a.InDelta(20, s.Distance.Meters(), 0.001)
+	a.InDelta(1, s.ElevationGain.Meters(), 0.001)
+	a.InDelta(210, s.AveragePower.Watts(), 0.001)
+	a.InDelta(220, s.MaxPower.Watts(), 0.001)
+
+	series, err := tcx.Series()
+	a.NoError(err)
+	a.NoError(series.Validate())
+	a.Equal(3, series.Len())
+	a.Equal(start, series.StartTime)
+	a.True(series.HasLatLng(0))
+	a.False(series.HasLatLng(1))
+	a.Equal(47.2, series.Latitude[2])
+	a.True(math.IsNaN(series.HeartRate[1]))
+	a.Equal(float64(86), series.Cadence[1])
+	a.InDelta(10.5, series.Speed[1].MetersPerSecond(), 0.001)
+	a.InDelta(220, series.Power[2].Watts(), 0.001)
+	a.Nil(series.Temperature)
+
+	course, err := tcx.Course()
+	a.Error(err)
+	a.Nil(course)
+
+	series, err = (&activity.TCX{}).Series()
+	a.Error(err)
+	a.Nil(series)
+	a.Equal(&activity.Summary{}, (&activity.TCX{}).Summarize())
+
+	tcx, err = activity.DecodeTCX(strings.NewReader("<gpx></gpx>"))
+	a.Error(err)
+	a.Nil(tcx)
+}
+
+func TestDecodeTCXCourse(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	data, err := os.ReadFile("testdata/course.tcx")
+	a.NoError(err)
+	f := &activity.File{Reader: bytes.NewReader(data), Name: "course.tcx", Format: activity.FormatTCX}
+	tcx, err := f.TCX()
+	a.NoError(err)
+	a.Len(tcx.Courses, 1)
+
+	course, err := tcx.Course()
+	a.NoError(err)
+	a.NoError(course.Validate())
+	a.Equal("Loop", course.Name)
+	// the trackpoint without a position is skipped
+	a.Equal(3, course.Len())
+	a.Equal([]unit.Length{10, 20, 30}, course.Elevation)
+	a.Len(course.Points, 1)
+	a.Equal(activity.CoursePointFourthCategory, course.Points[0].Type)
+	a.Equal(1, course.Points[0].Index)
+}
+
+func TestEncodeTCX(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	series := &activity.Series{
+		StartTime: start,
+		Time:      []unit.Duration{0, 1, 2},
+		Latitude:  []float64{math.NaN(), 47.1, 47.2},
+		Longitude: []float64{math.NaN(), -122.1, -122.2},
+		Elevation: []unit.Length{100, 101, 102},
+		Distance:  []unit.Length{0, 10, 20},
+		Speed:     []unit.Speed{10, 10.5, 11},
+		HeartRate: []float64{120, math.NaN(), 121.6},
+		Cadence:   []float64{85.5, math.NaN(), 90.2},
+		Power:     []unit.Power{100, 199.6, 300},
+	}
+	summary := &activity.Summary{
+		Sport:        activity.SportRunning,
+		MovingTime:   2 * unit.Second,
+		Distance:     20 * unit.Meter,
+		AveragePower: 200.4 * unit.Watt,
+		MaxPower:     300 * unit.Watt,
+	}
+
+	var buf bytes.Buffer
+	a.NoError(activity.EncodeTCX(&buf, summary, series))
+	a.Contains(buf.String(), `Sport="Running"`)
+	// heart rate, cadence and power are encoded as integers
+	a.Contains(buf.String(), `<Value>122</Value>`)
+	a.Contains(buf.String(), `<Cadence>86</Cadence>`)
+	a.Contains(buf.String(), `<Watts>200</Watts>`)
+	a.Contains(buf.String(), `<AvgWatts>200</AvgWatts>`)
+	tcx, err := activity.DecodeTCX(&buf)
+	a.NoError(err)
+
+	s := tcx.Summarize()
+	a.Equal(activity.SportRunning, s.Sport)
+	a.InDelta(20, s.Distance.Meters(), 0.001)
+	a.InDelta(200, s.AveragePower.Watts(), 0.001)
+	a.InDelta(300, s.MaxPower.Watts(), 0.001)
+
+	x, err := tcx.Series()
+	a.NoError(err)
+	a.Equal(3, x.Len())
+	a.False(x.HasLatLng(0))
+	a.Equal(47.2, x.Latitude[2])
+	a.True(math.IsNaN(x.HeartRate[1]))
+	a.Equal(float64(122), x.HeartRate[2])
+	a.Equal(float64(86), x.Cadence[0])
+	a.True(math.IsNaN(x.Cadence[1]))
+	a.Equal(series.Speed, x.Speed)
+	a.Equal([]unit.Power{100, 200, 300}, x.Power)
+
+	buf.Reset()
+	a.NoError(activity.EncodeTCX(&buf, nil, series))
+	tcx, err = activity.DecodeTCX(&buf)
+	a.NoError(err)
+	a.Equal(activity.SportOther, tcx.Summarize().Sport)
+
+	// unknown totals of the summary are derived from the series
+	buf.Reset()
+	a.NoError(activity.EncodeTCX(&buf, &activity.Summary{Sport: activity.SportRunning}, series))
+	tcx, err = activity.DecodeTCX(&buf)
+	a.NoError(err)
+	s = tcx.Summarize()
+	a.InDelta(20, s.Distance.Meters(), 0.001)
+	a.InDelta(2, s.MovingTime.Seconds(), 0.001)
+
+	a.Error(activity.EncodeTCX(&buf, nil, nil))
+	a.Error(activity.EncodeTCX(&buf, nil, &activity.Series{}))
+}
+
+func TestEncodeTCXCourse(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	course := &activity.Course{
+		Name:      "Hurricane Ridge Loop",
+		Latitude:  []float64{47.1, 47.2, 47.3},
+		Longitude: []float64{-122.1, -122.2, -122.3},
+		Points: []*activity.CoursePoint{
+			{Name: "Summit of the ridge", Type: activity.CoursePointSummit, Index: 1},
+		},
+	}
+
+	var buf bytes.Buffer
+	a.NoError(activity.EncodeTCXCourse(&buf, course))
+	tcx, err := activity.DecodeTCX(&buf)
+	a.NoError(err)
+	a.Len(tcx.Courses, 1)
+	a.Equal("Hurricane Ridg", tcx.Courses[0].Name[:14])
+	a.Len(tcx.Courses[0].Name, 15)
+	a.Equal("Summit", tcx.Courses[0].CoursePoints[0].PointType)
+	a.Equal("Summit of ", tcx.Courses[0].CoursePoints[0].Name)
+
+	x, err := tcx.Course()
+	a.NoError(err)
+	a.Equal(course.Latitude, x.Latitude)
+	a.Equal(course.Longitude, x.Longitude)
+	a.Nil(x.Elevation)
+	a.Equal(activity.CoursePointSummit, x.Points[0].Type)
+	a.Equal(1, x.Points[0].Index)
+
+	a.Error(activity.EncodeTCXCourse(&buf, nil))
+	a.Error(activity.EncodeTCXCourse(&buf, &activity.Course{}))
+	course.Points[0].Index = 3
+	a.Error(activity.EncodeTCXCourse(&buf, course))
+}
@@ -0,0 +1,162 @@
+package activity
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"golang.org/x/oauth2"
+)
+
+// TokenStore persists the OAuth token of an authenticated user
+type TokenStore interface {
+	// Token returns the stored token or an error wrapping ErrNotFound if no token is stored
+	Token(ctx context.Context) (*oauth2.Token, error)
+	// SaveToken stores the token replacing any existing token
+	SaveToken(ctx context.Context, token *oauth2.Token) error
+}
+
+// RefreshHook is called with the new token each time a token is refreshed
+type RefreshHook func(ctx context.Context, token *oauth2.Token) error
+
+// RefreshHooks are the hooks called with each refreshed token
+type RefreshHooks []RefreshHook
+
+// Add appends the hook
+func (h *RefreshHooks) Add(hook RefreshHook) error {
+	if hook == nil {
+		return errors.New("nil hook")
+	}
+	*h = append(*h, hook)
+	return nil
+}
+
+// Refreshed calls the hooks in order with the token, stopping at the first error
+func (h *RefreshHooks) Refreshed(ctx context.Context, token *oauth2.Token) error {
+	for _, hook := range *h {
+		if err := hook(ctx, token); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// StoredToken returns the token in the store or token if the store has none
+func StoredToken(ctx context.Context, store TokenStore, token *oauth2.Token) (*oauth2.Token, error) {
+	if store == nil {
+		return nil, errors.New("nil store")
+	}
+	stored, err := store.Token(ctx)
+	switch {
+	case errors.Is(err, ErrNotFound):
+		return token, nil
+	case err != nil:
+		return nil, err
+	default:
+		return stored, nil
+	}
+}
+
+// FileTokenStore stores a token as JSON in a file
+type FileTokenStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileTokenStore returns a FileTokenStore for the file at path
+func NewFileTokenStore(path string) *FileTokenStore {
+	return &FileTokenStore{path: path}
+}
+
+// Token returns the token stored in the file
+func (s *FileTokenStore) Token(_ context.Context) (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, &Error{Kind: ErrNotFound, Err: err}
+		}
+		return nil, err
+	}
+	token := new(oauth2.Token)
+	if err = json.Unmarshal(data, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
+
+// SaveToken writes the token to the file
+//
+// The token is written to a temporary file which replaces the file so a failure never leaves a partial token.
+// The file is readable only by the owner.
+func (s *FileTokenStore) SaveToken(_ context.Context, token *oauth2.Token) error {
+	if token == nil {
+		return errors.New("nil token")
+	}
+	data, err := json.MarshalIndent(token, "", " ")
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	dir := filepath.Dir(s.path)
+	if err = os.MkdirAll(dir, 0o700); err != nil {
+		return err
+	}
+	fp, err := os.CreateTemp(dir, filepath.Base(s.path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(fp.Name())
+	if _, err = fp.Write(data); err != nil {
+		fp.Close()
+		return err
+	}
+	if err = fp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(fp.Name(), s.path)
+}
+
+type refreshTokenSource struct {
+	ctx    context.Context //nolint:containedctx // the context of the token source is used by the hook
+	src    oauth2.TokenSource
+	hook   RefreshHook
+	mu     sync.Mutex
+	access string
+}
+
+// RefreshTokenSource returns a TokenSource which calls the hook each time src returns a new token
+//
+// The token is the current token, if any, and is not passed to the hook. If the hook fails the
+// error is returned in place of the token.
+func RefreshTokenSource(
+	ctx context.Context, token *oauth2.Token, src oauth2.TokenSource, hook RefreshHook) oauth2.TokenSource {
+	s := &refreshTokenSource{ctx: ctx, src: src, hook: hook}
+	if token != nil {
+		s.access = token.AccessToken
+	}
+	return s
+}
+
+func (s *refreshTokenSource) Token() (*oauth2.Token, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	token, err := s.src.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token.AccessToken == s.access {
+		return token, nil
+	}
+	s.access = token.AccessToken
+	if err = s.hook(s.ctx, token); err != nil {
+		return nil, err
+	}
+	return token, nil
+}
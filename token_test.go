@@ -0,0 +1,149 @@
+package activity_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+)
+
+func TestFileTokenStore(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	path := filepath.Join(t.TempDir(), "tokens", "strava.json")
+	store := activity.NewFileTokenStore(path)
+
+	token, err := store.Token(context.TODO())
+	a.Nil(token)
+	a.ErrorIs(err, activity.ErrNotFound)
+
+	a.Error(store.SaveToken(context.TODO(), nil))
+
+	expiry := time.Date(2021, time.March, 7, 13, 22, 5, 0, time.UTC)
+	for _, access := range []string{"first", "second"} {
+		a.NoError(store.SaveToken(context.TODO(), &oauth2.Token{
+			AccessToken: access, RefreshToken: "refresh", Expiry: expiry}))
+		token, err = store.Token(context.TODO())
+		a.NoError(err)
+		a.Equal(access, token.AccessToken)
+		a.Equal("refresh", token.RefreshToken)
+		a.True(expiry.Equal(token.Expiry))
+	}
+
+	info, err := os.Stat(path)
+	a.NoError(err)
+	a.Equal(os.FileMode(0o600), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	a.NoError(err)
+	a.Len(entries, 1)
+
+	a.NoError(os.WriteFile(path, []byte("not json"), 0o600))
+	token, err = store.Token(context.TODO())
+	a.Nil(token)
+	a.Error(err)
+	a.NotErrorIs(err, activity.ErrNotFound)
+}
+
+type tokenSource struct {
+	tokens []string
+}
+
+func (s *tokenSource) Token() (*oauth2.Token, error) {
+	if len(s.tokens) == 0 {
+		return nil, errors.New("no more tokens")
+	}
+	token := &oauth2.Token{AccessToken: s.tokens[0]}
+	s.tokens = s.tokens[1:]
+	return token, nil
+}
+
+func TestRefreshTokenSource(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var refreshed []string
+	hook := func(_ context.Context, token *oauth2.Token) error {
+		refreshed = append(refreshed, token.AccessToken)
+		if token.AccessToken == "bad" {
+			return errors.New("hook failed")
+		}
+		return nil
+	}
+	src := activity.RefreshTokenSource(context.TODO(), &oauth2.Token{AccessToken: "current"},
+		&tokenSource{tokens: []string{"current", "new", "new", "bad"}}, hook)
+	for _, access := range []string{"current", "new", "new"} {
+		token, err := src.Token()
+		a.NoError(err)
+		a.Equal(access, token.AccessToken)
+	}
+	a.Equal([]string{"new"}, refreshed)
+
+	token, err := src.Token()
+	a.Nil(token)
+	a.EqualError(err, "hook failed")
+	a.Equal([]string{"new", "bad"}, refreshed)
+
+	token, err = src.Token()
+	a.Nil(token)
+	a.EqualError(err, "no more tokens")
+}
+
+func TestRefreshHooks(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var calls []string
+	var hooks activity.RefreshHooks
+	a.Error(hooks.Add(nil))
+	a.NoError(hooks.Add(func(_ context.Context, token *oauth2.Token) error {
+		calls = append(calls, "first:"+token.AccessToken)
+		if token.AccessToken == "bad" {
+			return errors.New("hook failed")
+		}
+		return nil
+	}))
+	a.NoError(hooks.Add(func(_ context.Context, token *oauth2.Token) error {
+		calls = append(calls, "second:"+token.AccessToken)
+		return nil
+	}))
+
+	a.NoError(hooks.Refreshed(context.TODO(), &oauth2.Token{AccessToken: "good"}))
+	a.EqualError(hooks.Refreshed(context.TODO(), &oauth2.Token{AccessToken: "bad"}), "hook failed")
+	a.Equal([]string{"first:good", "second:good", "first:bad"}, calls)
+}
+
+func TestStoredToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	ctx := context.TODO()
+	current := &oauth2.Token{AccessToken: "current"}
+
+	token, err := activity.StoredToken(ctx, nil, current)
+	a.Nil(token)
+	a.Error(err)
+
+	store := activity.NewFileTokenStore(filepath.Join(t.TempDir(), "token.json"))
+	token, err = activity.StoredToken(ctx, store, current)
+	a.NoError(err)
+	a.Same(current, token)
+
+	a.NoError(store.SaveToken(ctx, &oauth2.Token{AccessToken: "stored"}))
+	token, err = activity.StoredToken(ctx, store, current)
+	a.NoError(err)
+	a.Equal("stored", token.AccessToken)
+
+	path := filepath.Join(t.TempDir(), "token.json")
+	a.NoError(os.WriteFile(path, []byte("{"), 0o600))
+	token, err = activity.StoredToken(ctx, activity.NewFileTokenStore(path), current)
+	a.Nil(token)
+	a.Error(err)
+}
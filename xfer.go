@@ -1,23 +1,32 @@
 package activity
 
-//go:generate stringer -type=Format -linecomment -output=xfer_string.go
+//go:generate stringer -type=Format,UploadStatus -linecomment -output=xfer_string.go
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"math"
+	"math/rand/v2"
 	"strings"
 	"time"
 )
 
 const (
-	pollInterval   = 2 * time.Second
-	pollIterations = 5
+	pollInterval    = time.Second
+	pollMaxInterval = 8 * time.Second
+	pollMultiplier  = 2.0
+	pollJitter      = 0.1
+	pollIterations  = 10
 )
 
 var ErrExceededIterations = errors.New("exceeded iterations")
 
+var ErrExceededDeadline = errors.New("exceeded deadline")
+
 // Export the contents and metadata about an activity file
 type Export struct {
 	*File
@@ -30,6 +39,12 @@ type Exporter interface {
 	Export(ctx context.Context, activityID int64) (*Export, error)
 }
 
+// Lister lists the activities of the authenticated user
+type Lister interface {
+	// List returns summaries of the activities using the pagination specification
+	List(ctx context.Context, spec Pagination) ([]*Summary, error)
+}
+
 // UploadID is the type for all upload identifiers
 type UploadID int64
 
@@ -41,10 +56,62 @@ type Upload interface {
 	Done() bool
 }
 
+// Outcome is optionally implemented by an Upload to report the result of processing
+type Outcome interface {
+	// ActivityIdentifier returns the id of the activity created by the upload or 0 if unknown
+	ActivityIdentifier() int64
+	// Err returns the error if processing failed
+	Err() error
+}
+
+// UploadStatus is the processing status of an upload
+type UploadStatus int
+
+const (
+	// StatusQueued is an upload waiting for processing to begin
+	StatusQueued UploadStatus = iota // queued
+	// StatusProcessing is an upload being processed
+	StatusProcessing // processing
+	// StatusReady is an upload successfully processed
+	StatusReady // ready
+	// StatusError is an upload which failed processing
+	StatusError // error
+)
+
+// MarshalJSON converts an UploadStatus enum to a string representation
+func (s UploadStatus) MarshalJSON() ([]byte, error) {
+	return fmt.Appendf(nil, `"%s"`, s.String()), nil
+}
+
+// Stager is optionally implemented by an Upload to report whether processing has begun
+type Stager interface {
+	// Stage returns the status of the upload
+	Stage() UploadStatus
+}
+
+// StatusOf returns the processing status of the upload
+//
+// If the Upload implements Stager its stage is used, otherwise the status is derived
+// from Done and, if implemented, Outcome.
+func StatusOf(upload Upload) UploadStatus {
+	if x, ok := upload.(Stager); ok {
+		return x.Stage()
+	}
+	if !upload.Done() {
+		return StatusProcessing
+	}
+	if x, ok := upload.(Outcome); ok && x.Err() != nil {
+		return StatusError
+	}
+	return StatusReady
+}
+
 // Poll is the result of polling
 type Poll struct {
 	// Upload is the upload status if no error occurred
 	Upload Upload
+	// Status is the processing status of the upload if no error occurred
+	Status UploadStatus
 	// Err is non-nil when an error occurred in the operation but not semantically
 	// Check the `Upload` for semantic errors (eg missing data, duplicate activity, ...)
 	Err error
@@ -77,6 +144,23 @@ func (f *File) Close() error {
 	return nil
 }
 
+// buffer reads the contents of the file, closes the original reader, and replaces
+// it with the buffered contents so the file can be read again
+func (f *File) buffer() ([]byte, error) {
+	if f.Reader == nil {
+		return nil, errors.New("missing reader")
+	}
+	data, err := io.ReadAll(f.Reader)
+	if err != nil {
+		return nil, err
+	}
+	if err = f.Close(); err != nil {
+		return nil, err
+	}
+	f.Reader = bytes.NewReader(data)
+	return data, nil
+}
+
 // Format of the file used in exporting and uploading
 type Format int
 
@@ -96,6 +180,16 @@ func (f Format) MarshalJSON() ([]byte, error) {
 	return fmt.Appendf(nil, `"%s"`, f.String()), nil
 }
 
+// UnmarshalJSON converts a string representation to a Format enum
+func (f *Format) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	*f = ToFormat(s)
+	return nil
+}
+
 // ToFormat converts a file extension (with or without the ".") to a Format
 // If no predefined extension exists the Format Original is returned
 func ToFormat(format string) Format {
@@ -115,11 +209,42 @@ func ToFormat(format string) Format {
 // A PollerOption allows configuring the default poller
 type PollerOption func(p *poller)
 
-// WithInterval controls the duration between status polling
+// WithInterval controls the initial duration between status polling
 func WithInterval(interval time.Duration) PollerOption {
 	return func(p *poller) {
 		if interval > 0 {
 			p.interval = interval
+			p.maxInterval = max(p.maxInterval, interval)
+		}
+	}
+}
+
+// WithMaxInterval controls the maximum duration between status polling
+func WithMaxInterval(interval time.Duration) PollerOption {
+	return func(p *poller) {
+		if interval > 0 {
+			p.maxInterval = interval
+		}
+	}
+}
+
+// WithBackoff controls the factor by which the duration between status polling increases
+//
+// A multiplier of 1 polls at a fixed interval.
+func WithBackoff(multiplier float64) PollerOption {
+	return func(p *poller) {
+		if multiplier >= 1 {
+			p.multiplier = multiplier
+		}
+	}
+}
+
+// WithJitter controls the fraction, between 0 and 1, by which the duration between status
+// polling is randomly adjusted
+func WithJitter(jitter float64) PollerOption {
+	return func(p *poller) {
+		if jitter >= 0 && jitter <= 1 {
+			p.jitter = jitter
 		}
 	}
 }
@@ -133,18 +258,38 @@ func WithIterations(iterations int) PollerOption {
 	}
 }
 
+// WithDeadline polls until the duration has elapsed rather than a max number of iterations
+func WithDeadline(deadline time.Duration) PollerOption {
+	return func(p *poller) {
+		if deadline > 0 {
+			p.deadline = deadline
+		}
+	}
+}
+
 // Poller will continually check the status of an upload request
 type Poller interface {
 	// Poll the status of an upload
 	//
 	// The operation will continue until either it is completed, the context
-	//  is canceled, or the maximum number of iterations have been exceeded.
+	//  is canceled, or the maximum number of iterations or deadline have been exceeded.
+	// A Poll is sent only when the status of the upload changes or an error occurs.
 	Poll(ctx context.Context, uploadID UploadID) <-chan *Poll
 }
 
 // NewPoller returns an instance of a Poller
+//
+// The duration between polling starts at the interval and increases exponentially, with
+// jitter, up to the max interval.
 func NewPoller(uploader Uploader, opts ...PollerOption) Poller {
-	p := &poller{uploader: uploader, interval: pollInterval, iterations: pollIterations}
+	p := &poller{
+		uploader:    uploader,
+		interval:    pollInterval,
+		maxInterval: pollMaxInterval,
+		multiplier:  pollMultiplier,
+		jitter:      pollJitter,
+		iterations:  pollIterations,
+	}
 	for _, opt := range opts {
 		opt(p)
 	}
@@ -152,38 +297,73 @@ func NewPoller(uploader Uploader, opts ...PollerOption) Poller {
 }
 
 type poller struct {
-	uploader   Uploader
-	interval   time.Duration
-	iterations int
+	uploader    Uploader
+	interval    time.Duration
+	maxInterval time.Duration
+	multiplier  float64
+	jitter      float64
+	iterations  int
+	deadline    time.Duration
+}
+
+// delay returns the duration to wait after the iteration
+func (p *poller) delay(iteration int) time.Duration {
+	d := min(float64(p.interval)*math.Pow(p.multiplier, float64(iteration)), float64(p.maxInterval))
+	// jitter spreads the polling of concurrent uploads
+	d *= 1 + p.jitter*(2*rand.Float64()-1) //nolint:gosec // jitter does not require a secure source
+	return time.Duration(d)
 }
 
 func (p *poller) Poll(ctx context.Context, uploadID UploadID) <-chan *Poll {
 	res := make(chan *Poll)
+	send := func(poll *Poll) bool {
+		select {
+		case <-ctx.Done():
+			return false
+		case res <- poll:
+			return true
+		}
+	}
 	go func() {
 		defer close(res)
-		for i := p.iterations; i > 0; i-- {
+		var deadline time.Time
+		if p.deadline > 0 {
+			deadline = time.Now().Add(p.deadline)
+		}
+		status := UploadStatus(-1)
+		for i := 0; p.deadline > 0 || i < p.iterations; i++ {
 			upload, err := p.uploader.Status(ctx, uploadID)
-			poll := &Poll{Upload: upload, Err: err}
-			select {
-			case <-ctx.Done():
+			if err != nil {
+				send(&Poll{Err: err})
 				return
-			case res <- poll:
-				if poll.Err != nil || poll.Upload.Done() {
+			}
+			if current := StatusOf(upload); current != status || upload.Done() {
+				status = current
+				if !send(&Poll{Upload: upload, Status: status}) {
 					return
 				}
 			}
+			if upload.Done() {
+				return
+			}
+			wait := p.delay(i)
+			if !deadline.IsZero() {
+				remaining := time.Until(deadline)
+				if remaining <= 0 {
+					send(&Poll{Err: ErrExceededDeadline})
+					return
+				}
+				// check the status one last time at the deadline
+				wait = min(wait, remaining)
+			}
 			// wait for a bit to let the processing continue
 			select {
 			case <-ctx.Done():
 				return
-			case <-time.After(p.interval):
+			case <-time.After(wait):
 			}
 		}
-		select {
-		case <-ctx.Done():
-			return
-		case res <- &Poll{Err: ErrExceededIterations}:
-		}
+		send(&Poll{Err: ErrExceededIterations})
 	}()
 	return res
 }
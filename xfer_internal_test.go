@@ -0,0 +1,29 @@
+package activity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollerDelay(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	p := &poller{interval: time.Second, maxInterval: 8 * time.Second, multiplier: 2}
+	var delays []time.Duration
+	for i := range 6 {
+		delays = append(delays, p.delay(i))
+	}
+	a.Equal([]time.Duration{
+		time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 8 * time.Second, 8 * time.Second}, delays)
+
+	p.jitter = 0.25
+	for i := range 100 {
+		d := p.delay(i % 4)
+		base := min(time.Second<<(i%4), 8*time.Second)
+		a.GreaterOrEqual(d, base*3/4)
+		a.LessOrEqual(d, base*5/4)
+	}
+}
@@ -89,6 +89,85 @@ func TestPoller(t *testing.T) {
 	}
 }
 
+type stagedUpload struct {
+	status activity.UploadStatus
+}
+
+func (u *stagedUpload) Identifier() activity.UploadID { return activity.UploadID(2233) }
+func (u *stagedUpload) Done() bool                    { return u.status >= activity.StatusReady }
+func (u *stagedUpload) Stage() activity.UploadStatus  { return u.status }
+
+type stagedUploader struct {
+	statuses []activity.UploadStatus
+}
+
+func (u *stagedUploader) Upload(_ context.Context, _ *activity.File) (activity.Upload, error) {
+	return &stagedUpload{}, nil
+}
+
+func (u *stagedUploader) Status(_ context.Context, _ activity.UploadID) (activity.Upload, error) {
+	status := u.statuses[0]
+	if len(u.statuses) > 1 {
+		u.statuses = u.statuses[1:]
+	}
+	return &stagedUpload{status: status}, nil
+}
+
+func TestPollerStatus(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	u := &stagedUploader{statuses: []activity.UploadStatus{
+		activity.StatusQueued, activity.StatusQueued, activity.StatusProcessing,
+		activity.StatusProcessing, activity.StatusProcessing, activity.StatusReady}}
+	p := activity.NewPoller(u, activity.WithInterval(time.Millisecond), activity.WithMaxInterval(2*time.Millisecond))
+	var statuses []activity.UploadStatus
+	for x := range p.Poll(context.Background(), activity.UploadID(2233)) {
+		a.NoError(x.Err)
+		statuses = append(statuses, x.Status)
+	}
+	// only changes in status are sent
+	a.Equal([]activity.UploadStatus{activity.StatusQueued, activity.StatusProcessing, activity.StatusReady}, statuses)
+}
+
+func TestPollerDeadline(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	u := &stagedUploader{statuses: []activity.UploadStatus{activity.StatusProcessing}}
+	p := activity.NewPoller(u,
+		activity.WithInterval(time.Millisecond),
+		activity.WithMaxInterval(5*time.Millisecond),
+		activity.WithIterations(1),
+		activity.WithDeadline(50*time.Millisecond))
+	start := time.Now()
+	var polls []*activity.Poll
+	for x := range p.Poll(context.Background(), activity.UploadID(2233)) {
+		polls = append(polls, x)
+	}
+	// the deadline takes precedence over the iterations
+	a.GreaterOrEqual(time.Since(start), 50*time.Millisecond)
+	a.Len(polls, 2)
+	a.Equal(activity.StatusProcessing, polls[0].Status)
+	a.ErrorIs(polls[1].Err, activity.ErrExceededDeadline)
+}
+
+func TestStatusOf(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	a.Equal(activity.StatusProcessing, activity.StatusOf(&upload{}))
+	a.Equal(activity.StatusReady, activity.StatusOf(&upload{done: true}))
+	a.Equal(activity.StatusQueued, activity.StatusOf(&stagedUpload{status: activity.StatusQueued}))
+	a.Equal(activity.StatusReady, activity.StatusOf(&syncUpload{done: true}))
+	a.Equal(activity.StatusError, activity.StatusOf(&syncUpload{done: true, err: errors.New("failed")}))
+
+	a.Equal("processing", activity.StatusProcessing.String())
+	data, err := json.Marshal(activity.StatusError)
+	a.NoError(err)
+	a.JSONEq(`"error"`, string(data))
+}
+
 func TestFormat(t *testing.T) {
 	t.Parallel()
 	a := assert.New(t)
@@ -102,6 +181,11 @@ func TestFormat(t *testing.T) {
 	v, err := json.Marshal(activity.FormatFIT)
 	a.NoError(err)
 	a.JSONEq(`"fit"`, string(v))
+
+	var format activity.Format
+	a.NoError(json.Unmarshal(v, &format))
+	a.Equal(activity.FormatFIT, format)
+	a.Error(json.Unmarshal([]byte("3"), &format))
 }
 
 func TestFile(t *testing.T) {
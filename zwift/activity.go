@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"iter"
 	"mime"
 	"net/http"
 	"net/url"
@@ -20,42 +21,6 @@ type ActivityService service
 // pageSize default for querying bulk entities (eg trips, routes)
 const pageSize = 20
 
-type paginator struct {
-	service    ActivityService
-	athleteID  int64
-	activities []*Activity
-}
-
-func (p *paginator) PageSize() int {
-	return pageSize
-}
-
-func (p *paginator) Count() int {
-	return len(p.activities)
-}
-
-func (p *paginator) Do(ctx context.Context, spec activity.Pagination) (int, error) {
-	// pagination uses the concept of page (based on strava), zwift uses an offset by row
-	//  since pagination starts with page 1 (again, strava), subtract one from `start`
-	count := spec.Count
-	start := int64((spec.Start - 1) * p.PageSize())
-
-	uri := fmt.Sprintf("api/profiles/%d/activities/?start=%d&limit=%d", p.athleteID, start, count)
-	req, err := p.service.client.newAPIRequest(ctx, http.MethodGet, uri)
-	if err != nil {
-		return 0, err
-	}
-	var acts []*Activity
-	if err = p.service.client.do(req, &acts); err != nil {
-		return 0, err
-	}
-	if spec.Total > 0 && len(p.activities)+len(acts) > spec.Total {
-		acts = acts[:spec.Total-len(p.activities)]
-	}
-	p.activities = append(p.activities, acts...)
-	return len(acts), nil
-}
-
 // Activity returns the activity for the athlete and activity id
 func (s *ActivityService) Activity(ctx context.Context, athleteID int64, activityID int64) (*Activity, error) {
 	uri := fmt.Sprintf("api/profiles/%d/activities/%d", athleteID, activityID)
@@ -79,12 +44,25 @@ func (s *ActivityService) Activity(ctx context.Context, athleteID int64, activit
 // Activities returns a slice of activities for the user
 func (s *ActivityService) Activities(
 	ctx context.Context, athleteID int64, spec activity.Pagination) ([]*Activity, error) {
-	p := &paginator{service: *s, athleteID: athleteID, activities: make([]*Activity, 0)}
-	err := activity.Paginate(ctx, p, spec)
-	if err != nil {
-		return nil, err
-	}
-	return p.activities, nil
+	return activity.Collect(s.ActivitiesSeq(ctx, athleteID, spec))
+}
+
+// ActivitiesSeq returns an iterator over the activities for the user
+func (s *ActivityService) ActivitiesSeq(
+	ctx context.Context, athleteID int64, spec activity.Pagination) iter.Seq2[*Activity, error] {
+	return activity.Iter(ctx, pageSize, func(ctx context.Context, spec activity.Pagination) ([]*Activity, error) {
+		// zwift uses an offset by row rather than a page
+		uri := fmt.Sprintf("api/profiles/%d/activities/?start=%d&limit=%d", athleteID, spec.Offset, spec.Count)
+		req, err := s.client.newAPIRequest(ctx, http.MethodGet, uri)
+		if err != nil {
+			return nil, err
+		}
+		var acts []*Activity
+		if err = s.client.do(req, &acts); err != nil {
+			return nil, err
+		}
+		return acts, nil
+	}, spec)
 }
 
 // Export exports the data file for the activity
@@ -125,10 +103,11 @@ func (s *ActivityService) ExportActivity(ctx context.Context, act *Activity) (*a
 	}
 	defer res.Body.Close()
 	if res.StatusCode >= http.StatusBadRequest {
+		fault := &Fault{Code: res.StatusCode, Message: fmt.Sprintf("error code: %d", res.StatusCode)}
 		if res.StatusCode == http.StatusNotFound {
-			return nil, &Fault{Message: "activity not found"}
+			fault.Message = "activity not found"
 		}
-		return nil, &Fault{Message: fmt.Sprintf("error code: %d", res.StatusCode)}
+		return nil, activity.NewStatusError(res, fault)
 	}
 	out := &bytes.Buffer{}
 	_, err = io.Copy(out, res.Body)
@@ -378,3 +378,35 @@ func (s *slowTransport) RoundTrip(req *http.Request) (*http.Response, error) {
 	<-req.Context().Done()
 	return nil, req.Context().Err()
 }
+
+func TestActivitiesSeq(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	var starts []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/profiles/1037/activities/", func(w http.ResponseWriter, r *http.Request) {
+		starts = append(starts, r.URL.Query().Get("start"))
+		enc := json.NewEncoder(w)
+		var res []*zwift.Activity
+		for i := 0; i < 20; i++ {
+			res = append(res, &zwift.Activity{ID: 882920 + int64(i)})
+		}
+		a.NoError(enc.Encode(res))
+	})
+
+	client, svr := newClient(t, mux)
+	defer svr.Close()
+
+	var n int
+	for act, err := range client.Activity.ActivitiesSeq(context.Background(), 1037, activity.Pagination{}) {
+		a.NoError(err)
+		a.NotNil(act)
+		n++
+		if n == 30 {
+			break
+		}
+	}
+	a.Equal(30, n)
+	a.Equal([]string{"0", "20"}, starts)
+}
@@ -19,13 +19,26 @@ type expiresin struct {
 	Expiry int `json:"expires_in,omitempty"`
 }
 
+// Refresh returns a new token using the credentials of the user
 func (s *AuthService) Refresh(ctx context.Context, username, password string) (*oauth2.Token, error) {
-	values := url.Values{
+	return s.token(ctx, url.Values{
 		"grant_type": {"password"},
 		"username":   {username},
 		"password":   {password},
 		"client_id":  {"Zwift_Mobile_Link"},
-	}
+	})
+}
+
+// RefreshToken returns a new token using the refresh token of an existing token
+func (s *AuthService) RefreshToken(ctx context.Context, refreshToken string) (*oauth2.Token, error) {
+	return s.token(ctx, url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {refreshToken},
+		"client_id":     {"Zwift_Mobile_Link"},
+	})
+}
+
+func (s *AuthService) token(ctx context.Context, values url.Values) (*oauth2.Token, error) {
 	endpoint := s.client.config.Endpoint
 	body := strings.NewReader(values.Encode())
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.TokenURL, body)
@@ -0,0 +1,42 @@
+package zwift_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestDoErrors(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/profiles/me", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Retry-After", "60")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+	mux.HandleFunc("/api/profiles/missing", func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+	client, svr := newClient(t, mux)
+	defer svr.Close()
+
+	profile, err := client.Profile.Profile(context.TODO(), zwift.Me)
+	a.Nil(profile)
+	a.ErrorIs(err, activity.ErrRateLimited)
+	var rle *activity.RateLimitError
+	a.ErrorAs(err, &rle)
+	a.False(rle.Reset.IsZero())
+
+	profile, err = client.Profile.Profile(context.TODO(), "missing")
+	a.Nil(profile)
+	a.ErrorIs(err, activity.ErrNotFound)
+	var fault *zwift.Fault
+	a.ErrorAs(err, &fault)
+	a.Equal(http.StatusNotFound, fault.Code)
+}
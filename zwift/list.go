@@ -0,0 +1,31 @@
+package zwift
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+type lister struct {
+	s *ActivityService
+}
+
+func newLister(s *ActivityService) activity.Lister {
+	return &lister{s: s}
+}
+
+// List returns summaries of the authenticated athlete's activities
+func (l *lister) List(ctx context.Context, spec activity.Pagination) ([]*activity.Summary, error) {
+	ath, err := l.s.client.Profile.Profile(ctx, Me)
+	if err != nil {
+		return nil, err
+	}
+	var summaries []*activity.Summary
+	for act, aerr := range l.s.ActivitiesSeq(ctx, ath.ID, spec) {
+		if aerr != nil {
+			return nil, aerr
+		}
+		summaries = append(summaries, act.Summarize())
+	}
+	return summaries, nil
+}
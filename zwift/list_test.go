@@ -0,0 +1,39 @@
+package zwift_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestLister(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/profiles/me", func(w http.ResponseWriter, _ *http.Request) {
+		a.NoError(json.NewEncoder(w).Encode(&zwift.Profile{ID: 1037}))
+	})
+	mux.HandleFunc("/api/profiles/1037/activities/", func(w http.ResponseWriter, _ *http.Request) {
+		var res []*zwift.Activity
+		for i := range 3 {
+			res = append(res, &zwift.Activity{ID: 882920 + int64(i), Name: "Watopia"})
+		}
+		a.NoError(json.NewEncoder(w).Encode(res))
+	})
+
+	client, svr := newClient(t, mux)
+	defer svr.Close()
+	summaries, err := client.Lister().List(context.Background(), activity.Pagination{Total: 3})
+	a.NoError(err)
+	a.Len(summaries, 3)
+	a.Equal(int64(882921), summaries[1].ID)
+	a.Equal("zwift", summaries[1].Provider)
+	a.True(summaries[1].Trainer)
+}
@@ -0,0 +1,30 @@
+package zwift
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+// Provider returns the registration of Zwift for an activity.Registry
+func Provider() *activity.Provider {
+	return &activity.Provider{
+		Name: "zwift",
+		Capabilities: []activity.Capability{
+			activity.CapabilityExport, activity.CapabilityList,
+		},
+		New: func(ctx context.Context, config activity.ProviderConfig) (any, error) {
+			opts := []Option{
+				WithTokenCredentials(config.AccessToken, config.RefreshToken, config.Expiry),
+				WithTokenRefresh(config.Username, config.Password),
+			}
+			if config.BaseURL != "" {
+				opts = append(opts, WithBaseURL(config.BaseURL))
+			}
+			if config.TokenFile != "" {
+				opts = append(opts, WithTokenStore(ctx, activity.NewFileTokenStore(config.TokenFile)))
+			}
+			return NewClient(opts...)
+		},
+	}
+}
@@ -0,0 +1,42 @@
+package zwift_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestProvider(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		a.Equal("Bearer access", r.Header.Get("Authorization"))
+		a.NoError(json.NewEncoder(w).Encode(&zwift.Profile{FirstName: "betty"}))
+	}))
+	defer svr.Close()
+
+	provider := zwift.Provider()
+	a.Equal("zwift", provider.Name)
+	a.True(provider.Supports(activity.CapabilityExport))
+	a.False(provider.Supports(activity.CapabilityUpload))
+	client, err := provider.New(context.Background(), activity.ProviderConfig{
+		AccessToken: "access",
+		Expiry:      time.Now().Add(time.Hour),
+		Username:    "barney@example.com",
+		Password:    "password",
+		BaseURL:     svr.URL,
+	})
+	a.NoError(err)
+	profile, err := client.(*zwift.Client).Profile.Profile(context.Background(), "")
+	a.NoError(err)
+	a.Equal("betty", profile.FirstName)
+}
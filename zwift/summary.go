@@ -0,0 +1,33 @@
+package zwift
+
+import (
+	"github.com/martinlindhe/unit"
+
+	"github.com/bzimmer/activity"
+)
+
+const provider = "zwift"
+
+var _ activity.Summarizer = (*Activity)(nil)
+
+// Summarize returns a provider-neutral summary of the activity
+//
+// All Zwift activities are performed on a trainer
+func (a *Activity) Summarize() *activity.Summary {
+	s := &activity.Summary{
+		ID:            a.ID,
+		Provider:      provider,
+		Name:          a.Name,
+		Sport:         activity.ToSport(a.Sport),
+		Trainer:       true,
+		StartTime:     a.StartDate.Time,
+		MovingTime:    unit.Duration(a.MovingTimeInMillis) * unit.Millisecond,
+		Distance:      unit.Length(a.DistanceInMeters) * unit.Meter,
+		ElevationGain: unit.Length(a.TotalElevation) * unit.Meter,
+		AveragePower:  unit.Power(a.AvgWatts) * unit.Watt,
+	}
+	if !a.StartDate.IsZero() && a.EndDate.After(a.StartDate.Time) {
+		s.ElapsedTime = unit.Duration(a.EndDate.Sub(a.StartDate.Time).Seconds()) * unit.Second
+	}
+	return s
+}
@@ -0,0 +1,45 @@
+package zwift_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestSummarize(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	start := time.Date(2021, time.February, 18, 7, 28, 35, 0, time.UTC)
+	act := &zwift.Activity{
+		ID:                 882200,
+		Name:               "Watopia",
+		Sport:              "CYCLING",
+		StartDate:          zwift.Datetime{Time: start},
+		EndDate:            zwift.Datetime{Time: start.Add(time.Hour)},
+		DistanceInMeters:   32000,
+		TotalElevation:     250,
+		AvgWatts:           210,
+		MovingTimeInMillis: 3_500_000,
+	}
+	s := act.Summarize()
+	a.NotNil(s)
+	a.Equal(int64(882200), s.ID)
+	a.Equal("zwift", s.Provider)
+	a.Equal(activity.SportCycling, s.Sport)
+	a.True(s.Trainer)
+	a.Equal(start, s.StartTime)
+	a.InDelta(3600, s.ElapsedTime.Seconds(), 0.01)
+	a.InDelta(3500, s.MovingTime.Seconds(), 0.01)
+	a.InDelta(32000, s.Distance.Meters(), 0.01)
+	a.InDelta(250, s.ElevationGain.Meters(), 0.01)
+	a.InDelta(210, s.AveragePower.Watts(), 0.01)
+
+	s = (&zwift.Activity{Sport: "RUNNING"}).Summarize()
+	a.Equal(activity.SportRunning, s.Sport)
+	a.Zero(s.ElapsedTime)
+}
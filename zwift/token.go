@@ -0,0 +1,26 @@
+package zwift
+
+import (
+	"context"
+
+	"github.com/bzimmer/activity"
+)
+
+// WithRefreshHook calls the hook with each refreshed token
+func WithRefreshHook(hook activity.RefreshHook) Option {
+	return func(c *Client) error {
+		return c.hooks.Add(hook)
+	}
+}
+
+// WithTokenStore uses the token in the store, if one exists, and saves each refreshed token
+func WithTokenStore(ctx context.Context, store activity.TokenStore) Option {
+	return func(c *Client) error {
+		token, err := activity.StoredToken(ctx, store, c.token)
+		if err != nil {
+			return err
+		}
+		c.token = token
+		return c.hooks.Add(store.SaveToken)
+	}
+}
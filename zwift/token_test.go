@@ -0,0 +1,114 @@
+package zwift_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestTokenExpiry(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	tests := []struct {
+		name     string
+		token    *oauth2.Token
+		username string
+		password string
+		bearer   string
+		grants   []string
+	}{
+		{
+			name:   "valid",
+			token:  &oauth2.Token{AccessToken: "valid", RefreshToken: "refresh", Expiry: time.Now().Add(time.Hour)},
+			bearer: "valid",
+		},
+		{
+			name:   "expiring",
+			token:  &oauth2.Token{AccessToken: "expiring", RefreshToken: "refresh", Expiry: time.Now().Add(time.Second)},
+			bearer: "refresh_token",
+			grants: []string{"refresh_token"},
+		},
+		{
+			name:     "refresh rejected",
+			token:    &oauth2.Token{AccessToken: "expired", RefreshToken: "rejected", Expiry: time.Now().Add(-time.Hour)},
+			username: "foo-user",
+			password: "bar-pass",
+			bearer:   "password",
+			grants:   []string{"refresh_token", "password"},
+		},
+		{
+			name:   "refresh rejected without credentials",
+			token:  &oauth2.Token{AccessToken: "expired", RefreshToken: "rejected", Expiry: time.Now().Add(-time.Hour)},
+			bearer: "expired",
+			// the rejected refresh token is not retried on every request
+			grants: []string{"refresh_token"},
+		},
+		{
+			name:   "no refresh",
+			token:  &oauth2.Token{AccessToken: "expired", Expiry: time.Now().Add(-time.Hour)},
+			bearer: "expired",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			var grants []string
+			mux := http.NewServeMux()
+			mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+				a.NoError(r.ParseForm())
+				grant := r.Form.Get("grant_type")
+				grants = append(grants, grant)
+				if r.Form.Get("refresh_token") == "rejected" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				enc := json.NewEncoder(w)
+				a.NoError(enc.Encode(map[string]any{
+					"access_token": grant, "refresh_token": "next", "expires_in": 3600}))
+			})
+			mux.HandleFunc("/api/profiles/me", func(w http.ResponseWriter, r *http.Request) {
+				a.Equal("Bearer "+tt.bearer, r.Header.Get("Authorization"))
+				enc := json.NewEncoder(w)
+				a.NoError(enc.Encode(&zwift.Profile{FirstName: "barney"}))
+			})
+			svr := httptest.NewServer(mux)
+			defer svr.Close()
+
+			ctx := context.Background()
+			store := activity.NewFileTokenStore(filepath.Join(t.TempDir(), "zwift.json"))
+			a.NoError(store.SaveToken(ctx, tt.token))
+			client, err := zwift.NewClient(
+				zwift.WithBaseURL(svr.URL),
+				zwift.WithConfig(oauth2.Config{Endpoint: oauth2.Endpoint{TokenURL: svr.URL + "/token"}}),
+				zwift.WithTokenStore(ctx, store),
+				zwift.WithTokenRefresh(tt.username, tt.password),
+			)
+			a.NoError(err)
+
+			for range 3 {
+				profile, perr := client.Profile.Profile(ctx, "")
+				a.NoError(perr)
+				a.Equal("barney", profile.FirstName)
+			}
+
+			token, err := store.Token(ctx)
+			a.NoError(err)
+			a.Equal(tt.bearer, token.AccessToken)
+			a.Equal(tt.grants, grants)
+			if tt.bearer != tt.token.AccessToken {
+				a.Equal("next", token.RefreshToken)
+			}
+		})
+	}
+}
@@ -14,11 +14,14 @@ import (
 	"github.com/bzimmer/activity"
 )
 
-//go:generate genwith --do --client --token --ratelimit --config --endpoint-func --package zwift
+//go:generate genwith --client --token --ratelimit --config --endpoint-func --package zwift
 
 const _baseURL = "https://us-or-rly101.zwift.com"
 const userAgent = "CNL/3.4.1 (Darwin Kernel 20.3.0) zwift/1.0.61590 curl/7.64.1"
 
+// tokenExpiryDelta is how long before its expiry a token is refreshed
+const tokenExpiryDelta = time.Minute
+
 // Endpoint is Zwifts's OAuth 2.0 endpoint
 func Endpoint() oauth2.Endpoint {
 	return oauth2.Endpoint{ //nolint:gosec // not a secret
@@ -35,6 +38,9 @@ type Client struct {
 	baseURL  string
 	username string
 	password string
+	hooks    activity.RefreshHooks
+	// rejected is the last refresh token which failed to refresh
+	rejected string
 
 	lock sync.RWMutex
 
@@ -47,6 +53,10 @@ func (c *Client) Exporter() activity.Exporter {
 	return c.Activity
 }
 
+func (c *Client) Lister() activity.Lister {
+	return newLister(c.Activity)
+}
+
 func withServices() Option {
 	return func(c *Client) error {
 		c.Auth = &AuthService{c}
@@ -69,7 +79,9 @@ func WithBaseURL(baseURL string) Option {
 	}
 }
 
-// WithTokenRefresh refreshes the access token if none is provided
+// WithTokenRefresh acquires a token using the username and password if none is provided
+//
+// The credentials are also used if the token expires and cannot be refreshed with its refresh token.
 func WithTokenRefresh(username, password string) Option {
 	return func(c *Client) error {
 		c.username = username
@@ -78,32 +90,73 @@ func WithTokenRefresh(username, password string) Option {
 	}
 }
 
-func (c *Client) validateToken(ctx context.Context) error {
+// valid returns true if the token has an access token which does not expire within tokenExpiryDelta
+func valid(token *oauth2.Token) bool {
+	if token == nil || token.AccessToken == "" {
+		return false
+	}
+	return token.Expiry.IsZero() || time.Now().Add(tokenExpiryDelta).Before(token.Expiry)
+}
+
+// validateToken returns a valid access token refreshing the token if it is missing or about to expire
+func (c *Client) validateToken(ctx context.Context) (string, error) {
 	c.lock.RLock()
-	// if no access token try to acquire one
-	if c.token != nil && c.token.AccessToken != "" {
-		c.lock.RUnlock()
-		return nil
+	if valid(c.token) {
+		defer c.lock.RUnlock()
+		return c.token.AccessToken, nil
 	}
 	c.lock.RUnlock()
-	// bail if username and password are not available
-	if c.username == "" || c.password == "" {
-		return errors.New("accessToken required")
-	}
 	c.lock.Lock()
 	defer c.lock.Unlock()
+	// the token might have been refreshed while waiting for the lock
+	if valid(c.token) {
+		return c.token.AccessToken, nil
+	}
+	token, err := c.refresh(ctx)
+	if err != nil {
+		return "", err
+	}
+	if token != c.token {
+		token.TokenType = "bearer"
+		c.token = token
+		if err = c.hooks.Refreshed(ctx, token); err != nil {
+			return "", err
+		}
+	}
+	return c.token.AccessToken, nil
+}
+
+// refresh returns a new token using the refresh token, falling back to the username and password
+//
+// If the token cannot be refreshed the current access token is used until it is rejected
+// and the failed refresh token is not tried again.
+func (c *Client) refresh(ctx context.Context) (*oauth2.Token, error) {
 	ctx, cancel := context.WithTimeout(ctx, time.Second)
 	defer cancel()
-	token, err := c.Auth.Refresh(ctx, c.username, c.password)
-	if err != nil {
-		return err
+	var err error
+	if c.token != nil && c.token.RefreshToken != "" && c.token.RefreshToken != c.rejected {
+		var token *oauth2.Token
+		token, err = c.Auth.RefreshToken(ctx, c.token.RefreshToken)
+		if err == nil {
+			return token, nil
+		}
+		c.rejected = c.token.RefreshToken
+	}
+	switch {
+	case c.username != "" && c.password != "":
+		return c.Auth.Refresh(ctx, c.username, c.password)
+	case c.token != nil && c.token.AccessToken != "":
+		return c.token, nil
+	case err != nil:
+		return nil, fmt.Errorf("failed to refresh token: %w", err)
+	default:
+		return nil, errors.New("accessToken required")
 	}
-	c.token = token
-	return nil
 }
 
 func (c *Client) newAPIRequest(ctx context.Context, method, uri string) (*http.Request, error) {
-	if err := c.validateToken(ctx); err != nil {
+	accessToken, err := c.validateToken(ctx)
+	if err != nil {
 		return nil, err
 	}
 	u, err := url.Parse(fmt.Sprintf("%s/%s", c.baseURL, uri))
@@ -116,6 +169,6 @@ func (c *Client) newAPIRequest(ctx context.Context, method, uri string) (*http.R
 	}
 	req.Header.Set("User-Agent", userAgent)
 	req.Header.Set("Accept", "application/json")
-	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", c.token.AccessToken))
+	req.Header.Set("Authorization", fmt.Sprintf("Bearer %v", accessToken))
 	return req, nil
 }
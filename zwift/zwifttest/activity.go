@@ -0,0 +1,127 @@
+package zwifttest
+
+import (
+	"cmp"
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/zwift"
+)
+
+// bucket is the S3 bucket of the FIT files
+const bucket = "zwifttest-activities"
+
+// fitFile is the FIT file of an activity
+type fitFile struct {
+	name string
+	data []byte
+}
+
+// AddActivity adds an activity of the authenticated athlete and its FIT file returning its id
+//
+// An id is assigned if the activity does not have one. If the data is not nil the FIT file
+// can be downloaded from the location in the activity's FitFileBucket and FitFileKey.
+func (s *Server) AddActivity(act *zwift.Activity, data []byte) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	x := *act
+	if x.ID == 0 {
+		x.ID = s.ids.Next()
+	}
+	x.IDString = strconv.FormatInt(x.ID, 10)
+	x.ProfileID = s.profile.ID
+	if data != nil {
+		x.FitFileBucket = bucket
+		x.FitFileKey = fmt.Sprintf("prod/%d/%d", x.ProfileID, x.ID)
+		s.files[x.FitFileKey] = &fitFile{name: x.StartDate.UTC().Format("2006-01-02-15-04-05") + ".fit", data: data}
+	}
+	s.activities[x.ID] = &x
+	return x.ID
+}
+
+// Activity returns a copy of the activity or nil if it does not exist
+func (s *Server) Activity(activityID int64) *zwift.Activity {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	act, ok := s.activities[activityID]
+	if !ok {
+		return nil
+	}
+	x := *act
+	return &x
+}
+
+// listActivities returns the athlete's activities newest first using the start offset and limit
+func (s *Server) listActivities(w http.ResponseWriter, r *http.Request) {
+	if !s.self(r) {
+		fault(w, http.StatusNotFound, "profile not found")
+		return
+	}
+	q := r.URL.Query()
+	start, _ := strconv.Atoi(q.Get("start"))
+	limit, err := strconv.Atoi(q.Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = 20
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	acts := make([]*zwift.Activity, 0, len(s.activities))
+	for _, act := range s.activities {
+		acts = append(acts, act)
+	}
+	slices.SortFunc(acts, func(a, b *zwift.Activity) int {
+		return cmp.Or(b.StartDate.Compare(a.StartDate.Time), cmp.Compare(b.ID, a.ID))
+	})
+	fakeserver.Encode(w, http.StatusOK, fakeserver.Page(acts, start, limit))
+}
+
+func (s *Server) getActivity(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("activity"), 10, 64)
+	act := s.Activity(id)
+	if !s.self(r) || err != nil || act == nil {
+		// zwift does not use json errors
+		http.NotFound(w, r)
+		return
+	}
+	fakeserver.Encode(w, http.StatusOK, act)
+}
+
+// getFile returns the FIT file of an activity as stored in S3
+func (s *Server) getFile(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	file, ok := s.files[r.PathValue("key")]
+	s.mu.Unlock()
+	if r.PathValue("bucket") != bucket || !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Disposition", "filename="+file.name)
+	_, _ = w.Write(file.data)
+}
+
+// Transport returns a RoundTripper sending S3 requests to the server and all others to the default transport
+func (s *Server) Transport() http.RoundTripper {
+	u, _ := url.Parse(s.URL)
+	return &transport{base: u, transport: http.DefaultTransport}
+}
+
+type transport struct {
+	base      *url.URL
+	transport http.RoundTripper
+}
+
+func (t *transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if name, ok := strings.CutSuffix(req.URL.Host, ".s3.amazonaws.com"); ok {
+		req = req.Clone(req.Context())
+		req.URL.Scheme, req.URL.Host = t.base.Scheme, t.base.Host
+		req.URL.Path = "/s3/" + name + req.URL.Path
+		req.Host = ""
+	}
+	return t.transport.RoundTrip(req)
+}
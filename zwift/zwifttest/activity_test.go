@@ -0,0 +1,72 @@
+package zwifttest_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+)
+
+func TestActivities(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	ctx := context.Background()
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	for i := range 25 {
+		svr.AddActivity(&zwift.Activity{Name: "ride", StartDate: zwift.Datetime{Time: start.AddDate(0, 0, i)}}, nil)
+	}
+
+	acts, err := client.Activity.Activities(ctx, 1, activity.Pagination{})
+	a.NoError(err)
+	a.Len(acts, 25)
+	// newest first
+	a.Equal(start.AddDate(0, 0, 24), acts[0].StartDate.Time)
+	a.Equal(start, acts[24].StartDate.Time)
+
+	summaries, err := client.Lister().List(ctx, activity.Pagination{Total: 3})
+	a.NoError(err)
+	a.Len(summaries, 3)
+
+	act, err := client.Activity.Activity(ctx, 1, acts[0].ID)
+	a.NoError(err)
+	a.Equal(acts[0].ID, act.ID)
+
+	act, err = client.Activity.Activity(ctx, 1, acts[0].ID+100)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(act)
+}
+
+func TestExport(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, client := newServer(t)
+	ctx := context.Background()
+	start := time.Date(2021, time.March, 7, 8, 0, 0, 0, time.UTC)
+	id := svr.AddActivity(&zwift.Activity{Name: "ride", StartDate: zwift.Datetime{Time: start}}, []byte("fit data"))
+
+	exp, err := client.Exporter().Export(ctx, id)
+	a.NoError(err)
+	a.Equal(id, exp.ID)
+	a.Equal("2021-03-07-08-00-00.fit", exp.Name)
+	a.Equal(activity.FormatFIT, exp.Format)
+	data, err := io.ReadAll(exp)
+	a.NoError(err)
+	a.Equal("fit data", string(data))
+
+	// no file for the activity
+	id = svr.AddActivity(&zwift.Activity{Name: "ride"}, nil)
+	act := svr.Activity(id)
+	act.FitFileBucket, act.FitFileKey = "zwifttest-activities", "missing"
+	exp, err = client.Activity.ExportActivity(ctx, act)
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(exp)
+}
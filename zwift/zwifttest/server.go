@@ -0,0 +1,192 @@
+// Package zwifttest provides a stateful fake of the Zwift API for testing
+//
+// The Server implements the endpoints used by the zwift package, including the token endpoint and
+// the download of FIT files from S3, so clients created with zwift.WithBaseURL(server.URL) and the
+// server's Transport can be tested without access to Zwift.
+package zwifttest
+
+import (
+	"crypto/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"golang.org/x/oauth2"
+
+	"github.com/bzimmer/activity/internal/fakeserver"
+	"github.com/bzimmer/activity/zwift"
+)
+
+const (
+	// AccessToken is the default access token accepted by the server
+	AccessToken = "zwifttest-access-token"
+	// Username is the default username of the athlete
+	Username = "rider@zwifttest.example"
+	// Password is the default password of the athlete
+	Password = "zwifttest-password" //nolint:gosec // not a secret
+)
+
+// Option configures a Server
+type Option func(*Server)
+
+// WithAccessToken sets an access token accepted by the API endpoints
+func WithAccessToken(token string) Option {
+	return func(s *Server) {
+		s.token = token
+	}
+}
+
+// WithCredentials sets the username and password accepted by the token endpoint
+func WithCredentials(username, password string) Option {
+	return func(s *Server) {
+		s.username = username
+		s.password = password
+	}
+}
+
+// WithProfile sets the profile of the authenticated athlete
+func WithProfile(profile *zwift.Profile) Option {
+	return func(s *Server) {
+		s.profile = profile
+	}
+}
+
+// Server is a fake of the Zwift API safe for concurrent use
+type Server struct {
+	*fakeserver.Server
+
+	token    string
+	username string
+	password string
+
+	mu         sync.Mutex
+	ids        fakeserver.IDs
+	profile    *zwift.Profile
+	tokens     map[string]bool
+	refresh    map[string]bool
+	activities map[int64]*zwift.Activity
+	files      map[string]*fitFile
+}
+
+// NewServer starts and returns a new Server, the caller should call Close when finished
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		token:      AccessToken,
+		username:   Username,
+		password:   Password,
+		profile:    &zwift.Profile{ID: 1, FirstName: "Zwift", LastName: "Test"},
+		tokens:     make(map[string]bool),
+		refresh:    make(map[string]bool),
+		activities: make(map[int64]*zwift.Activity),
+		files:      make(map[string]*fitFile),
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.tokens[s.token] = true
+	s.Server = fakeserver.Start(s.handler())
+	return s
+}
+
+// Endpoint returns the OAuth 2.0 endpoint of the server
+func (s *Server) Endpoint() oauth2.Endpoint {
+	endpoint := zwift.Endpoint()
+	endpoint.TokenURL = s.URL + "/token"
+	return endpoint
+}
+
+// NewClient returns a zwift.Client for the server authenticated with the server's access token
+func (s *Server) NewClient(opts ...zwift.Option) (*zwift.Client, error) {
+	opts = append([]zwift.Option{
+		zwift.WithBaseURL(s.URL),
+		zwift.WithTransport(s.Transport()),
+		zwift.WithConfig(oauth2.Config{Endpoint: s.Endpoint()}),
+		zwift.WithTokenCredentials(s.token, "", time.Time{}),
+	}, opts...)
+	return zwift.NewClient(opts...)
+}
+
+func (s *Server) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", s.createToken)
+	mux.HandleFunc("GET /api/profiles/{id}", s.authorize(s.getProfile))
+	mux.HandleFunc("GET /api/profiles/{id}/activities/{$}", s.authorize(s.listActivities))
+	mux.HandleFunc("GET /api/profiles/{id}/activities/{activity}", s.authorize(s.getActivity))
+	mux.HandleFunc("GET /s3/{bucket}/{key...}", s.getFile)
+	return mux
+}
+
+// authorize requires a bearer token issued by the server
+func (s *Server) authorize(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		const prefix = "Bearer "
+		auth := r.Header.Get("Authorization")
+		s.mu.Lock()
+		ok := len(auth) > len(prefix) && s.tokens[auth[len(prefix):]]
+		s.mu.Unlock()
+		if !ok {
+			fault(w, http.StatusUnauthorized, "invalid token")
+			return
+		}
+		h(w, r)
+	}
+}
+
+// createToken issues a token for the password or refresh token grants
+func (s *Server) createToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		fault(w, http.StatusBadRequest, "invalid request")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch r.PostForm.Get("grant_type") {
+	case "password":
+		if r.PostForm.Get("username") != s.username || r.PostForm.Get("password") != s.password {
+			fault(w, http.StatusUnauthorized, "invalid user credentials")
+			return
+		}
+	case "refresh_token":
+		token := r.PostForm.Get("refresh_token")
+		if !s.refresh[token] {
+			fault(w, http.StatusBadRequest, "invalid refresh token")
+			return
+		}
+		delete(s.refresh, token)
+	default:
+		fault(w, http.StatusBadRequest, "unsupported grant type")
+		return
+	}
+	access, refresh := rand.Text(), rand.Text()
+	s.tokens[access], s.refresh[refresh] = true, true
+	fakeserver.Encode(w, http.StatusOK, map[string]any{
+		"access_token":  access,
+		"refresh_token": refresh,
+		"token_type":    "bearer",
+		"expires_in":    3600,
+	})
+}
+
+// getProfile returns the profile of the authenticated athlete for "me" or its id
+func (s *Server) getProfile(w http.ResponseWriter, r *http.Request) {
+	if !s.self(r) {
+		fault(w, http.StatusNotFound, "profile not found")
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fakeserver.Encode(w, http.StatusOK, s.profile)
+}
+
+// self returns true if the profile id of the path is the authenticated athlete
+func (s *Server) self(r *http.Request) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	id := r.PathValue("id")
+	return id == zwift.Me || id == strconv.FormatInt(s.profile.ID, 10)
+}
+
+func fault(w http.ResponseWriter, status int, message string) {
+	fakeserver.Encode(w, status, &zwift.Fault{Code: status, Message: message})
+}
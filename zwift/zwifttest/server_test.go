@@ -0,0 +1,76 @@
+package zwifttest_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/bzimmer/activity"
+	"github.com/bzimmer/activity/zwift"
+	"github.com/bzimmer/activity/zwift/zwifttest"
+)
+
+func newServer(t *testing.T, opts ...zwifttest.Option) (*zwifttest.Server, *zwift.Client) {
+	t.Helper()
+	svr := zwifttest.NewServer(opts...)
+	t.Cleanup(svr.Close)
+	client, err := svr.NewClient()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return svr, client
+}
+
+func TestProfile(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	_, client := newServer(t, zwifttest.WithProfile(&zwift.Profile{ID: 1887, FirstName: "Barney"}))
+	for _, id := range []string{zwift.Me, "1887"} {
+		profile, err := client.Profile.Profile(context.Background(), id)
+		a.NoError(err)
+		a.Equal(int64(1887), profile.ID)
+		a.Equal("Barney", profile.FirstName)
+	}
+	profile, err := client.Profile.Profile(context.Background(), "1888")
+	a.True(errors.Is(err, activity.ErrNotFound))
+	a.Nil(profile)
+}
+
+func TestToken(t *testing.T) {
+	t.Parallel()
+	a := assert.New(t)
+
+	svr, _ := newServer(t, zwifttest.WithCredentials("barney", "rubble"))
+	ctx := context.Background()
+
+	// an expired token is refreshed using the credentials
+	client, err := svr.NewClient(
+		zwift.WithTokenCredentials("expired", "", time.Now().Add(-time.Hour)),
+		zwift.WithTokenRefresh("barney", "rubble"))
+	a.NoError(err)
+	profile, err := client.Profile.Profile(ctx, zwift.Me)
+	a.NoError(err)
+	a.NotNil(profile)
+
+	token, err := client.Auth.Refresh(ctx, "barney", "rubble")
+	a.NoError(err)
+	a.NotEmpty(token.RefreshToken)
+	refreshed, err := client.Auth.RefreshToken(ctx, token.RefreshToken)
+	a.NoError(err)
+	a.NotEqual(token.AccessToken, refreshed.AccessToken)
+	// refresh tokens are used once
+	_, err = client.Auth.RefreshToken(ctx, token.RefreshToken)
+	a.Error(err)
+	_, err = client.Auth.Refresh(ctx, "barney", "betty")
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+
+	client, err = svr.NewClient(zwift.WithTokenCredentials("other", "", time.Time{}))
+	a.NoError(err)
+	profile, err = client.Profile.Profile(ctx, zwift.Me)
+	a.True(errors.Is(err, activity.ErrUnauthorized))
+	a.Nil(profile)
+}